@@ -0,0 +1,445 @@
+package main
+
+import (
+	"backend/src/services"
+	"backend/src/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+
+	// cliUser is recorded in the audit log for command line changes
+	cliUser = "cli"
+)
+
+// runCommand dispatches subcommands, e.g. `main -env production export -job foo`.
+// Subcommands work on the configured folders directly and never start the HTTP server.
+func runCommand(args []string) int {
+	switch args[0] {
+	case "jobs":
+		return runJobsCommand(args[1:])
+	case "scan":
+		return runScanCommand(args[1:])
+	case "import":
+		return runImportCommand(args[1:])
+	case "delete":
+		return runDeleteCommand(args[1:])
+	case "backup":
+		return runBackupCommand(args[1:])
+	case "export":
+		return runExportCommand(args[1:])
+	case "help", "-h", "--help":
+		printCommandUsage(os.Stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", args[0])
+		printCommandUsage(os.Stderr)
+		return exitUsage
+	}
+}
+
+func printCommandUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: main [-env development|production] [-workspace name] [command] [options]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Without a command the HTTP server is started. Commands work on the default")
+	fmt.Fprintln(w, "workspace unless -workspace names another one.")
+	fmt.Fprintln(w, "import, delete and backup create/restore refuse to run while a server uses")
+	fmt.Fprintln(w, "the workspace's backup folder.")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "  jobs      List jobs under the image root")
+	fmt.Fprintln(w, "  scan      Summarize the datasets, images, labels and pending items of a job")
+	fmt.Fprintln(w, "  import    Apply a decision list (CSV/JSONL) to the pending review set")
+	fmt.Fprintln(w, "  delete    Delete the images pending review")
+	fmt.Fprintln(w, "  backup    Create, list or restore pending review backups")
+	fmt.Fprintln(w, "  export    Export review decisions (CSV/JSONL), keep/drop manifests or a COCO/YOLO training set")
+}
+
+// newOfflineJointServices opens the workspace selected with -workspace, exiting
+// when no such workspace is configured
+func newOfflineJointServices() *services.JointServices {
+	workspace, ok := cfg.GetWorkspace(cliWorkspace)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown workspace: %s\n", cliWorkspace)
+		os.Exit(exitUsage)
+	}
+
+	store, err := openStorage(workspace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open workspace storage: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	js := services.NewOfflineJointServices(context.Background(), store, workspace.BackupFolder)
+	js.SetMaxBackupCount(cfg.GetBackupConfig().MaxCount)
+	return js
+}
+
+// lockWorkspace takes the backup folder lock of the workspace selected with
+// -workspace before a command changes review state. It exits while a server
+// or another command holds the lock: the server keeps that state in memory and
+// would overwrite the change, and both would extend the audit log chain from
+// the same entry. The lock is held until the command exits.
+func lockWorkspace() {
+	workspace, ok := cfg.GetWorkspace(cliWorkspace)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown workspace: %s\n", cliWorkspace)
+		os.Exit(exitUsage)
+	}
+
+	lock, err := utils.LockBackupDir(workspace.BackupFolder)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		if errors.Is(err, utils.ErrBackupDirLocked) {
+			fmt.Fprintln(os.Stderr, "stop the server or use its API to change review state while it runs")
+		}
+		os.Exit(exitError)
+	}
+	backupLocks = append(backupLocks, lock)
+}
+
+func printJSON(v interface{}) int {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode output: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+func runJobsCommand(args []string) int {
+	fs := flag.NewFlagSet("jobs", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Print the job list as JSON")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	jobs := newOfflineJointServices().GetJobList()
+	if *asJSON {
+		return printJSON(jobs)
+	}
+	for _, job := range jobs {
+		fmt.Fprintln(os.Stdout, job)
+	}
+	return exitOK
+}
+
+func runScanCommand(args []string) int {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	jobName := fs.String("job", "", "Job name")
+	asJSON := fs.Bool("json", false, "Print the summary as JSON")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *jobName == "" {
+		fmt.Fprintln(os.Stderr, "scan requires -job")
+		return exitUsage
+	}
+
+	summary, err := newOfflineJointServices().GetJobSummary(context.Background(), *jobName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to scan job: %v\n", err)
+		return exitError
+	}
+	if *asJSON {
+		return printJSON(summary)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATASET\tIMAGES\tLABELS\tPENDING")
+	for _, dataset := range summary.Datasets {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", dataset.DatasetName, dataset.ImageCount, dataset.LabelCount, dataset.PendingCount)
+	}
+	fmt.Fprintf(w, "TOTAL (%d datasets)\t%d\t%d\t%d\n", summary.DatasetCount, summary.ImageCount, summary.LabelCount, summary.PendingCount)
+	w.Flush()
+	return exitOK
+}
+
+func runImportCommand(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	input := fs.String("file", "", "Decision list written by export (stdin when empty)")
+	format := fs.String("format", "", "csv or jsonl (guessed from the file extension when empty)")
+	user := fs.String("user", cliUser, "Reviewer recorded for drop rows without one and in the audit log")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if *format == "" {
+		*format = services.ExportFormatCSV
+		if strings.HasSuffix(*input, "."+services.ExportFormatJSONL) {
+			*format = services.ExportFormatJSONL
+		}
+	}
+	if !services.IsValidExportFormat(*format) {
+		fmt.Fprintf(os.Stderr, "invalid format %q, expected csv or jsonl\n", *format)
+		return exitUsage
+	}
+
+	r := io.Reader(os.Stdin)
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to open %s: %v\n", *input, err)
+			return exitError
+		}
+		defer file.Close()
+		r = file
+	}
+
+	records, err := services.ReadDecisionRecords(r, *format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read decisions: %v\n", err)
+		return exitError
+	}
+
+	lockWorkspace()
+	result := newOfflineJointServices().ImportDecisions(records, *user)
+	for _, message := range result.Errors {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", message)
+	}
+	fmt.Fprintf(os.Stdout, "Imported %d decisions: %d dropped, %d kept, %d skipped\n",
+		len(records), result.Dropped, result.Kept, result.Skipped)
+	return exitOK
+}
+
+func runDeleteCommand(args []string) int {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	jobName := fs.String("job", "", "Only delete pending images of this job (all jobs when empty)")
+	user := fs.String("user", cliUser, "User recorded in the audit log")
+	dryRun := fs.Bool("dry-run", false, "List the images that would be deleted without deleting them")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if !*dryRun {
+		lockWorkspace()
+	}
+	js := newOfflineJointServices()
+	if *dryRun {
+		items := js.PendingDeletionItems(*jobName)
+		for _, item := range items {
+			fmt.Fprintln(os.Stdout, item.ImagePath)
+		}
+		fmt.Fprintf(os.Stderr, "%d images would be deleted\n", len(items))
+		return exitOK
+	}
+
+	result, err := js.ApplyPendingDeletions(context.Background(), *jobName, *user)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to delete images: %v\n", err)
+		return exitError
+	}
+
+	if result.PendingApproval {
+		fmt.Fprintf(os.Stdout, "Deletion approval is required, created batch %s\n", result.BatchID)
+		return exitOK
+	}
+	for _, path := range result.DeletedPaths {
+		fmt.Fprintln(os.Stdout, path)
+	}
+	fmt.Fprintf(os.Stderr, "Deleted %d images\n", result.DeletedCount)
+	return exitOK
+}
+
+func runBackupCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: backup create | list [-json] | restore -file <name>")
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "create":
+		lockWorkspace()
+		name, err := newOfflineJointServices().CreateBackup()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create backup: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintln(os.Stdout, name)
+		return exitOK
+	case "list":
+		return listBackups(args[1:])
+	case "restore":
+		return restoreBackup(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown backup command: %s\n", args[0])
+		return exitUsage
+	}
+}
+
+func listBackups(args []string) int {
+	fs := flag.NewFlagSet("backup list", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Print the backup list as JSON")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	backups, err := newOfflineJointServices().GetBackupList()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list backups: %v\n", err)
+		return exitError
+	}
+	if *asJSON {
+		return printJSON(backups)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILENAME\tTIME\tITEMS")
+	for _, backup := range backups {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", backup.Filename, backup.Timestamp.Format(time.RFC3339), backup.ItemCount)
+	}
+	w.Flush()
+	return exitOK
+}
+
+func restoreBackup(args []string) int {
+	fs := flag.NewFlagSet("backup restore", flag.ContinueOnError)
+	filename := fs.String("file", "", "Backup file name as printed by backup list")
+	user := fs.String("user", cliUser, "User recorded in the audit log")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *filename == "" {
+		fmt.Fprintln(os.Stderr, "backup restore requires -file")
+		return exitUsage
+	}
+
+	lockWorkspace()
+	js := newOfflineJointServices()
+	if err := js.RestoreFromBackup(*filename, *user); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to restore backup: %v\n", err)
+		return exitError
+	}
+
+	// Restoring only changes memory; a fresh backup makes it the state the server loads next
+	name, err := js.CreateBackup()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to persist restored backup: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(os.Stdout, "Restored %d items from %s (saved as %s)\n", js.PendingReviewData.Len(), *filename, name)
+	return exitOK
+}
+
+func runExportCommand(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	jobName := fs.String("job", "", "Job name (all jobs when empty; required with -manifests)")
+	format := fs.String("format", services.ExportFormatCSV, "Decision format: csv or jsonl")
+	includeKeep := fs.Bool("include-keep", false, "Also emit a keep row for every undecided image")
+	manifests := fs.Bool("manifests", false, "Write keep/drop manifests per dataset instead of decisions")
+	trainingSet := fs.Bool("training-set", false, "Write the surviving images with COCO/YOLO annotations instead of decisions")
+	annotations := fs.String("annotations", "coco,yolo", "Comma separated annotation formats for -training-set")
+	archive := fs.Bool("archive", false, "Write the training set as a tar.gz archive at -out")
+	output := fs.String("out", "", "Output file for decisions (stdout when empty), directory for manifests, or training set path")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	js := newOfflineJointServices()
+	if *trainingSet {
+		return exportTrainingSet(js, *jobName, strings.Split(*annotations, ","), *archive, *output)
+	}
+	if *manifests {
+		return exportManifests(js, *jobName, *output)
+	}
+	return exportDecisions(js, *jobName, *format, *includeKeep, *output)
+}
+
+func exportDecisions(js *services.JointServices, jobName, format string, includeKeep bool, output string) int {
+	if !services.IsValidExportFormat(format) {
+		fmt.Fprintf(os.Stderr, "invalid format %q, expected csv or jsonl\n", format)
+		return exitUsage
+	}
+
+	records, err := js.BuildDecisionRecords(context.Background(), jobName, includeKeep)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to collect decisions: %v\n", err)
+		return exitError
+	}
+
+	w := io.Writer(os.Stdout)
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create %s: %v\n", output, err)
+			return exitError
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := services.WriteDecisionRecords(w, format, records); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write decisions: %v\n", err)
+		return exitError
+	}
+
+	if output != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d decisions to %s\n", len(records), output)
+	}
+	return exitOK
+}
+
+func exportManifests(js *services.JointServices, jobName, output string) int {
+	if jobName == "" || output == "" {
+		fmt.Fprintln(os.Stderr, "-manifests requires -job and -out")
+		return exitUsage
+	}
+
+	manifests, err := js.BuildManifests(context.Background(), jobName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build manifests: %v\n", err)
+		return exitError
+	}
+
+	written, err := services.WriteManifestFiles(output, manifests)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write manifests: %v\n", err)
+		return exitError
+	}
+
+	for _, path := range written {
+		fmt.Fprintln(os.Stdout, path)
+	}
+	return exitOK
+}
+
+func exportTrainingSet(js *services.JointServices, jobName string, formats []string, archive bool, output string) int {
+	if jobName == "" || output == "" {
+		fmt.Fprintln(os.Stderr, "-training-set requires -job and -out")
+		return exitUsage
+	}
+
+	// Ctrl-C stops copying instead of leaving the process to be killed mid-file
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	summary, err := js.ExportTrainingSet(ctx, jobName, formats, archive, output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to export training set: %v\n", err)
+		if errors.Is(err, services.ErrInvalidAnnotationFormat) {
+			return exitUsage
+		}
+		return exitError
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d images (%d excluded, %d annotations, %d classes) to %s\n",
+		summary.ImageCount, summary.ExcludedCount, summary.AnnotationCount, len(summary.Classes), summary.OutputPath)
+	for _, label := range summary.InvalidLabels {
+		fmt.Fprintf(os.Stderr, "warning: skipped invalid label %s\n", label)
+	}
+	return exitOK
+}
@@ -3,14 +3,30 @@ package config
 import (
 	"fmt"
 	"net"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
+const (
+	DefaultWorkspaceName = "default"
+
+	StorageTypeLocal = "local"
+	StorageTypeS3    = "s3"
+)
+
+var workspaceNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
 func (c *Config) GetServerAddress() string {
 	return fmt.Sprintf(":%s", c.Server.Port)
 }
 
+func (c *Config) GetShutdownTimeout() time.Duration {
+	return c.Server.ShutdownTimeout
+}
+
 func (c *Config) GetStaticFolder() string {
 	return c.Static.RootFolder
 }
@@ -19,6 +35,43 @@ func (c *Config) GetBackupFolder() string {
 	return c.Static.BackupFolder
 }
 
+// GetWorkspaces returns the configured image roots. The first one is the
+// default for requests and commands that do not name a workspace.
+func (c *Config) GetWorkspaces() []WorkspaceConfig {
+	if len(c.Static.Workspaces) == 0 {
+		return []WorkspaceConfig{{
+			Name:         DefaultWorkspaceName,
+			RootFolder:   c.Static.RootFolder,
+			BackupFolder: c.Static.BackupFolder,
+			Storage:      c.Static.Storage,
+		}}
+	}
+	return c.Static.Workspaces
+}
+
+// GetType returns the normalized storage type; an empty type means local
+func (s StorageConfig) GetType() string {
+	storageType := strings.ToLower(strings.TrimSpace(s.Type))
+	if storageType == "" {
+		return StorageTypeLocal
+	}
+	return storageType
+}
+
+// GetWorkspace looks up a workspace by name; an empty name selects the default
+func (c *Config) GetWorkspace(name string) (WorkspaceConfig, bool) {
+	workspaces := c.GetWorkspaces()
+	if name == "" {
+		return workspaces[0], true
+	}
+	for _, workspace := range workspaces {
+		if workspace.Name == name {
+			return workspace, true
+		}
+	}
+	return WorkspaceConfig{}, false
+}
+
 func (c *Config) GetHost() string {
 	return c.Server.Host
 }
@@ -35,6 +88,36 @@ func (c *Config) GetCORSConfig() CORSConfig {
 	return c.CORS
 }
 
+func (c *Config) GetReviewConfig() ReviewConfig {
+	return c.Review
+}
+
+func (c *Config) GetLoggingConfig() LoggingConfig {
+	return c.Logging
+}
+
+func (c *Config) GetProfilingConfig() ProfilingConfig {
+	profiling := c.Profiling
+	profiling.Mode = strings.ToLower(strings.TrimSpace(profiling.Mode))
+	return profiling
+}
+
+func (c *Config) GetAdminConfig() AdminConfig {
+	return c.Admin
+}
+
+func (c *Config) GetPerformanceConfig() PerformanceConfig {
+	return c.Performance
+}
+
+func (c *Config) GetCacheConfig() CacheConfig {
+	return c.Cache
+}
+
+func (c *Config) GetBackupConfig() BackupConfig {
+	return c.Backup
+}
+
 func PrintConfig(cfg *Config, env string) {
 	fmt.Println("========== Current Configuration ==========")
 	fmt.Printf("Environment      : %s\n", env)
@@ -43,10 +126,22 @@ func PrintConfig(cfg *Config, env string) {
 	fmt.Printf("Host             : %s\n", cfg.Server.Host)
 	fmt.Printf("Port             : %s\n", cfg.Server.Port)
 	fmt.Printf("Address          : %s\n", cfg.GetServerAddress())
+	fmt.Printf("Shutdown Timeout : %s\n", cfg.Server.ShutdownTimeout)
 
 	fmt.Println("[Static]")
-	fmt.Printf("Root Folder      : %s\n", cfg.Static.RootFolder)
-	fmt.Printf("Backup Folder    : %s\n", cfg.Static.BackupFolder)
+	for _, workspace := range cfg.GetWorkspaces() {
+		fmt.Printf("Workspace        : %s\n", workspace.Name)
+		fmt.Printf("  Storage        : %s\n", workspace.Storage.GetType())
+		if workspace.Storage.GetType() == StorageTypeS3 {
+			fmt.Printf("  Endpoint       : %s\n", workspace.Storage.Endpoint)
+			fmt.Printf("  Bucket         : %s\n", workspace.Storage.Bucket)
+			fmt.Printf("  Prefix         : %s\n", emptyFallback(workspace.Storage.Prefix, "(not set)"))
+			fmt.Printf("  Secret Key     : %s\n", maskSecret(workspace.Storage.SecretKey))
+		} else {
+			fmt.Printf("  Root Folder    : %s\n", workspace.RootFolder)
+		}
+		fmt.Printf("  Backup Folder  : %s\n", workspace.BackupFolder)
+	}
 
 	fmt.Println("[Database]")
 	fmt.Printf("Host             : %s\n", emptyFallback(cfg.Database.Host, "(not set)"))
@@ -60,6 +155,47 @@ func PrintConfig(cfg *Config, env string) {
 	fmt.Printf("Allowed Methods  : %s\n", formatSlice(cfg.CORS.AllowedMethods))
 	fmt.Printf("Allowed Headers  : %s\n", formatSlice(cfg.CORS.AllowedHeaders))
 
+	fmt.Println("[Review]")
+	fmt.Printf("Delete Approval  : %t\n", cfg.Review.RequireDeletionApproval)
+	for _, reviewer := range cfg.Review.Reviewers {
+		role := "reviewer"
+		if reviewer.Adjudicator {
+			role = "adjudicator"
+		}
+		fmt.Printf("Reviewer         : %s (%s, token %s)\n", reviewer.Name, role, maskSecret(reviewer.Token))
+	}
+
+	fmt.Println("[Logging]")
+	fmt.Printf("Level            : %s\n", cfg.Logging.Level)
+	fmt.Printf("Format           : %s\n", cfg.Logging.Format)
+
+	fmt.Println("[Profiling]")
+	fmt.Printf("Enabled          : %t\n", cfg.Profiling.Enabled)
+	fmt.Printf("Mode             : %s\n", cfg.Profiling.Mode)
+	fmt.Printf("Address          : %s\n", cfg.Profiling.Address)
+	fmt.Printf("Mem Profile Rate : %d\n", cfg.Profiling.MemProfileRate)
+	fmt.Printf("Block Rate       : %d\n", cfg.Profiling.BlockProfileRate)
+	fmt.Printf("Mutex Fraction   : %d\n", cfg.Profiling.MutexProfileFraction)
+
+	fmt.Println("[Admin]")
+	fmt.Printf("Token            : %s\n", maskSecret(cfg.Admin.Token))
+
+	fmt.Println("[Performance]")
+	fmt.Printf("Task Slots       : %d\n", cfg.Performance.MaxTaskSlots)
+	fmt.Printf("Page Range       : %d\n", cfg.Performance.PageRangeThreshold)
+	fmt.Printf("Max Image Width  : %d\n", cfg.Performance.MaxImageWidth)
+	fmt.Printf("Image Quality    : %d\n", cfg.Performance.ImageQuality)
+	fmt.Printf("Image Timeout    : %s\n", cfg.Performance.ImageTimeout)
+
+	fmt.Println("[Cache]")
+	fmt.Printf("Expiration       : %s\n", cfg.Cache.Expiration)
+	fmt.Printf("Cleanup Interval : %s\n", cfg.Cache.CleanupInterval)
+	fmt.Printf("Images Per Job   : %d\n", cfg.Cache.MaxImagesPerJob)
+	fmt.Printf("Review Images    : %d\n", cfg.Cache.MaxReviewImages)
+
+	fmt.Println("[Backup]")
+	fmt.Printf("Max Count        : %d\n", cfg.Backup.MaxCount)
+
 	fmt.Println("===========================================")
 }
 
@@ -72,12 +208,12 @@ func validateConfig(config *Config) error {
 	if err := validatePort("server.port", config.Server.Port); err != nil {
 		errs = append(errs, err.Error())
 	}
-
-	if strings.TrimSpace(config.Static.RootFolder) == "" {
-		errs = append(errs, "static.root_folder is empty")
+	if config.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, "server.shutdown_timeout must be positive")
 	}
-	if strings.TrimSpace(config.Static.BackupFolder) == "" {
-		errs = append(errs, "static.backup_folder is empty")
+
+	if err := validateStaticConfig(config.Static); err != nil {
+		errs = append(errs, err.Error())
 	}
 
 	if err := validateDatabaseConfig(config.Database); err != nil {
@@ -88,6 +224,158 @@ func validateConfig(config *Config) error {
 		errs = append(errs, err.Error())
 	}
 
+	if err := validateReviewConfig(config.Review, config.Admin); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := validateLoggingConfig(config.Logging); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := validateProfilingConfig(config.Profiling, config.Admin); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := validatePerformanceConfig(config.Performance); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := validateCacheConfig(config.Cache); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if config.Backup.MaxCount < 1 {
+		errs = append(errs, "backup.max_count must be at least 1")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func validateReviewConfig(review ReviewConfig, admin AdminConfig) error {
+	var errs []string
+
+	names := make(map[string]bool)
+	tokens := make(map[string]string)
+	for i, reviewer := range review.Reviewers {
+		field := fmt.Sprintf("review.reviewers[%d]", i)
+		name := strings.TrimSpace(reviewer.Name)
+		token := strings.TrimSpace(reviewer.Token)
+
+		switch {
+		case name == "":
+			errs = append(errs, fmt.Sprintf("%s.name is empty", field))
+		case names[name]:
+			errs = append(errs, fmt.Sprintf("%s.name is used by another reviewer: %s", field, name))
+		}
+		names[name] = true
+
+		switch {
+		case token == "":
+			errs = append(errs, fmt.Sprintf("%s.token is empty", field))
+		case token == strings.TrimSpace(admin.Token):
+			errs = append(errs, fmt.Sprintf("%s.token must differ from admin.token", field))
+		default:
+			if other, exists := tokens[token]; exists {
+				errs = append(errs, fmt.Sprintf("%s.token is shared with reviewer %s", field, other))
+			}
+			tokens[token] = name
+		}
+	}
+
+	// Two-person approval needs a requester and a different approver
+	if review.RequireDeletionApproval && len(review.Reviewers) < 2 {
+		errs = append(errs, "review.require_deletion_approval needs at least two review.reviewers")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func validateStaticConfig(static StaticConfig) error {
+	var errs []string
+
+	if len(static.Workspaces) == 0 {
+		if static.Storage.GetType() == StorageTypeLocal && strings.TrimSpace(static.RootFolder) == "" {
+			errs = append(errs, "static.root_folder is empty")
+		}
+		if strings.TrimSpace(static.BackupFolder) == "" {
+			errs = append(errs, "static.backup_folder is empty")
+		}
+		if err := validateStorageConfig("static.storage", static.Storage); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	names := make(map[string]bool)
+	backupFolders := make(map[string]string)
+	for i, workspace := range static.Workspaces {
+		field := fmt.Sprintf("static.workspaces[%d]", i)
+
+		switch {
+		case !workspaceNamePattern.MatchString(workspace.Name):
+			errs = append(errs, fmt.Sprintf("%s.name must contain only letters, digits, '-' and '_': %q", field, workspace.Name))
+		case names[workspace.Name]:
+			errs = append(errs, fmt.Sprintf("%s.name is used by another workspace: %s", field, workspace.Name))
+		}
+		names[workspace.Name] = true
+
+		if workspace.Storage.GetType() == StorageTypeLocal && strings.TrimSpace(workspace.RootFolder) == "" {
+			errs = append(errs, fmt.Sprintf("%s.root_folder is empty", field))
+		}
+		if err := validateStorageConfig(field+".storage", workspace.Storage); err != nil {
+			errs = append(errs, err.Error())
+		}
+
+		// Backups, audit log and review state use fixed file names, so two
+		// workspaces writing to the same folder would overwrite each other
+		if strings.TrimSpace(workspace.BackupFolder) == "" {
+			errs = append(errs, fmt.Sprintf("%s.backup_folder is empty", field))
+			continue
+		}
+		backupFolder := filepath.Clean(workspace.BackupFolder)
+		if other, exists := backupFolders[backupFolder]; exists {
+			errs = append(errs, fmt.Sprintf("%s.backup_folder is shared with workspace %s", field, other))
+		}
+		backupFolders[backupFolder] = workspace.Name
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func validateStorageConfig(field string, storage StorageConfig) error {
+	var errs []string
+
+	switch storage.GetType() {
+	case StorageTypeLocal:
+		return nil
+	case StorageTypeS3:
+		endpoint := strings.TrimSpace(storage.Endpoint)
+		if endpoint == "" {
+			errs = append(errs, fmt.Sprintf("%s.endpoint is empty", field))
+		} else if strings.Contains(endpoint, "://") || strings.Contains(endpoint, "/") {
+			errs = append(errs, fmt.Sprintf("%s.endpoint must be host[:port] without scheme or path: %s", field, storage.Endpoint))
+		}
+		if strings.TrimSpace(storage.Bucket) == "" {
+			errs = append(errs, fmt.Sprintf("%s.bucket is empty", field))
+		}
+		if (strings.TrimSpace(storage.AccessKey) == "") != (strings.TrimSpace(storage.SecretKey) == "") {
+			errs = append(errs, fmt.Sprintf("%s.access_key and %s.secret_key must be set together", field, field))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("%s.type must be local or s3: %s", field, storage.Type))
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf(strings.Join(errs, "; "))
 	}
@@ -162,6 +450,116 @@ func validateCORSConfig(cors CORSConfig) error {
 	return nil
 }
 
+func validateLoggingConfig(logging LoggingConfig) error {
+	var errs []string
+
+	switch strings.ToLower(strings.TrimSpace(logging.Level)) {
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		errs = append(errs, fmt.Sprintf("logging.level must be debug, info, warn or error: %s", logging.Level))
+	}
+
+	switch strings.ToLower(strings.TrimSpace(logging.Format)) {
+	case "console", "json":
+	default:
+		errs = append(errs, fmt.Sprintf("logging.format must be console or json: %s", logging.Format))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func validateProfilingConfig(profiling ProfilingConfig, admin AdminConfig) error {
+	if !profiling.Enabled {
+		return nil
+	}
+
+	var errs []string
+
+	switch strings.ToLower(strings.TrimSpace(profiling.Mode)) {
+	case "server":
+		if _, port, err := net.SplitHostPort(profiling.Address); err != nil {
+			errs = append(errs, fmt.Sprintf("profiling.address must be host:port: %s", profiling.Address))
+		} else if err := validatePort("profiling.address port", port); err != nil {
+			errs = append(errs, err.Error())
+		}
+	case "router":
+		if strings.TrimSpace(admin.Token) == "" {
+			errs = append(errs, "profiling.mode router requires admin.token")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("profiling.mode must be server or router: %s", profiling.Mode))
+	}
+
+	if profiling.MemProfileRate < 0 {
+		errs = append(errs, "profiling.mem_profile_rate must not be negative")
+	}
+	if profiling.BlockProfileRate < 0 {
+		errs = append(errs, "profiling.block_profile_rate must not be negative")
+	}
+	if profiling.MutexProfileFraction < 0 {
+		errs = append(errs, "profiling.mutex_profile_fraction must not be negative")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func validatePerformanceConfig(performance PerformanceConfig) error {
+	var errs []string
+
+	if performance.MaxTaskSlots < 1 {
+		errs = append(errs, "performance.max_task_slots must be at least 1")
+	}
+	if performance.PageRangeThreshold < 0 {
+		errs = append(errs, "performance.page_range_threshold must not be negative")
+	}
+	if performance.MaxImageWidth < 1 {
+		errs = append(errs, "performance.max_image_width must be at least 1")
+	}
+	if performance.ImageQuality < 1 || performance.ImageQuality > 100 {
+		errs = append(errs, "performance.image_quality must be between 1 and 100")
+	}
+	if performance.ImageTimeout <= 0 {
+		errs = append(errs, "performance.image_timeout must be positive")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func validateCacheConfig(cache CacheConfig) error {
+	var errs []string
+
+	if cache.Expiration <= 0 {
+		errs = append(errs, "cache.expiration must be positive")
+	}
+	if cache.CleanupInterval <= 0 {
+		errs = append(errs, "cache.cleanup_interval must be positive")
+	}
+	if cache.MaxImagesPerJob < 1 {
+		errs = append(errs, "cache.max_images_per_job must be at least 1")
+	}
+	if cache.MaxReviewImages < 1 {
+		errs = append(errs, "cache.max_review_images must be at least 1")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
 func validatePort(fieldName, port string) error {
 	port = strings.TrimSpace(port)
 	if port == "" {
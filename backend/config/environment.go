@@ -72,16 +72,32 @@ func setDefaults() {
 	setStaticDefaults()
 	setDatabaseDefaults()
 	setCORSDefaults()
+	setReviewDefaults()
+	setLoggingDefaults()
+	setProfilingDefaults()
+	setAdminDefaults()
+	setPerformanceDefaults()
+	setCacheDefaults()
+	setBackupDefaults()
 }
 
 func setServerDefaults() {
 	viper.SetDefault("server.port", "8080")
 	viper.SetDefault("server.host", "localhost")
+	viper.SetDefault("server.shutdown_timeout", "25s")
 }
 
 func setStaticDefaults() {
 	viper.SetDefault("static.root_folder", "./static")
 	viper.SetDefault("static.backup_folder", "./static")
+	viper.SetDefault("static.storage.type", StorageTypeLocal)
+	viper.SetDefault("static.storage.endpoint", "")
+	viper.SetDefault("static.storage.bucket", "")
+	viper.SetDefault("static.storage.prefix", "")
+	viper.SetDefault("static.storage.region", "")
+	viper.SetDefault("static.storage.access_key", "")
+	viper.SetDefault("static.storage.secret_key", "")
+	viper.SetDefault("static.storage.disable_ssl", false)
 }
 
 func setDatabaseDefaults() {
@@ -97,3 +113,45 @@ func setCORSDefaults() {
 	viper.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"})
 	viper.SetDefault("cors.allowed_headers", []string{"*"})
 }
+
+func setReviewDefaults() {
+	viper.SetDefault("review.require_deletion_approval", false)
+	viper.SetDefault("review.reviewers", []ReviewerConfig{})
+}
+
+func setLoggingDefaults() {
+	viper.SetDefault("logging.level", "info")
+	viper.SetDefault("logging.format", "console")
+}
+
+func setProfilingDefaults() {
+	viper.SetDefault("profiling.enabled", true)
+	viper.SetDefault("profiling.mode", "server")
+	viper.SetDefault("profiling.address", "localhost:6060")
+	viper.SetDefault("profiling.mem_profile_rate", 512*1024) // Go runtime default
+	viper.SetDefault("profiling.block_profile_rate", 0)
+	viper.SetDefault("profiling.mutex_profile_fraction", 0)
+}
+
+func setAdminDefaults() {
+	viper.SetDefault("admin.token", "")
+}
+
+func setPerformanceDefaults() {
+	viper.SetDefault("performance.max_task_slots", 10)
+	viper.SetDefault("performance.page_range_threshold", 10)
+	viper.SetDefault("performance.max_image_width", 400)
+	viper.SetDefault("performance.image_quality", 75)
+	viper.SetDefault("performance.image_timeout", "30s")
+}
+
+func setCacheDefaults() {
+	viper.SetDefault("cache.expiration", "10m")
+	viper.SetDefault("cache.cleanup_interval", "3m")
+	viper.SetDefault("cache.max_images_per_job", 2000)
+	viper.SetDefault("cache.max_review_images", 1000)
+}
+
+func setBackupDefaults() {
+	viper.SetDefault("backup.max_count", 10)
+}
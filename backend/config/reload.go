@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// liveSettings are the keys (or key prefixes ending in ".") that can be applied
+// while the server runs. Every other change only takes effect after a restart;
+// profiling.mem_profile_rate is among them because the runtime only honours it
+// when set before the first allocations.
+var liveSettings = []string{
+	"cors.",
+	"logging.level",
+	"review.require_deletion_approval",
+	"review.reviewers",
+	"performance.",
+	"cache.max_images_per_job",
+	"cache.max_review_images",
+	"backup.max_count",
+	"profiling.block_profile_rate",
+	"profiling.mutex_profile_fraction",
+}
+
+// ReloadStatus reports how the configuration file has been reloaded so far.
+// PendingRestart lists the settings whose file value differs from the one the
+// server started with and that cannot be applied live.
+type ReloadStatus struct {
+	Enabled        bool       `json:"enabled"`
+	ConfigFile     string     `json:"config_file,omitempty"`
+	Reloads        int        `json:"reloads"`
+	Failures       int        `json:"failures"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	LastReloadAt   *time.Time `json:"last_reload_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastApplied    []string   `json:"last_applied"`
+	PendingRestart []string   `json:"pending_restart"`
+}
+
+type configReloader struct {
+	startup *Config
+	applied *Config
+	apply   func(*Config)
+	status  ReloadStatus
+	mu      sync.Mutex
+}
+
+var reloader = &configReloader{
+	status: ReloadStatus{LastApplied: []string{}, PendingRestart: []string{}},
+}
+
+// WatchConfig watches the configuration file loaded by LoadConfigForEnvironment.
+// On every change the file is parsed and validated again; an invalid file is
+// rejected and the running configuration kept. For a valid file apply receives
+// the new configuration and should apply its live settings.
+func WatchConfig(current *Config, apply func(*Config)) {
+	configFile := viper.ConfigFileUsed()
+
+	reloader.mu.Lock()
+	reloader.startup = current
+	reloader.applied = current
+	reloader.apply = apply
+	reloader.status.ConfigFile = configFile
+	reloader.status.Enabled = configFile != ""
+	reloader.mu.Unlock()
+
+	if configFile == "" {
+		slog.Warn("No configuration file loaded, hot reload disabled")
+		return
+	}
+
+	viper.OnConfigChange(func(event fsnotify.Event) {
+		reloader.reload(event.Name)
+	})
+	viper.WatchConfig()
+	slog.Info("Watching configuration file for changes", "file", configFile)
+}
+
+// CurrentReloadStatus returns a snapshot of the reload status
+func CurrentReloadStatus() ReloadStatus {
+	reloader.mu.Lock()
+	defer reloader.mu.Unlock()
+
+	status := reloader.status
+	status.LastApplied = append([]string{}, status.LastApplied...)
+	status.PendingRestart = append([]string{}, status.PendingRestart...)
+	return status
+}
+
+func (r *configReloader) reload(file string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.status.LastAttemptAt = &now
+
+	next, err := reloadedConfig()
+	if err != nil {
+		r.status.Failures++
+		r.status.LastError = err.Error()
+		slog.Error("Rejected configuration reload, keeping the running configuration", "file", file, "error", err)
+		return
+	}
+
+	live, _ := splitLiveSettings(ChangedSettings(r.applied, next))
+	_, restart := splitLiveSettings(ChangedSettings(r.startup, next))
+
+	r.apply(next)
+	r.applied = next
+
+	r.status.Reloads++
+	r.status.LastReloadAt = &now
+	r.status.LastError = ""
+	r.status.LastApplied = live
+	r.status.PendingRestart = restart
+
+	slog.Info("Reloaded configuration", "file", file, "applied", live, "pending_restart", restart)
+}
+
+func reloadedConfig() (*Config, error) {
+	config, err := unmarshalConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	overrideWithEnvironmentVariables(config)
+
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("configuration verification failed: %w", err)
+	}
+	return config, nil
+}
+
+// ChangedSettings lists the keys, in their mapstructure form such as
+// "cors.allowed_origins", whose values differ between two configurations
+func ChangedSettings(previous, next *Config) []string {
+	changed := make([]string, 0)
+	collectChangedSettings("", reflect.ValueOf(*previous), reflect.ValueOf(*next), &changed)
+	sort.Strings(changed)
+	return changed
+}
+
+func collectChangedSettings(prefix string, previous, next reflect.Value, changed *[]string) {
+	for i := 0; i < previous.NumField(); i++ {
+		field := previous.Type().Field(i)
+		key := prefix + field.Tag.Get("mapstructure")
+
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			collectChangedSettings(key+".", previous.Field(i), next.Field(i), changed)
+			continue
+		}
+		if !reflect.DeepEqual(previous.Field(i).Interface(), next.Field(i).Interface()) {
+			*changed = append(*changed, key)
+		}
+	}
+}
+
+func splitLiveSettings(keys []string) (live []string, restart []string) {
+	live, restart = make([]string, 0), make([]string, 0)
+	for _, key := range keys {
+		if isLiveSetting(key) {
+			live = append(live, key)
+		} else {
+			restart = append(restart, key)
+		}
+	}
+	return live, restart
+}
+
+func isLiveSetting(key string) bool {
+	for _, setting := range liveSettings {
+		if key == setting || (strings.HasSuffix(setting, ".") && strings.HasPrefix(key, setting)) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,20 +1,57 @@
 package config
 
+import "time"
+
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Static   StaticConfig   `mapstructure:"static"`
-	Database DatabaseConfig `mapstructure:"database"`
-	CORS     CORSConfig     `mapstructure:"cors"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Static      StaticConfig      `mapstructure:"static"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	CORS        CORSConfig        `mapstructure:"cors"`
+	Review      ReviewConfig      `mapstructure:"review"`
+	Logging     LoggingConfig     `mapstructure:"logging"`
+	Profiling   ProfilingConfig   `mapstructure:"profiling"`
+	Admin       AdminConfig       `mapstructure:"admin"`
+	Performance PerformanceConfig `mapstructure:"performance"`
+	Cache       CacheConfig       `mapstructure:"cache"`
+	Backup      BackupConfig      `mapstructure:"backup"`
 }
 
 type ServerConfig struct {
-	Port string `mapstructure:"port"`
-	Host string `mapstructure:"host"`
+	Port            string        `mapstructure:"port"`
+	Host            string        `mapstructure:"host"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 }
 
+// StaticConfig holds the image roots. Without a workspaces list the root and
+// backup folders (and storage) form a single workspace named "default".
 type StaticConfig struct {
-	RootFolder   string `mapstructure:"root_folder"`
-	BackupFolder string `mapstructure:"backup_folder"`
+	RootFolder   string            `mapstructure:"root_folder"`
+	BackupFolder string            `mapstructure:"backup_folder"`
+	Storage      StorageConfig     `mapstructure:"storage"`
+	Workspaces   []WorkspaceConfig `mapstructure:"workspaces"`
+}
+
+// WorkspaceConfig is one named image root with its own backup folder
+type WorkspaceConfig struct {
+	Name         string        `mapstructure:"name"`
+	RootFolder   string        `mapstructure:"root_folder"`
+	BackupFolder string        `mapstructure:"backup_folder"`
+	Storage      StorageConfig `mapstructure:"storage"`
+}
+
+// StorageConfig selects where the images of a workspace are read from: the
+// local root_folder, or a bucket on an S3-compatible service such as MinIO.
+// Backups and review state are always written to the local backup_folder. S3
+// requests use TLS unless disable_ssl is set.
+type StorageConfig struct {
+	Type       string `mapstructure:"type"`
+	Endpoint   string `mapstructure:"endpoint"`
+	Bucket     string `mapstructure:"bucket"`
+	Prefix     string `mapstructure:"prefix"`
+	Region     string `mapstructure:"region"`
+	AccessKey  string `mapstructure:"access_key"`
+	SecretKey  string `mapstructure:"secret_key"`
+	DisableSSL bool   `mapstructure:"disable_ssl"`
 }
 
 type DatabaseConfig struct {
@@ -30,3 +67,58 @@ type CORSConfig struct {
 	AllowedMethods []string `mapstructure:"allowed_methods"`
 	AllowedHeaders []string `mapstructure:"allowed_headers"`
 }
+
+type ReviewConfig struct {
+	RequireDeletionApproval bool             `mapstructure:"require_deletion_approval"`
+	Reviewers               []ReviewerConfig `mapstructure:"reviewers"`
+}
+
+// ReviewerConfig is the token a reviewer authenticates with. Actions that need
+// a verified identity, like approving a deletion batch, accept only reviewers
+// listed here. Adjudicators may read blind review agreement before a session
+// is complete.
+type ReviewerConfig struct {
+	Name        string `mapstructure:"name"`
+	Token       string `mapstructure:"token"`
+	Adjudicator bool   `mapstructure:"adjudicator"`
+}
+
+type LoggingConfig struct {
+	Level  string `mapstructure:"level"`
+	Format string `mapstructure:"format"`
+}
+
+// ProfilingConfig controls the pprof endpoints. In "server" mode they are served
+// on their own listener at Address; in "router" mode they are mounted under
+// /debug/pprof on the main router behind the admin token.
+type ProfilingConfig struct {
+	Enabled              bool   `mapstructure:"enabled"`
+	Mode                 string `mapstructure:"mode"`
+	Address              string `mapstructure:"address"`
+	MemProfileRate       int    `mapstructure:"mem_profile_rate"`
+	BlockProfileRate     int    `mapstructure:"block_profile_rate"`
+	MutexProfileFraction int    `mapstructure:"mutex_profile_fraction"`
+}
+
+type AdminConfig struct {
+	Token string `mapstructure:"token"`
+}
+
+type PerformanceConfig struct {
+	MaxTaskSlots       int           `mapstructure:"max_task_slots"`
+	PageRangeThreshold int           `mapstructure:"page_range_threshold"`
+	MaxImageWidth      int           `mapstructure:"max_image_width"`
+	ImageQuality       int           `mapstructure:"image_quality"`
+	ImageTimeout       time.Duration `mapstructure:"image_timeout"`
+}
+
+type CacheConfig struct {
+	Expiration      time.Duration `mapstructure:"expiration"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+	MaxImagesPerJob int           `mapstructure:"max_images_per_job"`
+	MaxReviewImages int           `mapstructure:"max_review_images"`
+}
+
+type BackupConfig struct {
+	MaxCount int `mapstructure:"max_count"`
+}
@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"backend/config"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Get configuration reload status
+// @Description  Reports configuration file reloads, the settings applied by the last one and the changed settings that need a restart
+// @Tags         config
+// @Produce      json
+// @Success      200  {object}  config.ReloadStatus
+// @Router       /api/getConfigReloadStatus [get]
+func (handle *Handle) GetConfigReloadStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, config.CurrentReloadStatus())
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
-	"backend/src/services"
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"errors"
+	"io/fs"
 	"net/http"
 	"strconv"
 
@@ -15,7 +18,7 @@ import (
 // @Success      200  {object}  map[string]interface{}
 // @Router       /api/getJobs [get]
 func (handle *Handle) GetJobs(c *gin.Context) {
-	jobNames := handle.JointServices.GetJobList()
+	jobNames := handle.joint(c).GetJobList()
 	c.JSON(http.StatusOK, gin.H{
 		"total_jobs": len(jobNames),
 		"job_names":  jobNames,
@@ -23,42 +26,58 @@ func (handle *Handle) GetJobs(c *gin.Context) {
 }
 
 // @Summary      Save pending review
-// @Description  Save pending review data
+// @Description  Replaces the pending review list with the posted items. revision must be the revision returned by getPendingReview or the previous save; when the list changed since, nothing is saved and 409 returns the current revision.
 // @Tags         review
 // @Accept       json
 // @Produce      json
-// @Param        body  body  interface{}  true  "Pending review data"
+// @Param        revision  query  int          true  "Revision of the list the items are based on"
+// @Param        body      body   interface{}  true  "Pending review data"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]string
+// @Failure      409  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]string
 // @Router       /api/savePendingReview [post]
 func (handle *Handle) SavePendingReview(c *gin.Context) {
+	revision, err := strconv.ParseUint(c.Query("revision"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid revision parameter"})
+		return
+	}
+
 	var body interface{}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
 		return
 	}
 
-	itemsLen := handle.JointServices.SavePendingReviewData(body)
+	itemsLen, newRevision, err := handle.joint(c).SavePendingReviewData(body, revision, requestUser(c))
+	if errors.Is(err, models_verify_viewer.ErrPendingReviewStale) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    err.Error(),
+			"revision": newRevision,
+		})
+		return
+	}
 	if itemsLen == 0 {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "fail"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status": "success",
-		"count":  itemsLen,
+		"status":   "success",
+		"count":    itemsLen,
+		"revision": newRevision,
 	})
 }
 
 // @Summary      Get pending review
-// @Description  Get all pending review data
+// @Description  Get all pending review data and the revision of the list, which savePendingReview expects back
 // @Tags         review
 // @Produce      json
 // @Success      200  {object}  interface{}
 // @Router       /api/getPendingReview [get]
 func (handle *Handle) GetPendingReview(c *gin.Context) {
-	c.JSON(http.StatusOK, handle.JointServices.PendingReviewData)
+	c.JSON(http.StatusOK, handle.joint(c).PendingReviewData)
 }
 
 // @Summary      Clear pending review
@@ -68,7 +87,7 @@ func (handle *Handle) GetPendingReview(c *gin.Context) {
 // @Success      200  {object}  map[string]string
 // @Router       /api/clearPendingReview [post]
 func (handle *Handle) ClearPendingReview(c *gin.Context) {
-	handle.JointServices.ClearPendingReview()
+	handle.joint(c).ClearPendingReview(requestUser(c))
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
 		"message": "All pending review data cleared",
@@ -76,7 +95,7 @@ func (handle *Handle) ClearPendingReview(c *gin.Context) {
 }
 
 // @Summary      Get review image
-// @Description  Get original image for review item by job, dataset, and image name (direct file serving)
+// @Description  Get original image for review item by job, dataset, and image name, streamed from the workspace storage
 // @Tags         review
 // @Produce      image/jpeg,image/png
 // @Param        job         query    string  true  "Job name"
@@ -85,6 +104,7 @@ func (handle *Handle) ClearPendingReview(c *gin.Context) {
 // @Success      200  {file}  binary
 // @Failure      400  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
 // @Router       /api/getReviewImage [get]
 func (handle *Handle) GetReviewImage(c *gin.Context) {
 	job := c.Query("job")
@@ -98,12 +118,40 @@ func (handle *Handle) GetReviewImage(c *gin.Context) {
 		return
 	}
 
-	// Construct the full image path using GetImageRoot for consistency
-	root := services.GetImageRoot()
+	// Construct the full image path from the workspace root for consistency
+	root := handle.joint(c).ImageRoot()
 	imagePath := root + "/" + job + "/" + dataset + "/image/" + imageName
 
 	// Serve the file directly as binary
-	c.File(imagePath)
+	serveStoredFile(c, handle.joint(c).Storage(), imagePath)
+}
+
+// serveStoredFile streams a file from storage the way c.File serves one from
+// disk, with its content type, Last-Modified and range requests
+func serveStoredFile(c *gin.Context, store utils.Storage, path string) {
+	info, err := store.Stat(c.Request.Context(), path)
+	if err == nil && info.IsDir {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+			return
+		}
+		requestLogger(c).Error("Failed to stat image", "path", path, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image"})
+		return
+	}
+
+	file, err := store.Open(c.Request.Context(), path)
+	if err != nil {
+		requestLogger(c).Error("Failed to open image", "path", path, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image"})
+		return
+	}
+	defer file.Close()
+
+	http.ServeContent(c.Writer, c.Request, info.Name, info.ModTime, file)
 }
 
 // @Summary      Get pending review image paths
@@ -114,14 +162,14 @@ func (handle *Handle) GetReviewImage(c *gin.Context) {
 // @Failure      404  {object}  map[string]string
 // @Router       /api/getPendingReviewPaths [get]
 func (handle *Handle) GetPendingReviewPaths(c *gin.Context) {
-	items := handle.JointServices.GetPendingReviewItems()
+	items := handle.joint(c).GetPendingReviewItems()
 
 	if len(items) == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "No pending review items found"})
 		return
 	}
 
-	imagePaths := handle.JointServices.GetPendingReviewImagePaths()
+	imagePaths := handle.joint(c).GetPendingReviewImagePaths()
 
 	c.JSON(http.StatusOK, gin.H{
 		"total_items": len(items),
@@ -161,7 +209,7 @@ func (handle *Handle) GetPendingReviewImages(c *gin.Context) {
 	}
 
 	// Get all review items
-	allItems := handle.JointServices.GetPendingReviewItems()
+	allItems := handle.joint(c).GetPendingReviewItems()
 
 	if len(allItems) == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "No pending review items found"})
@@ -192,7 +240,7 @@ func (handle *Handle) GetPendingReviewImages(c *gin.Context) {
 	}
 
 	// Get base64 images using Review cache (separate from ImageGrid cache)
-	base64Images := handle.UserServices.GetOrCreateReviewBase64Images(imagePaths)
+	base64Images := handle.user(c).GetOrCreateReviewBase64Images(imagePaths)
 
 	c.JSON(http.StatusOK, gin.H{
 		"image_path":   imagePaths,
@@ -212,7 +260,7 @@ func (handle *Handle) GetPendingReviewImages(c *gin.Context) {
 // @Failure      500  {object}  map[string]string
 // @Router       /api/getBackupList [get]
 func (handle *Handle) GetBackupList(c *gin.Context) {
-	backups, err := handle.JointServices.GetBackupList()
+	backups, err := handle.joint(c).GetBackupList()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to get backup list",
@@ -252,7 +300,7 @@ func (handle *Handle) RestoreFromBackup(c *gin.Context) {
 		return
 	}
 
-	err := handle.JointServices.RestoreFromBackup(requestBody.Filename)
+	err := handle.joint(c).RestoreFromBackup(requestBody.Filename, requestUser(c))
 	if err != nil {
 		if err.Error() == "backup file not found: "+requestBody.Filename {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -269,7 +317,7 @@ func (handle *Handle) RestoreFromBackup(c *gin.Context) {
 		return
 	}
 
-	items := handle.JointServices.GetPendingReviewItems()
+	items := handle.joint(c).GetPendingReviewItems()
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":         "success",
@@ -280,13 +328,15 @@ func (handle *Handle) RestoreFromBackup(c *gin.Context) {
 }
 
 // @Summary      Delete selected images
-// @Description  Delete selected images from pending review and clear caches
+// @Description  Delete selected images from pending review and clear caches. When deletion approval is required, a pending deletion batch is created instead, and the request needs a reviewer token so the batch records a verified requester.
 // @Tags         review
 // @Accept       json
 // @Produce      json
 // @Param        body  body  interface{}  true  "Array of images to delete"
 // @Success      200  {object}  map[string]interface{}
+// @Success      202  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/deleteSelectedImages [post]
 func (handle *Handle) DeleteSelectedImages(c *gin.Context) {
@@ -296,8 +346,16 @@ func (handle *Handle) DeleteSelectedImages(c *gin.Context) {
 		return
 	}
 
+	// A batch awaiting approval must name a verified requester, otherwise
+	// anyone could approve their own request under a different name
+	if handle.joint(c).DeletionApprovalRequired() {
+		if _, ok := requireReviewer(c); !ok {
+			return
+		}
+	}
+
 	// Delete physical files and get result
-	result, err := handle.JointServices.DeleteSelectedImages(body)
+	result, err := handle.joint(c).DeleteSelectedImages(c.Request.Context(), body, requestUser(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"status": "fail",
@@ -306,8 +364,17 @@ func (handle *Handle) DeleteSelectedImages(c *gin.Context) {
 		return
 	}
 
+	if result.PendingApproval {
+		c.JSON(http.StatusAccepted, gin.H{
+			"status":           "pending_approval",
+			"batch_id":         result.BatchID,
+			"pending_approval": true,
+		})
+		return
+	}
+
 	// Clean up caches for deleted images (non-blocking, errors are logged but don't fail the request)
-	handle.JointServices.CleanupDeletedImagesFromCache(handle.UserServices, result)
+	handle.joint(c).CleanupDeletedImagesFromCache(handle.user(c), result)
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":        "success",
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/services"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Assign datasets to reviewers
+// @Description  Splits the datasets of a job among reviewers, balancing image counts. Replaces existing assignments for the job.
+// @Tags         assignment
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string,reviewers=[]string}  true  "Job name and reviewers"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/assignDatasets [post]
+func (handle *Handle) AssignDatasets(c *gin.Context) {
+	var requestBody struct {
+		Job       string   `json:"job" binding:"required"`
+		Reviewers []string `json:"reviewers" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	assignments, err := handle.joint(c).AssignDatasets(c.Request.Context(), requestBody.Job, requestBody.Reviewers, requestUser(c))
+	if err != nil {
+		respondAssignmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":      "success",
+		"job_name":    requestBody.Job,
+		"count":       len(assignments),
+		"assignments": assignments,
+	})
+}
+
+// @Summary      Get dataset assignments
+// @Description  Returns every dataset of a job with its assigned reviewer and current lock holder
+// @Tags         assignment
+// @Produce      json
+// @Param        job  query  string  true  "Job name"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/getAssignments [get]
+func (handle *Handle) GetAssignments(c *gin.Context) {
+	jobName := c.Query("job")
+	if jobName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job parameter"})
+		return
+	}
+
+	statuses, err := handle.joint(c).GetDatasetStatuses(c.Request.Context(), jobName)
+	if err != nil {
+		respondAssignmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"job_name": jobName,
+		"datasets": statuses,
+	})
+}
+
+// @Summary      Claim dataset
+// @Description  Takes or renews a soft lock on a dataset for the requesting reviewer
+// @Tags         assignment
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string,dataset=string,ttl_seconds=int}  true  "Dataset to claim"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/claimDataset [post]
+func (handle *Handle) ClaimDataset(c *gin.Context) {
+	var requestBody struct {
+		Job        string `json:"job" binding:"required"`
+		Dataset    string `json:"dataset" binding:"required"`
+		TTLSeconds int    `json:"ttl_seconds"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ttl := time.Duration(requestBody.TTLSeconds) * time.Second
+	lock, err := handle.joint(c).ClaimDataset(c.Request.Context(), requestBody.Job, requestBody.Dataset, requestUser(c), ttl)
+	if errors.Is(err, models_verify_viewer.ErrDatasetLocked) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"lock":  lock,
+		})
+		return
+	}
+	if err != nil {
+		respondAssignmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"lock":   lock,
+	})
+}
+
+// @Summary      Release dataset
+// @Description  Releases the soft lock on a dataset. Only the holder may release unless force is set.
+// @Tags         assignment
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string,dataset=string,force=bool}  true  "Dataset to release"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/releaseDataset [post]
+func (handle *Handle) ReleaseDataset(c *gin.Context) {
+	var requestBody struct {
+		Job     string `json:"job" binding:"required"`
+		Dataset string `json:"dataset" binding:"required"`
+		Force   bool   `json:"force"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	err := handle.joint(c).ReleaseDataset(requestBody.Job, requestBody.Dataset, requestUser(c), requestBody.Force)
+	if err != nil {
+		respondAssignmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Dataset released: " + requestBody.Dataset,
+	})
+}
+
+// @Summary      Get dataset locks
+// @Description  Returns live dataset locks, optionally limited to one job
+// @Tags         assignment
+// @Produce      json
+// @Param        job  query  string  false  "Job name"
+// @Success      200  {object}  map[string]interface{}
+// @Router       /api/getDatasetLocks [get]
+func (handle *Handle) GetDatasetLocks(c *gin.Context) {
+	locks := handle.joint(c).GetDatasetLocks(c.Query("job"))
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(locks),
+		"locks": locks,
+	})
+}
+
+func respondAssignmentError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrNoReviewers):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrJobNotFound),
+		errors.Is(err, services.ErrDatasetNotFound),
+		errors.Is(err, models_verify_viewer.ErrDatasetLockNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, models_verify_viewer.ErrDatasetLockNotHeld):
+		status = http.StatusForbidden
+	case errors.Is(err, models_verify_viewer.ErrDatasetLocked):
+		status = http.StatusConflict
+	}
+
+	c.JSON(status, gin.H{"error": err.Error()})
+}
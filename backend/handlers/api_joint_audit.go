@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"backend/src/models_verify_viewer"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Get audit log
+// @Description  Returns audit log entries (newest first) filtered by job, user, action and time range
+// @Tags         audit
+// @Produce      json
+// @Param        job     query    string  false  "Job name"
+// @Param        user    query    string  false  "User who performed the action"
+// @Param        action  query    string  false  "Action (review_saved, review_cleared, backup_restored, image_deleted)"
+// @Param        since   query    string  false  "Start time (RFC3339)"
+// @Param        until   query    string  false  "End time (RFC3339)"
+// @Param        limit   query    int     false  "Maximum number of entries"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/getAuditLog [get]
+func (handle *Handle) GetAuditLog(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := handle.joint(c).QueryAuditLog(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to read audit log",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(entries),
+		"entries": entries,
+	})
+}
+
+func parseAuditFilter(c *gin.Context) (models_verify_viewer.AuditFilter, error) {
+	filter := models_verify_viewer.AuditFilter{
+		JobName: c.Query("job"),
+		User:    c.Query("user"),
+		Action:  c.Query("action"),
+	}
+
+	var err error
+	if filter.Since, err = parseTimeQuery(c, "since"); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseTimeQuery(c, "until"); err != nil {
+		return filter, err
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			return filter, errInvalidParameter("limit")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+func parseTimeQuery(c *gin.Context, key string) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errInvalidParameter(key)
+	}
+	return t, nil
+}
+
+// @Summary      Verify audit log
+// @Description  Walks the audit log and checks the hash chain for tampering
+// @Tags         audit
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/verifyAuditLog [get]
+func (handle *Handle) VerifyAuditLog(c *gin.Context) {
+	count, err := handle.joint(c).VerifyAuditLog()
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"status":        "broken",
+			"valid_entries": count,
+			"error":         err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":        "intact",
+		"valid_entries": count,
+	})
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type blindDecisionRequest struct {
+	SessionID string `json:"session_id" binding:"required"`
+	Dataset   string `json:"dataset" binding:"required"`
+	ImageName string `json:"imageName" binding:"required"`
+	Decision  string `json:"decision" binding:"required"`
+	Note      string `json:"note"`
+}
+
+// @Summary      Create blind review
+// @Description  Draws a reproducible random sample of a job's images for two reviewers to decide on independently
+// @Tags         blind-review
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string,reviewers=[]string,sample_size=int,seed=int}  true  "Blind review definition"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/createBlindReview [post]
+func (handle *Handle) CreateBlindReview(c *gin.Context) {
+	var requestBody struct {
+		Job        string   `json:"job" binding:"required"`
+		Reviewers  []string `json:"reviewers" binding:"required"`
+		SampleSize int      `json:"sample_size"`
+		Seed       int64    `json:"seed"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	summary, err := handle.joint(c).CreateBlindReview(c.Request.Context(),
+		requestBody.Job, requestBody.Reviewers, requestBody.SampleSize, requestBody.Seed, requestUser(c))
+	if err != nil {
+		respondBlindReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"session": summary,
+	})
+}
+
+// @Summary      List blind reviews
+// @Description  Returns blind review sessions with per-reviewer progress but no decisions
+// @Tags         blind-review
+// @Produce      json
+// @Param        job  query  string  false  "Job name"
+// @Success      200  {object}  map[string]interface{}
+// @Router       /api/getBlindReviews [get]
+func (handle *Handle) GetBlindReviews(c *gin.Context) {
+	sessions := handle.joint(c).ListBlindReviews(c.Query("job"))
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(sessions),
+		"sessions": sessions,
+	})
+}
+
+// @Summary      Get blind review
+// @Description  Returns the sample and the requesting reviewer's own decisions; other reviewers' decisions stay hidden. Requires a reviewer token.
+// @Tags         blind-review
+// @Produce      json
+// @Param        sessionId  query  string  true  "Blind review session ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/getBlindReview [get]
+func (handle *Handle) GetBlindReview(c *gin.Context) {
+	sessionID := c.Query("sessionId")
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing sessionId parameter"})
+		return
+	}
+
+	reviewer, ok := requireReviewer(c)
+	if !ok {
+		return
+	}
+
+	view, err := handle.joint(c).GetBlindReviewView(sessionID, reviewer.Name)
+	if err != nil {
+		respondBlindReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// @Summary      Submit blind decision
+// @Description  Records the requesting reviewer's keep/drop decision on a sampled image. Requires a reviewer token.
+// @Tags         blind-review
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{session_id=string,dataset=string,imageName=string,decision=string,note=string}  true  "Decision"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/submitBlindDecision [post]
+func (handle *Handle) SubmitBlindDecision(c *gin.Context) {
+	var requestBody blindDecisionRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reviewer, ok := requireReviewer(c)
+	if !ok {
+		return
+	}
+
+	err := handle.joint(c).SubmitBlindDecision(requestBody.SessionID, reviewer.Name,
+		requestBody.Dataset, requestBody.ImageName, requestBody.Decision, requestBody.Note)
+	if err != nil {
+		respondBlindReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// @Summary      Get blind review agreement
+// @Description  Returns percent agreement and Cohen's kappa per job and dataset plus the conflict list. Until both reviewers have finished only a reviewer token with the adjudicator role can read it.
+// @Tags         blind-review
+// @Produce      json
+// @Param        sessionId  query  string  true  "Blind review session ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/getBlindReviewAgreement [get]
+func (handle *Handle) GetBlindReviewAgreement(c *gin.Context) {
+	sessionID := c.Query("sessionId")
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing sessionId parameter"})
+		return
+	}
+
+	reviewer, authenticated := authenticatedReviewer(c)
+	report, err := handle.joint(c).GetBlindReviewAgreement(sessionID, authenticated && reviewer.Adjudicator)
+	if err != nil {
+		respondBlindReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary      Adjudicate blind review conflict
+// @Description  Records the final decision on a conflicting image; "drop" adds it to pending review, "keep" removes it. Requires a reviewer token with the adjudicator role from neither of the session's reviewers.
+// @Tags         blind-review
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{session_id=string,dataset=string,imageName=string,decision=string,note=string}  true  "Final decision"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/adjudicateBlindReview [post]
+func (handle *Handle) AdjudicateBlindReview(c *gin.Context) {
+	var requestBody blindDecisionRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reviewer, ok := requireReviewer(c)
+	if !ok {
+		return
+	}
+	if !reviewer.Adjudicator {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Adjudicating requires the adjudicator role"})
+		return
+	}
+
+	err := handle.joint(c).AdjudicateBlindReview(requestBody.SessionID, reviewer.Name,
+		requestBody.Dataset, requestBody.ImageName, requestBody.Decision, requestBody.Note)
+	if err != nil {
+		respondBlindReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func respondBlindReviewError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, models_verify_viewer.ErrInvalidDecision),
+		errors.Is(err, models_verify_viewer.ErrBlindReviewerCount),
+		errors.Is(err, models_verify_viewer.ErrBlindReviewEmptySample):
+		status = http.StatusBadRequest
+	case errors.Is(err, models_verify_viewer.ErrNotBlindReviewer),
+		errors.Is(err, models_verify_viewer.ErrBlindReviewIncomplete),
+		errors.Is(err, models_verify_viewer.ErrOwnConflictAdjudicated):
+		status = http.StatusForbidden
+	case errors.Is(err, services.ErrJobNotFound),
+		errors.Is(err, models_verify_viewer.ErrBlindReviewNotFound),
+		errors.Is(err, models_verify_viewer.ErrImageNotInSample):
+		status = http.StatusNotFound
+	case errors.Is(err, models_verify_viewer.ErrBlindReviewNoConflict):
+		status = http.StatusConflict
+	}
+
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Bulk flag images by filter
+// @Description  Adds every image of a job matching the filter (dataset, name glob/regex, label class, confidence range, quality flags) to the pending review set. With dry_run only the match counts are returned.
+// @Tags         review
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string,filter=object,dry_run=bool,note=string}  true  "Job name, filter and options"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/bulkFlag [post]
+func (handle *Handle) BulkFlag(c *gin.Context) {
+	var requestBody struct {
+		Job    string                           `json:"job" binding:"required"`
+		Filter models_verify_viewer.ImageFilter `json:"filter"`
+		DryRun bool                             `json:"dry_run"`
+		Note   string                           `json:"note"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := handle.joint(c).BulkFlag(c.Request.Context(), requestBody.Job, requestBody.Filter, requestBody.DryRun, requestBody.Note, requestUser(c))
+	if err != nil {
+		respondBulkFlagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"result": result,
+	})
+}
+
+func respondBulkFlagError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrJobNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidFilter), errors.Is(err, services.ErrEmptyFilter):
+		status = http.StatusBadRequest
+	case errors.Is(err, models_verify_viewer.ErrQualityNotAnalyzed):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"backend/src/models_verify_viewer"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Get deletion batches
+// @Description  Returns deletion batches awaiting or past two-person approval
+// @Tags         deletion
+// @Produce      json
+// @Param        status  query    string  false  "Batch status (pending, approved, rejected)"
+// @Success      200  {object}  map[string]interface{}
+// @Router       /api/getDeletionBatches [get]
+func (handle *Handle) GetDeletionBatches(c *gin.Context) {
+	batches := handle.joint(c).GetDeletionBatches(c.Query("status"))
+	c.JSON(http.StatusOK, gin.H{
+		"approval_required": handle.joint(c).DeletionApprovalRequired(),
+		"count":             len(batches),
+		"batches":           batches,
+	})
+}
+
+// @Summary      Approve deletion batch
+// @Description  Approves a pending deletion batch and physically deletes its images. Requires a reviewer token; the approver must differ from the requester.
+// @Tags         deletion
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{batch_id=string}  true  "Deletion batch ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/approveDeletionBatch [post]
+func (handle *Handle) ApproveDeletionBatch(c *gin.Context) {
+	var requestBody struct {
+		BatchID string `json:"batch_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reviewer, ok := requireReviewer(c)
+	if !ok {
+		return
+	}
+
+	result, err := handle.joint(c).ApproveDeletionBatch(c.Request.Context(), requestBody.BatchID, reviewer.Name)
+	if err != nil {
+		respondDeletionDecisionError(c, requestBody.BatchID, err)
+		return
+	}
+
+	handle.joint(c).CleanupDeletedImagesFromCache(handle.user(c), result)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":        "success",
+		"batch_id":      result.BatchID,
+		"deleted_count": result.DeletedCount,
+		"cache_cleared": result.CacheCleared,
+		"affected_jobs": result.AffectedJobs,
+	})
+}
+
+// @Summary      Reject deletion batch
+// @Description  Rejects a pending deletion batch; no files are deleted. Requires a reviewer token.
+// @Tags         deletion
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{batch_id=string,reason=string}  true  "Deletion batch ID and reason"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/rejectDeletionBatch [post]
+func (handle *Handle) RejectDeletionBatch(c *gin.Context) {
+	var requestBody struct {
+		BatchID string `json:"batch_id" binding:"required"`
+		Reason  string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reviewer, ok := requireReviewer(c)
+	if !ok {
+		return
+	}
+
+	batch, err := handle.joint(c).RejectDeletionBatch(requestBody.BatchID, reviewer.Name, requestBody.Reason)
+	if err != nil {
+		respondDeletionDecisionError(c, requestBody.BatchID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"batch":  batch,
+	})
+}
+
+func respondDeletionDecisionError(c *gin.Context, batchID string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, models_verify_viewer.ErrDeletionBatchNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, models_verify_viewer.ErrDeletionSelfApproval):
+		status = http.StatusForbidden
+	case errors.Is(err, models_verify_viewer.ErrDeletionBatchNotPending):
+		status = http.StatusConflict
+	}
+
+	c.JSON(status, gin.H{
+		"error":    err.Error(),
+		"batch_id": batchID,
+	})
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Start hash job
+// @Description  Computes perceptual hashes (aHash, dHash, pHash) for every image of a job in the background. Unchanged images reuse their stored hashes.
+// @Tags         duplicates
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string}  true  "Job name"
+// @Success      202  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/startHashJob [post]
+func (handle *Handle) StartHashJob(c *gin.Context) {
+	var requestBody struct {
+		Job string `json:"job" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	status, err := handle.joint(c).StartHashJob(c.Request.Context(), requestBody.Job)
+	if errors.Is(err, models_verify_viewer.ErrAnalysisJobRunning) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  err.Error(),
+			"status": status,
+		})
+		return
+	}
+	if err != nil {
+		respondDuplicateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status": "started",
+		"job":    status,
+	})
+}
+
+// @Summary      Cancel hash job
+// @Description  Stops the running hash job of a job. Hashes computed so far are kept.
+// @Tags         duplicates
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string}  true  "Job name"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/cancelHashJob [post]
+func (handle *Handle) CancelHashJob(c *gin.Context) {
+	var requestBody struct {
+		Job string `json:"job" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := handle.joint(c).CancelHashJob(requestBody.Job); err != nil {
+		respondDuplicateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Hash job cancelled: " + requestBody.Job,
+	})
+}
+
+// @Summary      Get hash job status
+// @Description  Returns the progress of the latest hash job of a job
+// @Tags         duplicates
+// @Produce      json
+// @Param        job  query  string  true  "Job name"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/getHashJobStatus [get]
+func (handle *Handle) GetHashJobStatus(c *gin.Context) {
+	jobName := c.Query("job")
+	if jobName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job parameter"})
+		return
+	}
+
+	status, err := handle.joint(c).GetHashJobStatus(jobName)
+	if err != nil {
+		respondDuplicateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": status})
+}
+
+// @Summary      Get duplicate clusters
+// @Description  Groups the images of a job around the largest file: each cluster holds the image suggested to keep and the images whose perceptual hashes differ from it by at most threshold bits. Results are cached until the next hash job.
+// @Tags         duplicates
+// @Produce      json
+// @Param        job        query  string  true   "Job name"
+// @Param        algorithm  query  string  false  "ahash, dhash or phash"  default(phash)
+// @Param        threshold  query  int     false  "Maximum hash distance in bits"  default(6)
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/getDuplicateClusters [get]
+func (handle *Handle) GetDuplicateClusters(c *gin.Context) {
+	jobName := c.Query("job")
+	if jobName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job parameter"})
+		return
+	}
+
+	threshold, err := parseDuplicateThreshold(c.Query("threshold"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	clusters, err := handle.joint(c).GetDuplicateClusters(jobName, c.Query("algorithm"), threshold)
+	if err != nil {
+		respondDuplicateError(c, err)
+		return
+	}
+
+	redundant := 0
+	for _, cluster := range clusters {
+		redundant += len(cluster.Redundant)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"job_name":        jobName,
+		"cluster_count":   len(clusters),
+		"redundant_count": redundant,
+		"clusters":        clusters,
+	})
+}
+
+// @Summary      Flag duplicates
+// @Description  Adds every redundant image of the duplicate clusters (all but the suggested keep) to the pending review set. With dry_run only the counts are returned.
+// @Tags         duplicates
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string,algorithm=string,threshold=int,dry_run=bool,note=string}  true  "Job name and options"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/flagDuplicates [post]
+func (handle *Handle) FlagDuplicates(c *gin.Context) {
+	var requestBody struct {
+		Job       string `json:"job" binding:"required"`
+		Algorithm string `json:"algorithm"`
+		Threshold *int   `json:"threshold"`
+		DryRun    bool   `json:"dry_run"`
+		Note      string `json:"note"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	threshold := -1
+	if requestBody.Threshold != nil {
+		if *requestBody.Threshold < 0 || *requestBody.Threshold > 64 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParameter("threshold").Error()})
+			return
+		}
+		threshold = *requestBody.Threshold
+	}
+
+	result, err := handle.joint(c).FlagDuplicates(requestBody.Job, requestBody.Algorithm, threshold,
+		requestBody.DryRun, requestBody.Note, requestUser(c))
+	if err != nil {
+		respondDuplicateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"result": result,
+	})
+}
+
+// parseDuplicateThreshold returns -1 for an empty value so the service default applies
+func parseDuplicateThreshold(value string) (int, error) {
+	if value == "" {
+		return -1, nil
+	}
+	threshold, err := strconv.Atoi(value)
+	if err != nil || threshold < 0 || threshold > 64 {
+		return 0, errInvalidParameter("threshold")
+	}
+	return threshold, nil
+}
+
+func respondDuplicateError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrJobNotFound),
+		errors.Is(err, models_verify_viewer.ErrAnalysisJobNotRunning),
+		errors.Is(err, models_verify_viewer.ErrAnalysisJobNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, models_verify_viewer.ErrInvalidHashAlgorithm):
+		status = http.StatusBadRequest
+	case errors.Is(err, models_verify_viewer.ErrAnalysisJobRunning),
+		errors.Is(err, models_verify_viewer.ErrHashesNotComputed):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
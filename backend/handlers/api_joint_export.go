@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/services"
+	"backend/src/utils"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var exportContentTypes = map[string]string{
+	services.ExportFormatCSV:   "text/csv; charset=utf-8",
+	services.ExportFormatJSONL: "application/x-ndjson",
+}
+
+// @Summary      Export review decisions
+// @Description  Downloads decisions (job, dataset, image, decision, reviewer, note, time) as CSV or JSON Lines
+// @Tags         export
+// @Produce      text/csv,application/x-ndjson
+// @Param        job          query  string  false  "Job name (all jobs when empty)"
+// @Param        format       query  string  false  "csv or jsonl"  default(csv)
+// @Param        includeKeep  query  bool    false  "Also emit a keep row for every undecided image"
+// @Success      200  {file}  binary
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/exportDecisions [get]
+func (handle *Handle) ExportDecisions(c *gin.Context) {
+	jobName := c.Query("job")
+	format := c.DefaultQuery("format", services.ExportFormatCSV)
+	if !services.IsValidExportFormat(format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format parameter, expected csv or jsonl"})
+		return
+	}
+
+	records, err := handle.joint(c).BuildDecisionRecords(c.Request.Context(), jobName, c.Query("includeKeep") == "true")
+	if err != nil {
+		respondExportError(c, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteDecisionRecords(&buf, format, records); err != nil {
+		respondExportError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("decisions_%s_%s.%s", exportScope(jobName), time.Now().Format("20060102_150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, exportContentTypes[format], buf.Bytes())
+}
+
+// @Summary      Get dataset manifests
+// @Description  Returns keep and drop lists for every dataset of a job (paths relative to the image root)
+// @Tags         export
+// @Produce      json
+// @Param        job  query  string  true  "Job name"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/getManifests [get]
+func (handle *Handle) GetManifests(c *gin.Context) {
+	jobName := c.Query("job")
+	if jobName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job parameter"})
+		return
+	}
+
+	manifests, err := handle.joint(c).BuildManifests(c.Request.Context(), jobName)
+	if err != nil {
+		respondExportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"job_name":  jobName,
+		"manifests": manifests,
+	})
+}
+
+// @Summary      Export dataset manifest
+// @Description  Downloads the keep or drop list of one dataset as plain text, one image path per line
+// @Tags         export
+// @Produce      plain
+// @Param        job      query  string  true  "Job name"
+// @Param        dataset  query  string  true  "Dataset name"
+// @Param        list     query  string  true  "keep or drop"
+// @Success      200  {file}  binary
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/exportManifest [get]
+func (handle *Handle) ExportManifest(c *gin.Context) {
+	jobName := c.Query("job")
+	datasetName := c.Query("dataset")
+	list := c.Query("list")
+	if jobName == "" || datasetName == "" || list == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job, dataset or list parameter"})
+		return
+	}
+
+	manifests, err := handle.joint(c).BuildManifests(c.Request.Context(), jobName)
+	if err != nil {
+		respondExportError(c, err)
+		return
+	}
+
+	for _, manifest := range manifests {
+		if manifest.DatasetName != datasetName {
+			continue
+		}
+
+		paths, ok := manifest.List(list)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid list parameter, expected keep or drop"})
+			return
+		}
+
+		body := strings.Join(paths, "\n")
+		if len(paths) > 0 {
+			body += "\n"
+		}
+		filename := fmt.Sprintf("%s.%s.txt", datasetName, list)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(body))
+		return
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "Dataset not found: " + datasetName})
+}
+
+// @Summary      Export training set
+// @Description  Starts copying the images of a job that are not pending review, with their labels as COCO and/or YOLO annotations, into the backup folder's exports directory in the background. Poll getTrainingSetExportStatus for progress and the summary.
+// @Tags         export
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string,formats=[]string,archive=bool}  true  "Job name, annotation formats (coco, yolo) and whether to write a tar.gz archive"
+// @Success      202  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/exportTrainingSet [post]
+func (handle *Handle) ExportTrainingSet(c *gin.Context) {
+	var requestBody struct {
+		Job     string   `json:"job" binding:"required"`
+		Formats []string `json:"formats"`
+		Archive bool     `json:"archive"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	formats := requestBody.Formats
+	if len(formats) == 0 {
+		formats = []string{utils.AnnotationFormatCOCO, utils.AnnotationFormatYOLO}
+	}
+
+	status, err := handle.joint(c).StartTrainingSetExport(c.Request.Context(), requestBody.Job, formats, requestBody.Archive)
+	if errors.Is(err, models_verify_viewer.ErrAnalysisJobRunning) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  err.Error(),
+			"status": status,
+		})
+		return
+	}
+	if err != nil {
+		respondExportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status": "started",
+		"job":    status,
+	})
+}
+
+// @Summary      Cancel training set export
+// @Description  Stops the running training set export of a job. Files written so far are left in place.
+// @Tags         export
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string}  true  "Job name"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/cancelTrainingSetExport [post]
+func (handle *Handle) CancelTrainingSetExport(c *gin.Context) {
+	var requestBody struct {
+		Job string `json:"job" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := handle.joint(c).CancelTrainingSetExport(requestBody.Job); err != nil {
+		respondExportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Training set export cancelled: " + requestBody.Job,
+	})
+}
+
+// @Summary      Get training set export status
+// @Description  Returns the progress of the latest training set export of a job and, once it has finished, its summary
+// @Tags         export
+// @Produce      json
+// @Param        job  query  string  true  "Job name"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/getTrainingSetExportStatus [get]
+func (handle *Handle) GetTrainingSetExportStatus(c *gin.Context) {
+	jobName := c.Query("job")
+	if jobName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job parameter"})
+		return
+	}
+
+	status, err := handle.joint(c).GetTrainingSetExportStatus(jobName)
+	if err != nil {
+		respondExportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": status})
+}
+
+func exportScope(jobName string) string {
+	if jobName == "" {
+		return "all"
+	}
+	return jobName
+}
+
+func respondExportError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrJobNotFound),
+		errors.Is(err, models_verify_viewer.ErrAnalysisJobNotRunning),
+		errors.Is(err, models_verify_viewer.ErrAnalysisJobNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidAnnotationFormat):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"backend/src/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Liveness probe
+// @Description  Runs the dependency checks of every workspace and fails only when a critical one (job watcher, review store) fails
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  models_verify_viewer.HealthReport
+// @Failure      503  {object}  models_verify_viewer.HealthReport
+// @Router       /healthz [get]
+func (handle *Handle) Healthz(c *gin.Context) {
+	report := services.CheckWorkspacesHealth(c.Request.Context(), handle.Workspaces())
+	if !report.Live() {
+		requestLogger(c).Error("Liveness check failed", "checks", report.Checks)
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary      Readiness probe
+// @Description  Fails unless, in every workspace, the image root is readable, the backup directory is writable, the job watcher is running and the review stores respond
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  models_verify_viewer.HealthReport
+// @Failure      503  {object}  models_verify_viewer.HealthReport
+// @Router       /readyz [get]
+func (handle *Handle) Readyz(c *gin.Context) {
+	report := services.CheckWorkspacesHealth(c.Request.Context(), handle.Workspaces())
+	if !report.Ready() {
+		requestLogger(c).Warn("Readiness check failed", "checks", report.Checks)
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary      Diagnostics snapshot
+// @Description  Summarizes the image and review caches, review sessions, compression tasks and background analyses. Requires the admin token.
+// @Tags         health
+// @Produce      json
+// @Param        workspace  query  string  false  "Workspace name, defaults to the first configured workspace"
+// @Success      200  {object}  services.DebugState
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /debug/state [get]
+func (handle *Handle) GetDebugState(c *gin.Context) {
+	c.JSON(http.StatusOK, services.BuildDebugState(handle.user(c), handle.joint(c)))
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"backend/src/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Get review progress
+// @Description  Returns images seen, images decided and time spent per dataset and reviewer for a job
+// @Tags         progress
+// @Produce      json
+// @Param        job       query  string  true   "Job name"
+// @Param        dataset   query  string  false  "Dataset name"
+// @Param        reviewer  query  string  false  "Reviewer"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/getReviewProgress [get]
+func (handle *Handle) GetReviewProgress(c *gin.Context) {
+	jobName := c.Query("job")
+	if jobName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job parameter"})
+		return
+	}
+
+	report, err := handle.joint(c).GetReviewProgress(c.Request.Context(), jobName, c.Query("dataset"), c.Query("reviewer"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrJobNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
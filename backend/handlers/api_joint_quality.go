@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Start quality check
+// @Description  Analyzes every image of a job in the background for decode errors, blur (Laplacian variance), under/over-exposure and low resolution. Omitted thresholds take the defaults.
+// @Tags         quality
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string,thresholds=object}  true  "Job name and optional thresholds"
+// @Success      202  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/startQualityCheck [post]
+func (handle *Handle) StartQualityCheck(c *gin.Context) {
+	var requestBody struct {
+		Job        string                                 `json:"job" binding:"required"`
+		Thresholds models_verify_viewer.QualityThresholds `json:"thresholds"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	status, err := handle.joint(c).StartQualityCheck(c.Request.Context(), requestBody.Job, requestBody.Thresholds)
+	if errors.Is(err, models_verify_viewer.ErrAnalysisJobRunning) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  err.Error(),
+			"status": status,
+		})
+		return
+	}
+	if err != nil {
+		respondQualityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status": "started",
+		"job":    status,
+	})
+}
+
+// @Summary      Cancel quality check
+// @Description  Stops the running quality check of a job. Images analyzed so far are kept.
+// @Tags         quality
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string}  true  "Job name"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/cancelQualityCheck [post]
+func (handle *Handle) CancelQualityCheck(c *gin.Context) {
+	var requestBody struct {
+		Job string `json:"job" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := handle.joint(c).CancelQualityCheck(requestBody.Job); err != nil {
+		respondQualityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Quality check cancelled: " + requestBody.Job,
+	})
+}
+
+// @Summary      Get quality check status
+// @Description  Returns the progress of the latest quality check of a job
+// @Tags         quality
+// @Produce      json
+// @Param        job  query  string  true  "Job name"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/getQualityCheckStatus [get]
+func (handle *Handle) GetQualityCheckStatus(c *gin.Context) {
+	jobName := c.Query("job")
+	if jobName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job parameter"})
+		return
+	}
+
+	status, err := handle.joint(c).GetQualityCheckStatus(jobName)
+	if err != nil {
+		respondQualityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": status})
+}
+
+// @Summary      Get quality report
+// @Description  Returns the flag counts of a job and the images raising the given flag (any flag when omitted). Flagged images can be bulk flagged with the quality_flags filter of /api/bulkFlag.
+// @Tags         quality
+// @Produce      json
+// @Param        job   query  string  true   "Job name"
+// @Param        flag  query  string  false  "corrupt, blurry, underexposed, overexposed or low_resolution"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/getQualityReport [get]
+func (handle *Handle) GetQualityReport(c *gin.Context) {
+	jobName := c.Query("job")
+	if jobName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job parameter"})
+		return
+	}
+
+	report, err := handle.joint(c).GetQualityReport(jobName, c.Query("flag"))
+	if err != nil {
+		respondQualityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":  len(report.Images),
+		"report": report,
+	})
+}
+
+func respondQualityError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrJobNotFound),
+		errors.Is(err, models_verify_viewer.ErrAnalysisJobNotRunning),
+		errors.Is(err, models_verify_viewer.ErrAnalysisJobNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidQualityThresholds),
+		errors.Is(err, models_verify_viewer.ErrInvalidQualityFlag):
+		status = http.StatusBadRequest
+	case errors.Is(err, models_verify_viewer.ErrAnalysisJobRunning),
+		errors.Is(err, models_verify_viewer.ErrQualityNotAnalyzed):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
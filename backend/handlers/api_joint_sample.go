@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Get samples
+// @Description  Lists recorded spot-check samples (definition, population and sample size per stratum), newest first
+// @Tags         sample
+// @Produce      json
+// @Param        job  query  string  false  "Job name (all jobs when empty)"
+// @Success      200  {object}  map[string]interface{}
+// @Router       /api/getSamples [get]
+func (handle *Handle) GetSamples(c *gin.Context) {
+	samples := handle.joint(c).GetSamples(c.Query("job"))
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(samples),
+		"samples": samples,
+	})
+}
+
+// @Summary      Get sample
+// @Description  Returns a recorded sample including every sampled image
+// @Tags         sample
+// @Produce      json
+// @Param        id  query  string  true  "Sample ID"
+// @Success      200  {object}  models_verify_viewer.SampleRecord
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/getSample [get]
+func (handle *Handle) GetSample(c *gin.Context) {
+	sampleID := c.Query("id")
+	if sampleID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing id parameter"})
+		return
+	}
+
+	sample, err := handle.joint(c).GetSample(sampleID)
+	if err != nil {
+		respondSampleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sample)
+}
+
+// @Summary      Get sample estimate
+// @Description  Extrapolates the share of sampled images pending review to the population of every stratum, with a 95% margin of error
+// @Tags         sample
+// @Produce      json
+// @Param        id  query  string  true  "Sample ID"
+// @Success      200  {object}  models_verify_viewer.SampleEstimate
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/getSampleEstimate [get]
+func (handle *Handle) GetSampleEstimate(c *gin.Context) {
+	sampleID := c.Query("id")
+	if sampleID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing id parameter"})
+		return
+	}
+
+	estimate, err := handle.joint(c).GetSampleEstimate(sampleID)
+	if err != nil {
+		respondSampleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, estimate)
+}
+
+func respondSampleError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrJobNotFound), errors.Is(err, models_verify_viewer.ErrSampleNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidSample):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const profilingPrefix = "/debug/pprof/"
+
+// ProfilingMux serves the pprof endpoints for a dedicated profiling listener
+func ProfilingMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(profilingPrefix, serveProfile)
+	return mux
+}
+
+// RegisterProfilingRoutes mounts the pprof endpoints under /debug/pprof on the
+// main router, guarded by the given middleware
+func RegisterProfilingRoutes(r *gin.Engine, guard gin.HandlerFunc) {
+	profiling := r.Group("/debug/pprof", guard)
+	{
+		profiling.GET("/*profile", gin.WrapF(serveProfile))
+		profiling.POST("/symbol", gin.WrapF(pprof.Symbol))
+	}
+}
+
+// serveProfile dispatches to the pprof handler named by the last path element.
+// pprof.Index serves both the listing and every named profile (heap, goroutine, ...).
+func serveProfile(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, profilingPrefix) {
+	case "cmdline":
+		pprof.Cmdline(w, r)
+	case "profile":
+		pprof.Profile(w, r)
+	case "symbol":
+		pprof.Symbol(w, r)
+	case "trace":
+		pprof.Trace(w, r)
+	default:
+		pprof.Index(w, r)
+	}
+}
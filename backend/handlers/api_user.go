@@ -1,20 +1,23 @@
 package handlers
 
 import (
-	"log"
+	"backend/src/models_verify_viewer"
+	"backend/src/services"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary      Set all pages for a job
-// @Description  Sets all page details for a given job
+// @Description  Sets all page details for a given job. page_mode "dataset" (default) chunks every dataset separately, "packed" fills pages across datasets. Images can be sorted by name (natural order), mtime, size, label confidence or randomly with a seed; packed pages sort the whole job. A sample (random, or stratified by dataset or label class, with a rate or count and a seed) pages only the sampled images and is recorded for extrapolation.
 // @Tags         pages
 // @Accept       json
 // @Produce      json
-// @Param        job  query  string  true  "Job name"
-// @Param        pages  body  []string  true  "List of page details"
+// @Param        pages  body  object{job=string,image_per_page=int,page_mode=string,sort=object{key=string,order=string,seed=int},sample=object{method=string,stratify=string,rate=number,count=int,seed=int}}  true  "Job name, images per page, page mode, optional sort and sample"
 // @Success      200  {object}  map[string]string
 // @Failure      400  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
@@ -22,8 +25,11 @@ import (
 // @Router       /api/setAllPages [post]
 func (handle *Handle) SetAllPageDetails(c *gin.Context) {
 	type PageRequest struct {
-		Job          string `json:"job" binding:"required"`
-		ImagePerPage int    `json:"image_per_page" binding:"required,gt=0"`
+		Job          string                          `json:"job" binding:"required"`
+		ImagePerPage int                             `json:"image_per_page" binding:"required,gt=0"`
+		PageMode     string                          `json:"page_mode"`
+		Sort         models_verify_viewer.ImageSort  `json:"sort"`
+		Sample       models_verify_viewer.SampleSpec `json:"sample"`
 	}
 
 	var req PageRequest
@@ -34,23 +40,87 @@ func (handle *Handle) SetAllPageDetails(c *gin.Context) {
 		return
 	}
 
-	log.Printf("[SetAllPageDetails] REQUEST - job: %s, image_per_page: %d", req.Job, req.ImagePerPage)
+	layout, err := resolvePageLayout(req.PageMode, req.Sort, req.Sample, handle.user(c).CurrentPageData.Layout())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	pageDataExists := handle.UserServices.CurrentPageDataExists(req.Job)
-	log.Printf("[SetAllPageDetails] CurrentPageDataExists(%s): %v", req.Job, pageDataExists)
+	logger := requestLogger(c).With("job", req.Job)
 
+	// Search pages (setSearchPages) only cover part of the job, so they are rebuilt here,
+	// as are pages built with a different layout
+	pageDataExists := handle.user(c).FullPageDataExists(req.Job, layout)
 	if !pageDataExists {
-		log.Printf("[SetAllPageDetails] Clearing and setting new page data for job: %s", req.Job)
-		handle.UserServices.ClearImageCache(req.Job)
-		handle.UserServices.ClearCurrentPageData()
-		handle.UserServices.SetCurrentPageData(req.Job, req.ImagePerPage)
+		logger.Debug("Building page data", "image_per_page", req.ImagePerPage, "layout", layout)
+		var sample *models_verify_viewer.SampleRecord
+		if layout.Sample.Enabled() {
+			record, err := handle.joint(c).CreateSample(c.Request.Context(), req.Job, layout.Sample, requestUser(c))
+			if err != nil {
+				respondSampleError(c, err)
+				return
+			}
+			sample = &record
+		}
+
+		handle.user(c).ClearImageCache(req.Job)
+		handle.user(c).ClearCurrentPageData()
+		handle.user(c).SetCurrentPageData(c.Request.Context(), req.Job, req.ImagePerPage, layout, sample)
 	} else {
-		log.Printf("[SetAllPageDetails] Page data already exists for job: %s, skipping setup", req.Job)
+		logger.Debug("Page data already exists, skipping setup", "layout", layout)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"message": "All pages set successfully for job: " + req.Job,
-	})
+		"layout":  layout,
+	}
+	if sampleID := handle.user(c).CurrentPageData.SampleID(); sampleID != "" {
+		response["sample_id"] = sampleID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// resolvePageLayout validates and normalizes the requested layout. A random sort
+// or sample without a seed reuses the seed of the current pages when they were
+// built the same way, or draws one from the clock, so repeated setAllPages calls
+// keep the same pages.
+func resolvePageLayout(pageMode string, order models_verify_viewer.ImageSort, sample models_verify_viewer.SampleSpec, current models_verify_viewer.PageLayout) (models_verify_viewer.PageLayout, error) {
+	switch pageMode {
+	case "":
+		pageMode = models_verify_viewer.PageModeDataset
+	case models_verify_viewer.PageModeDataset, models_verify_viewer.PageModePacked:
+	default:
+		return models_verify_viewer.PageLayout{}, fmt.Errorf("page_mode must be %s or %s",
+			models_verify_viewer.PageModeDataset, models_verify_viewer.PageModePacked)
+	}
+
+	if err := order.Validate(); err != nil {
+		return models_verify_viewer.PageLayout{}, err
+	}
+	order = order.Normalize()
+	if order.Key == models_verify_viewer.SortKeyRandom && order.Seed == 0 {
+		if current.Sort.Key == models_verify_viewer.SortKeyRandom {
+			order.Seed = current.Sort.Seed
+		} else {
+			order.Seed = time.Now().UnixNano()
+		}
+	}
+
+	if err := sample.Validate(); err != nil {
+		return models_verify_viewer.PageLayout{}, err
+	}
+	sample = sample.Normalize()
+	if sample.Enabled() && sample.Seed == 0 {
+		unseeded := current.Sample
+		unseeded.Seed = 0
+		if unseeded == sample {
+			sample.Seed = current.Sample.Seed
+		} else {
+			sample.Seed = time.Now().UnixNano()
+		}
+	}
+
+	return models_verify_viewer.PageLayout{Mode: pageMode, Sort: order, Sample: sample}, nil
 }
 
 // @Summary      Get all pages for a job
@@ -65,32 +135,29 @@ func (handle *Handle) SetAllPageDetails(c *gin.Context) {
 // @Router       /api/getAllPages [get]
 func (handle *Handle) GetAllPageDetails(c *gin.Context) {
 	jobName := c.Query("job")
-	log.Printf("[GetAllPageDetails] REQUEST - job: %s", jobName)
 
 	if jobName == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job parameter"})
 		return
 	}
 
-	pageDataExists := handle.UserServices.CurrentPageDataExists(jobName)
-	log.Printf("[GetAllPageDetails] CurrentPageDataExists(%s): %v", jobName, pageDataExists)
-
+	pageDataExists := handle.user(c).CurrentPageDataExists(jobName)
 	if !pageDataExists {
-		log.Printf("[GetAllPageDetails] ERROR - Job not found or pages not initialized for: %s", jobName)
+		requestLogger(c).Debug("Job not found or pages not initialized")
 		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found or pages not initialized"})
 		return
 	}
 
-	pages, exist := handle.UserServices.GetCurrentPageData(jobName)
+	pages, exist := handle.user(c).GetCurrentPageData(jobName)
 	if !exist {
-		log.Printf("[GetAllPageDetails] ERROR - Pages not found after existence check for job: %s", jobName)
+		requestLogger(c).Warn("Pages not found after existence check")
 		c.JSON(http.StatusNotFound, gin.H{"error": "Pages not found for the job"})
 		return
 	}
 
 	// Use PageItemsReadOnly for performance - avoid copying large slice before JSON serialization
 	pageItems := pages.PageItemsReadOnly()
-	log.Printf("[GetAllPageDetails] SUCCESS - Returning %d pages for job: %s", len(pageItems), jobName)
+	requestLogger(c).Debug("Returning all pages", "pages", len(pageItems))
 	c.JSON(http.StatusOK, gin.H{
 		"total_pages": len(pageItems),
 		"pages":       pageItems,
@@ -98,7 +165,7 @@ func (handle *Handle) GetAllPageDetails(c *gin.Context) {
 }
 
 // @Summary      Get job metadata (lightweight)
-// @Description  Returns lightweight job metadata including per-page dataset mapping and total pages without image details
+// @Description  Returns lightweight job metadata without image details: total pages, the dataset of the first image of every page (dataset_names) and every dataset on every page (page_dataset_names)
 // @Tags         pages
 // @Produce      json
 // @Param        job  query  string  true  "Job name"
@@ -108,25 +175,22 @@ func (handle *Handle) GetAllPageDetails(c *gin.Context) {
 // @Router       /api/getJobMetadata [get]
 func (handle *Handle) GetJobMetadata(c *gin.Context) {
 	jobName := c.Query("job")
-	log.Printf("[GetJobMetadata] REQUEST - job: %s", jobName)
 
 	if jobName == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job parameter"})
 		return
 	}
 
-	pageDataExists := handle.UserServices.CurrentPageDataExists(jobName)
-	log.Printf("[GetJobMetadata] CurrentPageDataExists(%s): %v", jobName, pageDataExists)
-
+	pageDataExists := handle.user(c).CurrentPageDataExists(jobName)
 	if !pageDataExists {
-		log.Printf("[GetJobMetadata] ERROR - Job not found or pages not initialized for: %s", jobName)
+		requestLogger(c).Debug("Job not found or pages not initialized")
 		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found or pages not initialized"})
 		return
 	}
 
-	pages, exist := handle.UserServices.GetCurrentPageData(jobName)
+	pages, exist := handle.user(c).GetCurrentPageData(jobName)
 	if !exist {
-		log.Printf("[GetJobMetadata] ERROR - Pages not found after existence check for job: %s", jobName)
+		requestLogger(c).Warn("Pages not found after existence check")
 		c.JSON(http.StatusNotFound, gin.H{"error": "Pages not found for the job"})
 		return
 	}
@@ -135,12 +199,23 @@ func (handle *Handle) GetJobMetadata(c *gin.Context) {
 	datasetNames := pages.GetDatasetNames()
 	totalPages := pages.Len()
 
-	log.Printf("[GetJobMetadata] SUCCESS - job: %s, total_pages: %d, datasets: %d", jobName, totalPages, len(datasetNames))
-	c.JSON(http.StatusOK, gin.H{
-		"job_name":      jobName,
-		"total_pages":   totalPages,
-		"dataset_names": datasetNames,
-	})
+	searchQuery, isSearch := pages.SearchQuery()
+
+	requestLogger(c).Debug("Returning job metadata", "pages", totalPages, "datasets", len(datasetNames))
+	response := gin.H{
+		"job_name":           jobName,
+		"total_pages":        totalPages,
+		"dataset_names":      datasetNames,
+		"page_dataset_names": pages.GetPageDatasetNames(),
+		"layout":             pages.Layout(),
+	}
+	if sampleID := pages.SampleID(); sampleID != "" {
+		response["sample_id"] = sampleID
+	}
+	if isSearch {
+		response["search_query"] = searchQuery
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // @Summary      Get page by page index
@@ -169,12 +244,12 @@ func (handle *Handle) GetPageByPageIndex(c *gin.Context) {
 		return
 	}
 
-	if !handle.UserServices.CurrentPageDataExists(jobName) {
+	if !handle.user(c).CurrentPageDataExists(jobName) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found or pages not initialized"})
 		return
 	}
 
-	pages, exist := handle.UserServices.GetCurrentPageData(jobName)
+	pages, exist := handle.user(c).GetCurrentPageData(jobName)
 	if !exist {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Pages not found for the job"})
 		return
@@ -201,22 +276,20 @@ func parsePageIndex(pageIndex string) (int, error) {
 }
 
 // @Summary      Get base64image by page index
-// @Description  Returns all base64 encoded images for a specific page of a job
+// @Description  Returns all base64 encoded images for a specific page of a job. Each entry of "images" carries a status (ok, decode_error, missing, cancelled, timed_out) and an error message; failed images have an empty base64 string. Partial pages are returned with 200.
 // @Tags         images
 // @Produce      json
 // @Param        job  query  string  true  "Job name"
-// @Param        dataset  query  string  true  "Dataset name"
+// @Param        pageIndex  query  string  true  "Page index"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      503  {object}  map[string]interface{}
 // @Router       /api/getBase64ImageSet [get]
 func (handle *Handle) GetBase64ImageByPageIndex(c *gin.Context) {
 	jobName := c.Query("job")
 	pageIndex := c.Query("pageIndex")
 
-	log.Printf("[GetBase64ImageByPageIndex] REQUEST - job: %s, pageIndex: %s", jobName, pageIndex)
-
 	if jobName == "" || pageIndex == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job or pageIndex parameter"})
 		return
@@ -228,11 +301,12 @@ func (handle *Handle) GetBase64ImageByPageIndex(c *gin.Context) {
 		return
 	}
 
-	imagePaths, base64Images := handle.getOrCreateBase64ImageCache(jobName, index, pageIndex)
+	logger := requestLogger(c)
+	results := getOrCreateBase64ImageCache(handle.user(c), logger, jobName, index)
 
-	// Check if task was cancelled (returns nil, nil)
-	if imagePaths == nil || base64Images == nil {
-		log.Printf("[GetBase64ImageByPageIndex] ERROR - Task was cancelled for job: %s, page: %d", jobName, index)
+	// nil means the page no longer belongs to the job (job switched during processing)
+	if results == nil {
+		logger.Warn("Page image processing was cancelled")
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"error": "Image processing was cancelled, please retry",
 			"code":  "TASK_CANCELLED",
@@ -240,42 +314,61 @@ func (handle *Handle) GetBase64ImageByPageIndex(c *gin.Context) {
 		return
 	}
 
-	// Check for empty arrays
-	if len(imagePaths) == 0 || len(base64Images) == 0 {
-		log.Printf("[GetBase64ImageByPageIndex] ERROR - No images found for job: %s, page: %d", jobName, index)
+	if len(results) == 0 {
+		logger.Debug("No images found for page")
 		c.JSON(http.StatusNotFound, gin.H{"error": "No images found for the specified page"})
 		return
 	}
 
-	// Check for empty base64 strings in response
-	emptyCount := 0
-	for _, b64 := range base64Images {
-		if b64 == "" {
-			emptyCount++
+	imagePaths := make([]string, len(results))
+	base64Images := make([]string, len(results))
+	failedCount, cancelledCount := 0, 0
+	for i, result := range results {
+		imagePaths[i] = result.ImagePath
+		base64Images[i] = result.Base64
+		if !result.OK() {
+			failedCount++
 		}
+		if result.Status == models_verify_viewer.ImageStatusCancelled {
+			cancelledCount++
+		}
+	}
+
+	// A page where nothing was processed is still worth a retry; partial pages are returned
+	if cancelledCount == len(results) {
+		logger.Warn("Page image processing was cancelled")
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":  "Image processing was cancelled, please retry",
+			"code":   "TASK_CANCELLED",
+			"images": results,
+		})
+		return
 	}
-	if emptyCount > 0 {
-		log.Printf("[GetBase64ImageByPageIndex] WARNING - Returning %d empty base64 strings out of %d total for job: %s, page: %d", emptyCount, len(base64Images), jobName, index)
+
+	if failedCount > 0 {
+		logger.Warn("Returning partial page", "failed", failedCount, "images", len(results))
 	} else {
-		log.Printf("[GetBase64ImageByPageIndex] SUCCESS - Returning %d images for job: %s, page: %d", len(base64Images), jobName, index)
+		logger.Debug("Returning page images", "images", len(results))
 	}
 
+	handle.recordPageView(c, jobName, index)
+
 	c.JSON(http.StatusOK, gin.H{
 		"image_path":   imagePaths,
 		"base64_image": base64Images,
+		"images":       results,
+		"failed_count": failedCount,
 	})
 }
 
-func (handle *Handle) getOrCreateBase64ImageCache(jobName string, index int, pageIndex string) ([]string, []string) {
-	if handle.UserServices.ImageCacheExists(jobName, index) {
-		log.Printf("[getOrCreateBase64ImageCache] Cache HIT for job: %s, page: %d", jobName, index)
-		imagePaths, base64Images := handle.UserServices.GetBase64ImageCacheByPage(jobName, index)
-		log.Printf("[getOrCreateBase64ImageCache] Retrieved from cache: %d paths, %d images", len(imagePaths), len(base64Images))
-		return imagePaths, base64Images
+func getOrCreateBase64ImageCache(us *services.UserServices, logger *slog.Logger, jobName string, index int) []models_verify_viewer.ImageResult {
+	if us.ImageCacheExists(jobName, index) {
+		logger.Debug("Image cache hit")
+		return us.GetBase64ImageCacheByPage(jobName, index)
 	}
 
-	log.Printf("[getOrCreateBase64ImageCache] Cache MISS - Creating base64 image cache for job: %s, page: %s", jobName, pageIndex)
-	return handle.UserServices.SetBase64ImageCacheByPage(jobName, index)
+	logger.Debug("Image cache miss, compressing page")
+	return us.SetBase64ImageCacheByPage(jobName, index)
 }
 
 // @Summary      Get base64 image by image path
@@ -289,7 +382,7 @@ func (handle *Handle) getOrCreateBase64ImageCache(jobName string, index int, pag
 // @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/getBase64Image [get]
-func (Handle *Handle) GetBase64ImageByImagePath(c *gin.Context) {
+func (handle *Handle) GetBase64ImageByImagePath(c *gin.Context) {
 	jobName := c.Query("job")
 	imagePath := c.Query("imagePath")
 	if jobName == "" || imagePath == "" {
@@ -297,7 +390,7 @@ func (Handle *Handle) GetBase64ImageByImagePath(c *gin.Context) {
 		return
 	}
 
-	base64Image := Handle.UserServices.GetBase64ImageByPath(jobName, imagePath)
+	base64Image := handle.user(c).GetBase64ImageByPath(jobName, imagePath)
 	if base64Image == "" {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
 		return
@@ -335,12 +428,12 @@ func (handle *Handle) GetImageByPageIndex(c *gin.Context) {
 		return
 	}
 
-	if !handle.UserServices.CurrentPageDataExists(jobName) {
+	if !handle.user(c).CurrentPageDataExists(jobName) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Image cache not found for the specified job"})
 		return
 	}
 
-	imageNames, imagePaths := handle.UserServices.GetImageCacheByPage(jobName, index)
+	imageNames, imagePaths := handle.user(c).GetImageCacheByPage(jobName, index)
 	if len(imageNames) == 0 && len(imagePaths) == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "No images found for the specified page"})
 		return
@@ -351,3 +444,11 @@ func (handle *Handle) GetImageByPageIndex(c *gin.Context) {
 		"image_path": imagePaths,
 	})
 }
+
+// recordPageView credits the images of a page to the reviewer. Only the image
+// payload counts as a view; the page metadata endpoints do not record one.
+func (handle *Handle) recordPageView(c *gin.Context, jobName string, index int) {
+	if pageItem, found := handle.user(c).CurrentPageData.PageAt(index); found {
+		handle.joint(c).RecordPageView(requestUser(c), jobName, pageItem)
+	}
+}
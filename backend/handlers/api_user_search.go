@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Search images of a job
+// @Description  Filters a job's images by name substring, dataset, file size, dimensions, modification time, label presence and decision status
+// @Tags         search
+// @Produce      json
+// @Param        job             query  string  true   "Job name"
+// @Param        name            query  string  false  "Case insensitive substring of the image name"
+// @Param        dataset         query  string  false  "Dataset name"
+// @Param        minSize         query  int     false  "Minimum file size in bytes"
+// @Param        maxSize         query  int     false  "Maximum file size in bytes"
+// @Param        minWidth        query  int     false  "Minimum width in pixels"
+// @Param        maxWidth        query  int     false  "Maximum width in pixels"
+// @Param        minHeight       query  int     false  "Minimum height in pixels"
+// @Param        maxHeight       query  int     false  "Maximum height in pixels"
+// @Param        modifiedAfter   query  string  false  "Modified at or after (RFC3339)"
+// @Param        modifiedBefore  query  string  false  "Modified at or before (RFC3339)"
+// @Param        hasLabel        query  bool    false  "Only images with (true) or without (false) a label file"
+// @Param        decision        query  string  false  "pending or undecided"
+// @Param        page            query  int     false  "Result page, starting at 0"
+// @Param        pageSize        query  int     false  "Results per page"  default(50)
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/searchImages [get]
+func (handle *Handle) SearchImages(c *gin.Context) {
+	jobName := c.Query("job")
+	if jobName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing job parameter"})
+		return
+	}
+
+	var query models_verify_viewer.ImageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid search parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "0"))
+	if err != nil || page < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParameter("page").Error()})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", strconv.Itoa(services.DefaultSearchPageSize)))
+	if err != nil || pageSize <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParameter("pageSize").Error()})
+		return
+	}
+
+	result, err := handle.joint(c).SearchJobImages(c.Request.Context(), jobName, query, page, pageSize)
+	if err != nil {
+		respondSearchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary      Use search results as pages
+// @Description  Replaces the current pages with the images matching the query so the grid can page through them. setAllPages restores the whole job.
+// @Tags         search
+// @Accept       json
+// @Produce      json
+// @Param        body  body  object{job=string,image_per_page=int,query=object}  true  "Job name, page size and query"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/setSearchPages [post]
+func (handle *Handle) SetSearchPages(c *gin.Context) {
+	var requestBody struct {
+		Job          string                          `json:"job" binding:"required"`
+		ImagePerPage int                             `json:"image_per_page" binding:"required,gt=0"`
+		Query        models_verify_viewer.ImageQuery `json:"query"`
+	}
+
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	hits, err := handle.joint(c).MatchImageQuery(c.Request.Context(), requestBody.Job, requestBody.Query)
+	if err != nil {
+		respondSearchError(c, err)
+		return
+	}
+
+	handle.user(c).SetSearchPageData(requestBody.Job, requestBody.Query, hits, requestBody.ImagePerPage)
+
+	c.JSON(http.StatusOK, gin.H{
+		"job_name":    requestBody.Job,
+		"total":       len(hits),
+		"total_pages": handle.user(c).CurrentPageData.Len(),
+	})
+}
+
+func respondSearchError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrJobNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidQuery):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
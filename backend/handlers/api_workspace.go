@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"backend/src/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      List workspaces
+// @Description  Returns the configured image roots. API calls select one with /api/workspaces/{workspace}/..., the X-Workspace header or the workspace query parameter; without one the default workspace is used.
+// @Tags         workspaces
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Router       /api/getWorkspaces [get]
+func (handle *Handle) GetWorkspaces(c *gin.Context) {
+	workspaces := make([]services.WorkspaceSummary, 0, len(handle.workspaceNames))
+	for _, ws := range handle.Workspaces() {
+		workspaces = append(workspaces, ws.Summary(ws.Name == handle.defaultWorkspace))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"workspaces": workspaces,
+		"default":    handle.defaultWorkspace,
+	})
+}
@@ -1,54 +1,190 @@
 package handlers
 
 import (
+	"backend/src/models_verify_viewer"
 	"backend/src/services"
+	"backend/src/utils"
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	reviewerHeader    = "X-Reviewer"
+	anonymousReviewer = "anonymous"
+)
+
 type Handle struct {
-	UserServices  *services.UserServices
-	JointServices *services.JointServices
-	ctx           context.Context
+	workspaces       map[string]*services.Workspace
+	workspaceNames   []string
+	defaultWorkspace string
+	reviewerAuth     *ReviewerAuth
+	adminAuth        gin.HandlerFunc
+	ctx              context.Context
+}
+
+// HandleOptions carries the configuration the services are built from. The
+// first workspace is the default for requests that do not name one.
+type HandleOptions struct {
+	Workspaces              []services.WorkspaceConfig
+	RequireDeletionApproval bool
+	CacheLimits             models_verify_viewer.CacheLimits
+	MaxBackupCount          int
+	Reviewers               []Reviewer
+	AdminToken              string
+}
+
+func NewHandle(ctx context.Context, options HandleOptions) *Handle {
+	handle := &Handle{
+		workspaces:   make(map[string]*services.Workspace, len(options.Workspaces)),
+		reviewerAuth: NewReviewerAuth(options.Reviewers),
+		adminAuth:    AdminAuth(options.AdminToken),
+		ctx:          ctx,
+	}
+
+	for _, config := range options.Workspaces {
+		ws := services.NewWorkspace(ctx, config, options.CacheLimits)
+		ws.JointServices.SetDeletionApprovalRequired(options.RequireDeletionApproval)
+		ws.JointServices.SetMaxBackupCount(options.MaxBackupCount)
+
+		handle.workspaces[ws.Name] = ws
+		handle.workspaceNames = append(handle.workspaceNames, ws.Name)
+	}
+	if len(handle.workspaceNames) > 0 {
+		handle.defaultWorkspace = handle.workspaceNames[0]
+	}
+	return handle
 }
 
-func NewHandle(ctx context.Context, root string, backupDir string) *Handle {
-	services.SetConfig(root, backupDir)
-	js := services.NewJointServices(ctx)
-	us := services.NewUserServices()
+// Workspaces returns every workspace in configuration order
+func (handle *Handle) Workspaces() []*services.Workspace {
+	workspaces := make([]*services.Workspace, 0, len(handle.workspaceNames))
+	for _, name := range handle.workspaceNames {
+		workspaces = append(workspaces, handle.workspaces[name])
+	}
+	return workspaces
+}
 
-	services.CheckServicesState(us, js)
-	return &Handle{
-		UserServices:  us,
-		JointServices: js,
-		ctx:           ctx,
+// Shutdown stops background work and writes the final backups of every
+// workspace. Call it after the HTTP server has stopped accepting requests.
+func (handle *Handle) Shutdown(ctx context.Context) {
+	for _, ws := range handle.Workspaces() {
+		ws.JointServices.Shutdown(ctx)
 	}
 }
 
+// SetReviewers replaces the reviewer credentials accepted from the next request on
+func (handle *Handle) SetReviewers(reviewers []Reviewer) {
+	handle.reviewerAuth.Update(reviewers)
+}
+
 func (handle *Handle) RegisterRoutes(r *gin.Engine) {
-	api := r.Group("/api")
-	{
-		api.GET("/getJobs", handle.GetJobs)
-
-		api.POST("/setAllPages", handle.SetAllPageDetails)
-		api.GET("/getAllPages", handle.GetAllPageDetails)
-		api.GET("/getJobMetadata", handle.GetJobMetadata)
-		api.GET("/getPage", handle.GetPageByPageIndex)
-
-		api.GET("/getBase64ImageSet", handle.GetBase64ImageByPageIndex)
-		api.GET("/getBase64Image", handle.GetBase64ImageByImagePath)
-		api.GET("/getImageSet", handle.GetImageByPageIndex)
-
-		api.POST("/savePendingReview", handle.SavePendingReview)
-		api.GET("/getPendingReview", handle.GetPendingReview)
-		api.POST("/clearPendingReview", handle.ClearPendingReview)
-		api.GET("/getReviewImage", handle.GetReviewImage)
-		api.GET("/getPendingReviewPaths", handle.GetPendingReviewPaths)
-		api.GET("/getPendingReviewImages", handle.GetPendingReviewImages)
-		api.POST("/deleteSelectedImages", handle.DeleteSelectedImages)
-
-		api.GET("/getBackupList", handle.GetBackupList)
-		api.POST("/restoreFromBackup", handle.RestoreFromBackup)
+	r.GET("/metrics", gin.WrapH(utils.MetricsHandler()))
+	r.GET("/healthz", handle.Healthz)
+	r.GET("/readyz", handle.Readyz)
+	r.GET("/debug/state", handle.adminAuth, handle.ResolveWorkspace(), handle.GetDebugState)
+
+	r.GET("/api/getWorkspaces", handle.GetWorkspaces)
+	r.GET("/api/getConfigReloadStatus", handle.GetConfigReloadStatus)
+
+	// Every API call can name its workspace in the path; the unscoped routes
+	// take it from the X-Workspace header or the workspace query parameter.
+	handle.registerAPIRoutes(r.Group("/api", handle.ResolveWorkspace(), handle.reviewerAuth.Middleware()))
+	handle.registerAPIRoutes(r.Group("/api/workspaces/:workspace", handle.ResolveWorkspace(), handle.reviewerAuth.Middleware()))
+}
+
+func (handle *Handle) registerAPIRoutes(api *gin.RouterGroup) {
+	api.GET("/getJobs", handle.GetJobs)
+
+	api.POST("/setAllPages", handle.SetAllPageDetails)
+	api.GET("/getAllPages", handle.GetAllPageDetails)
+	api.GET("/getJobMetadata", handle.GetJobMetadata)
+	api.GET("/getPage", handle.GetPageByPageIndex)
+
+	api.GET("/getBase64ImageSet", handle.GetBase64ImageByPageIndex)
+	api.GET("/getBase64Image", handle.GetBase64ImageByImagePath)
+	api.GET("/getImageSet", handle.GetImageByPageIndex)
+
+	api.POST("/savePendingReview", handle.SavePendingReview)
+	api.GET("/getPendingReview", handle.GetPendingReview)
+	api.POST("/clearPendingReview", handle.ClearPendingReview)
+	api.GET("/getReviewImage", handle.GetReviewImage)
+	api.GET("/getPendingReviewPaths", handle.GetPendingReviewPaths)
+	api.GET("/getPendingReviewImages", handle.GetPendingReviewImages)
+	api.POST("/deleteSelectedImages", handle.DeleteSelectedImages)
+	api.GET("/getDeletionBatches", handle.GetDeletionBatches)
+	api.POST("/approveDeletionBatch", handle.ApproveDeletionBatch)
+	api.POST("/rejectDeletionBatch", handle.RejectDeletionBatch)
+
+	api.POST("/bulkFlag", handle.BulkFlag)
+
+	api.POST("/startHashJob", handle.StartHashJob)
+	api.POST("/cancelHashJob", handle.CancelHashJob)
+	api.GET("/getHashJobStatus", handle.GetHashJobStatus)
+	api.GET("/getDuplicateClusters", handle.GetDuplicateClusters)
+	api.POST("/flagDuplicates", handle.FlagDuplicates)
+
+	api.POST("/startQualityCheck", handle.StartQualityCheck)
+	api.POST("/cancelQualityCheck", handle.CancelQualityCheck)
+	api.GET("/getQualityCheckStatus", handle.GetQualityCheckStatus)
+	api.GET("/getQualityReport", handle.GetQualityReport)
+
+	api.GET("/searchImages", handle.SearchImages)
+	api.POST("/setSearchPages", handle.SetSearchPages)
+
+	api.GET("/getBackupList", handle.GetBackupList)
+	api.POST("/restoreFromBackup", handle.RestoreFromBackup)
+
+	api.POST("/assignDatasets", handle.AssignDatasets)
+	api.GET("/getAssignments", handle.GetAssignments)
+	api.POST("/claimDataset", handle.ClaimDataset)
+	api.POST("/releaseDataset", handle.ReleaseDataset)
+	api.GET("/getDatasetLocks", handle.GetDatasetLocks)
+
+	api.POST("/createBlindReview", handle.CreateBlindReview)
+	api.GET("/getBlindReviews", handle.GetBlindReviews)
+	api.GET("/getBlindReview", handle.GetBlindReview)
+	api.POST("/submitBlindDecision", handle.SubmitBlindDecision)
+	api.GET("/getBlindReviewAgreement", handle.GetBlindReviewAgreement)
+	api.POST("/adjudicateBlindReview", handle.AdjudicateBlindReview)
+
+	api.GET("/getReviewProgress", handle.GetReviewProgress)
+
+	api.GET("/getSamples", handle.GetSamples)
+	api.GET("/getSample", handle.GetSample)
+	api.GET("/getSampleEstimate", handle.GetSampleEstimate)
+
+	api.GET("/exportDecisions", handle.ExportDecisions)
+	api.GET("/getManifests", handle.GetManifests)
+	api.GET("/exportManifest", handle.ExportManifest)
+	api.POST("/exportTrainingSet", handle.ExportTrainingSet)
+	api.POST("/cancelTrainingSetExport", handle.CancelTrainingSetExport)
+	api.GET("/getTrainingSetExportStatus", handle.GetTrainingSetExportStatus)
+
+	api.GET("/getAuditLog", handle.GetAuditLog)
+	api.GET("/verifyAuditLog", handle.VerifyAuditLog)
+}
+
+// requestUser identifies the reviewer behind a request. A reviewer token wins;
+// otherwise the unverified X-Reviewer header or "user" query parameter is used
+// as a label.
+func requestUser(c *gin.Context) string {
+	if reviewer, ok := authenticatedReviewer(c); ok {
+		return reviewer.Name
 	}
+
+	user := strings.TrimSpace(c.GetHeader(reviewerHeader))
+	if user == "" {
+		user = strings.TrimSpace(c.Query("user"))
+	}
+	if user == "" {
+		return anonymousReviewer
+	}
+	return user
+}
+
+func errInvalidParameter(name string) error {
+	return fmt.Errorf("Invalid %s parameter", name)
 }
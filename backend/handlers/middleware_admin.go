@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	adminTokenHeader = "X-Admin-Token"
+	bearerPrefix     = "Bearer "
+)
+
+// AdminAuth lets a request through only when it carries the admin token, either
+// as "Authorization: Bearer <token>" or in the X-Admin-Token header. With no
+// token configured every request is refused.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access is not configured"})
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(adminToken(c)), []byte(token)) != 1 {
+			requestLogger(c).Warn("Rejected admin request", "path", c.Request.URL.Path)
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing admin token"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func adminToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); strings.HasPrefix(header, bearerPrefix) {
+		return strings.TrimSpace(strings.TrimPrefix(header, bearerPrefix))
+	}
+	return strings.TrimSpace(c.GetHeader(adminTokenHeader))
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"sync/atomic"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// DynamicCORS is a CORS middleware whose settings can be replaced while the
+// server runs, so reloaded origins apply to the next request
+type DynamicCORS struct {
+	handler atomic.Pointer[gin.HandlerFunc]
+}
+
+func NewDynamicCORS(config cors.Config) (*DynamicCORS, error) {
+	dynamic := &DynamicCORS{}
+	if err := dynamic.Update(config); err != nil {
+		return nil, err
+	}
+	return dynamic, nil
+}
+
+// Update swaps in new CORS settings; invalid settings are rejected and the
+// current ones kept
+func (dynamic *DynamicCORS) Update(config cors.Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	handler := cors.New(config)
+	dynamic.handler.Store(&handler)
+	return nil
+}
+
+func (dynamic *DynamicCORS) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		(*dynamic.handler.Load())(c)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	requestIDHeader  = "X-Request-ID"
+	loggerContextKey = "logger"
+	requestIDBytes   = 8
+)
+
+// RequestLogger tags every request with a request ID (taken from the X-Request-ID
+// header or generated), stores a logger carrying the request's job, page and
+// reviewer for the handlers, and logs one line per finished request.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := strings.TrimSpace(c.GetHeader(requestIDHeader))
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		c.Header(requestIDHeader, requestID)
+
+		attrs := []any{"request_id", requestID}
+		if jobName := c.Query("job"); jobName != "" {
+			attrs = append(attrs, "job", jobName)
+		}
+		if pageIndex := c.Query("pageIndex"); pageIndex != "" {
+			attrs = append(attrs, "page", pageIndex)
+		}
+		if user := requestUser(c); user != anonymousReviewer {
+			attrs = append(attrs, "user", user)
+		}
+
+		logger := slog.Default().With(attrs...)
+		c.Set(loggerContextKey, logger)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		logger.Log(c.Request.Context(), level, "Request handled",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		)
+	}
+}
+
+// requestLogger returns the request-scoped logger set by RequestLogger
+func requestLogger(c *gin.Context) *slog.Logger {
+	if value, exists := c.Get(loggerContextKey); exists {
+		if logger, ok := value.(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+func newRequestID() string {
+	buf := make([]byte, requestIDBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
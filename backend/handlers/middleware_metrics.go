@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"backend/src/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMetrics records the latency of every request labelled by its route
+// template, so /api/getPage?pageIndex=3 and ?pageIndex=4 share one series.
+func RequestMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		utils.ObserveHTTPRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	reviewerTokenHeader   = "X-Reviewer-Token"
+	reviewerContextKey    = "reviewer"
+	reviewerAuthChallenge = `Bearer realm="reviewer"`
+)
+
+// Reviewer is a reviewer credential from the configuration
+type Reviewer struct {
+	Name        string
+	Token       string
+	Adjudicator bool
+}
+
+// ReviewerAuth holds the reviewer credentials, which can be replaced while the
+// server runs so a reloaded reviewer list applies to the next request
+type ReviewerAuth struct {
+	reviewers atomic.Pointer[[]Reviewer]
+}
+
+func NewReviewerAuth(reviewers []Reviewer) *ReviewerAuth {
+	auth := &ReviewerAuth{}
+	auth.Update(reviewers)
+	return auth
+}
+
+// Update swaps in a new reviewer list
+func (auth *ReviewerAuth) Update(reviewers []Reviewer) {
+	reviewers = append([]Reviewer(nil), reviewers...)
+	auth.reviewers.Store(&reviewers)
+}
+
+// Middleware identifies the reviewer behind a request from a reviewer token,
+// sent as "Authorization: Bearer <token>" or in the X-Reviewer-Token header.
+// Requests without a token continue unauthenticated; an unknown token is
+// refused so a mistyped token never falls back to an unverified name.
+func (auth *ReviewerAuth) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := reviewerToken(c)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		reviewer, ok := findReviewer(*auth.reviewers.Load(), token)
+		if !ok {
+			requestLogger(c).Warn("Rejected reviewer token", "path", c.Request.URL.Path)
+			c.Header("WWW-Authenticate", reviewerAuthChallenge)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid reviewer token"})
+			return
+		}
+
+		c.Set(reviewerContextKey, reviewer)
+		c.Set(loggerContextKey, requestLogger(c).With("reviewer", reviewer.Name))
+		c.Next()
+	}
+}
+
+// findReviewer compares the token against every reviewer so the time taken
+// does not reveal which one matched
+func findReviewer(reviewers []Reviewer, token string) (Reviewer, bool) {
+	var found Reviewer
+	ok := false
+	for _, reviewer := range reviewers {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(reviewer.Token)) == 1 {
+			found = reviewer
+			ok = true
+		}
+	}
+	return found, ok
+}
+
+func reviewerToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); strings.HasPrefix(header, bearerPrefix) {
+		return strings.TrimSpace(strings.TrimPrefix(header, bearerPrefix))
+	}
+	return strings.TrimSpace(c.GetHeader(reviewerTokenHeader))
+}
+
+// authenticatedReviewer returns the reviewer whose token the request carried
+func authenticatedReviewer(c *gin.Context) (Reviewer, bool) {
+	value, exists := c.Get(reviewerContextKey)
+	if !exists {
+		return Reviewer{}, false
+	}
+	reviewer, ok := value.(Reviewer)
+	return reviewer, ok
+}
+
+// requireReviewer responds with 401 when the request carries no reviewer token
+func requireReviewer(c *gin.Context) (Reviewer, bool) {
+	reviewer, ok := authenticatedReviewer(c)
+	if !ok {
+		c.Header("WWW-Authenticate", reviewerAuthChallenge)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "A reviewer token is required for this action"})
+	}
+	return reviewer, ok
+}
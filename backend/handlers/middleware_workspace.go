@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"backend/src/services"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	workspaceParam      = "workspace"
+	workspaceHeader     = "X-Workspace"
+	workspaceContextKey = "workspace"
+)
+
+// ResolveWorkspace picks the workspace a request works on from the :workspace
+// path parameter, the X-Workspace header or the workspace query parameter, in
+// that order. Requests naming none use the default workspace; unknown names
+// are rejected before they reach a handler.
+func (handle *Handle) ResolveWorkspace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := requestWorkspace(c)
+		ws, ok := handle.workspace(name)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Unknown workspace: %s", name)})
+			return
+		}
+
+		c.Set(workspaceContextKey, ws)
+		c.Set(loggerContextKey, requestLogger(c).With("workspace", ws.Name))
+		c.Next()
+	}
+}
+
+func requestWorkspace(c *gin.Context) string {
+	if name := strings.TrimSpace(c.Param(workspaceParam)); name != "" {
+		return name
+	}
+	if name := strings.TrimSpace(c.GetHeader(workspaceHeader)); name != "" {
+		return name
+	}
+	return strings.TrimSpace(c.Query(workspaceParam))
+}
+
+func (handle *Handle) workspace(name string) (*services.Workspace, bool) {
+	if name == "" {
+		name = handle.defaultWorkspace
+	}
+	ws, ok := handle.workspaces[name]
+	return ws, ok
+}
+
+// joint returns the joint services of the workspace resolved for the request
+func (handle *Handle) joint(c *gin.Context) *services.JointServices {
+	return c.MustGet(workspaceContextKey).(*services.Workspace).JointServices
+}
+
+// user returns the user services of the workspace resolved for the request
+func (handle *Handle) user(c *gin.Context) *services.UserServices {
+	return c.MustGet(workspaceContextKey).(*services.Workspace).UserServices
+}
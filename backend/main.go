@@ -3,109 +3,268 @@ package main
 import (
 	"backend/config"
 	"backend/handlers"
+	"backend/src/services"
+	"backend/src/utils"
 	"context"
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
-	_ "net/http/pprof"
+	"os"
+	"os/signal"
 	"runtime"
+	"strings"
+	"syscall"
+	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 const (
 	defaultEnvironment = "production"
 	corsMaxAge         = 12 * 3600
+
+	profilingModeServer = "server"
+	profilingModeRouter = "router"
+
+	// finalBackupTimeout bounds the final backups when draining the requests
+	// used up the shutdown deadline
+	finalBackupTimeout = 10 * time.Second
 )
 
-var cfg *config.Config
+var (
+	cfg          *config.Config
+	cliWorkspace string
+
+	// backupLocks stay referenced so their files are not closed and unlocked
+	backupLocks []*utils.BackupDirLock
+)
 
 func init() {
 	cfg = loadConfiguration()
 }
 
 func main() {
-	ctx := createContext()
-	defer ctx.Value("cancel").(context.CancelFunc)()
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(flag.Args()))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	startPprofServer()
+	lockBackupFolders()
+	startProfiling()
+	utils.ConfigureCompression(compressionSettings(cfg))
 
-	router := setupRouter(ctx)
-	startServer(router)
+	router, handle, corsMiddleware := setupRouter(ctx)
+	watchConfiguration(handle, corsMiddleware)
+	runServer(ctx, router, handle)
 }
 
 func loadConfiguration() *config.Config {
 	env := flag.String("env", defaultEnvironment, "Set the environment (development|production)")
+	flag.StringVar(&cliWorkspace, "workspace", "", "Workspace commands work on (defaults to the first configured workspace)")
 	flag.Parse()
 
 	configuration, err := config.LoadConfigForEnvironment(*env)
 	if err != nil {
-		log.Fatalf("Error loading configuration for environment %s: %v", *env, err)
+		slog.Error("Error loading configuration", "environment", *env, "error", err)
+		os.Exit(1)
 	}
 
-	log.Printf("Loaded configuration for environment: %s", *env)
-	config.PrintConfig(configuration, *env)
+	loggingConfig := configuration.GetLoggingConfig()
+	if err := utils.ConfigureLogger(loggingConfig.Level, loggingConfig.Format); err != nil {
+		slog.Error("Error configuring logger", "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Loaded configuration", "environment", *env)
+	if flag.NArg() == 0 {
+		// Subcommands may write their results to stdout; keep it clean for them
+		config.PrintConfig(configuration, *env)
+	}
 
 	return configuration
 }
 
-func createContext() context.Context {
-	ctx, cancel := context.WithCancel(context.Background())
-	return context.WithValue(ctx, "cancel", cancel)
+// lockBackupFolders holds the lock of every workspace backup folder while the
+// server runs, so commands that change review state refuse to run meanwhile
+func lockBackupFolders() {
+	for _, workspace := range cfg.GetWorkspaces() {
+		lock, err := utils.LockBackupDir(workspace.BackupFolder)
+		if err != nil {
+			slog.Error("Failed to lock backup folder", "workspace", workspace.Name, "error", err)
+			os.Exit(1)
+		}
+		backupLocks = append(backupLocks, lock)
+	}
 }
 
-func startPprofServer() {
-	runtime.MemProfileRate = 1 // Enable memory profiling
+// startProfiling applies the profiling sample rates and, in server mode, serves
+// pprof on its own listener. Router mode is mounted in setupRouter.
+func startProfiling() {
+	profiling := cfg.GetProfilingConfig()
+	if !profiling.Enabled {
+		slog.Info("Profiling disabled")
+		return
+	}
+
+	// The memory profile rate must be set as early as possible and only once, so
+	// unlike the other rates it is not applied again on reload
+	runtime.MemProfileRate = profiling.MemProfileRate
+	applyProfilingRates(profiling)
+
+	if profiling.Mode != profilingModeServer {
+		return
+	}
 
-	pprofAddr := ":6060"
 	go func() {
-		log.Printf("Starting pprof server at %s", pprofAddr)
-		log.Printf("Access profiling at:")
-		log.Printf("  - Heap profile: http://localhost:6060/debug/pprof/heap")
-		log.Printf("  - Goroutine profile: http://localhost:6060/debug/pprof/goroutine")
-		log.Printf("  - CPU profile: http://localhost:6060/debug/pprof/profile?seconds=30")
-		log.Printf("  - All profiles: http://localhost:6060/debug/pprof/")
-
-		if err := http.ListenAndServe(pprofAddr, nil); err != nil {
-			log.Printf("pprof server error: %v", err)
+		slog.Info("Starting pprof server", "address", profiling.Address,
+			"profiles", fmt.Sprintf("http://%s/debug/pprof/", profiling.Address))
+
+		if err := http.ListenAndServe(profiling.Address, handlers.ProfilingMux()); err != nil {
+			slog.Error("pprof server error", "error", err)
 		}
 	}()
 }
 
-func setupRouter(ctx context.Context) *gin.Engine {
-	router := gin.Default()
+// applyProfilingRates sets the block and mutex profile rates, which can change
+// while the server runs
+func applyProfilingRates(profiling config.ProfilingConfig) {
+	runtime.SetBlockProfileRate(profiling.BlockProfileRate)
+	runtime.SetMutexProfileFraction(profiling.MutexProfileFraction)
+}
+
+func setupRouter(ctx context.Context) (*gin.Engine, *handlers.Handle, *handlers.DynamicCORS) {
+	router := gin.New()
+	router.Use(gin.Recovery(), handlers.RequestLogger(), handlers.RequestMetrics())
 
-	configureCORS(router)
-	registerRoutes(router, ctx)
+	corsMiddleware := configureCORS(router)
+	handle := registerRoutes(router, ctx)
 
-	return router
+	if profiling := cfg.GetProfilingConfig(); profiling.Enabled && profiling.Mode == profilingModeRouter {
+		handlers.RegisterProfilingRoutes(router, handlers.AdminAuth(cfg.GetAdminConfig().Token))
+		slog.Info("Profiling mounted on the main router", "path", "/debug/pprof/")
+	}
+
+	return router, handle, corsMiddleware
 }
 
-func configureCORS(router *gin.Engine) {
-	corsConfig := cors.Config{
-		AllowOrigins:     cfg.GetCORSConfig().AllowedOrigins,
-		AllowMethods:     cfg.GetCORSConfig().AllowedMethods,
-		AllowHeaders:     cfg.GetCORSConfig().AllowedHeaders,
-		AllowCredentials: true,
-		ExposeHeaders:    []string{"Content-Length"},
-		MaxAge:           corsMaxAge,
+func configureCORS(router *gin.Engine) *handlers.DynamicCORS {
+	corsMiddleware, err := handlers.NewDynamicCORS(corsSettings(cfg))
+	if err != nil {
+		slog.Error("Invalid CORS configuration", "error", err)
+		os.Exit(1)
 	}
 
-	log.Printf("CORS configured with origins: %v", cfg.GetCORSConfig().AllowedOrigins)
-	router.Use(cors.New(corsConfig))
+	slog.Info("CORS configured", "origins", cfg.GetCORSConfig().AllowedOrigins)
+	router.Use(corsMiddleware.Middleware())
+	return corsMiddleware
 }
 
-func registerRoutes(router *gin.Engine, ctx context.Context) {
-	handle := handlers.NewHandle(ctx, cfg.GetStaticFolder(), cfg.GetBackupFolder())
+func registerRoutes(router *gin.Engine, ctx context.Context) *handlers.Handle {
+	handle := handlers.NewHandle(ctx, handlers.HandleOptions{
+		Workspaces:              workspaceConfigs(cfg),
+		RequireDeletionApproval: cfg.GetReviewConfig().RequireDeletionApproval,
+		CacheLimits:             cacheLimits(cfg),
+		MaxBackupCount:          cfg.GetBackupConfig().MaxCount,
+		Reviewers:               reviewers(cfg),
+		AdminToken:              cfg.GetAdminConfig().Token,
+	})
 	handle.RegisterRoutes(router)
+	return handle
 }
 
-func startServer(router *gin.Engine) {
-	serverAddress := cfg.GetServerAddress()
-	log.Printf("Server started at %s", serverAddress)
+func reviewers(c *config.Config) []handlers.Reviewer {
+	reviewers := make([]handlers.Reviewer, 0, len(c.GetReviewConfig().Reviewers))
+	for _, reviewer := range c.GetReviewConfig().Reviewers {
+		reviewers = append(reviewers, handlers.Reviewer{
+			Name:        strings.TrimSpace(reviewer.Name),
+			Token:       strings.TrimSpace(reviewer.Token),
+			Adjudicator: reviewer.Adjudicator,
+		})
+	}
+	return reviewers
+}
 
-	if err := router.Run(serverAddress); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+func workspaceConfigs(c *config.Config) []services.WorkspaceConfig {
+	workspaces := make([]services.WorkspaceConfig, 0, len(c.GetWorkspaces()))
+	for _, workspace := range c.GetWorkspaces() {
+		store, err := openStorage(workspace)
+		if err != nil {
+			slog.Error("Failed to open workspace storage", "workspace", workspace.Name, "error", err)
+			os.Exit(1)
+		}
+
+		workspaces = append(workspaces, services.WorkspaceConfig{
+			Name:      workspace.Name,
+			Storage:   store,
+			BackupDir: workspace.BackupFolder,
+		})
+	}
+	return workspaces
+}
+
+func openStorage(workspace config.WorkspaceConfig) (utils.Storage, error) {
+	if workspace.Storage.GetType() != config.StorageTypeS3 {
+		return utils.NewLocalStorage(workspace.RootFolder), nil
+	}
+
+	storage := workspace.Storage
+	return utils.NewS3Storage(utils.S3Options{
+		Endpoint:  storage.Endpoint,
+		Bucket:    storage.Bucket,
+		Prefix:    storage.Prefix,
+		Region:    storage.Region,
+		AccessKey: storage.AccessKey,
+		SecretKey: storage.SecretKey,
+		UseSSL:    !storage.DisableSSL,
+	})
+}
+
+// runServer serves until ctx is cancelled by SIGINT or SIGTERM, then shuts down
+func runServer(ctx context.Context, router *gin.Engine, handle *handlers.Handle) {
+	server := &http.Server{
+		Addr:    cfg.GetServerAddress(),
+		Handler: router,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server started", "address", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		slog.Error("Failed to start server", "error", err)
+		os.Exit(1)
+	case <-ctx.Done():
+		slog.Info("Shutdown signal received, draining requests", "timeout", cfg.GetShutdownTimeout())
 	}
+
+	shutdownServer(server, handle)
+}
+
+// shutdownServer cancels page compression so pending image requests return
+// quickly, waits for the other in-flight requests (deletions included), then
+// stops the watcher and background jobs and writes the final backups.
+func shutdownServer(server *http.Server, handle *handlers.Handle) {
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.GetShutdownTimeout())
+	defer cancel()
+
+	utils.CancelAllTasks()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Requests were still running at the shutdown deadline, the final backups may miss their changes", "error", err)
+
+		// The expired deadline would skip waiting for the analysis jobs, so the
+		// final backups get their own
+		var backupCancel context.CancelFunc
+		shutdownCtx, backupCancel = context.WithTimeout(context.Background(), finalBackupTimeout)
+		defer backupCancel()
+	}
+
+	handle.Shutdown(shutdownCtx)
+	slog.Info("Server stopped")
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"backend/config"
+	"backend/handlers"
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"log/slog"
+
+	"github.com/gin-contrib/cors"
+)
+
+// watchConfiguration reloads the configuration file when it changes and applies
+// the settings that are safe to change while serving. Settings such as the
+// image root or the server port are only reported as waiting for a restart.
+func watchConfiguration(handle *handlers.Handle, corsMiddleware *handlers.DynamicCORS) {
+	config.WatchConfig(cfg, func(next *config.Config) {
+		applyLiveConfiguration(next, handle, corsMiddleware)
+	})
+}
+
+func applyLiveConfiguration(next *config.Config, handle *handlers.Handle, corsMiddleware *handlers.DynamicCORS) {
+	if err := corsMiddleware.Update(corsSettings(next)); err != nil {
+		slog.Error("Failed to apply reloaded CORS settings", "error", err)
+	}
+
+	if err := utils.SetLogLevel(next.GetLoggingConfig().Level); err != nil {
+		slog.Error("Failed to apply reloaded log level", "error", err)
+	}
+
+	// Reviewers change together with require_deletion_approval, so turning
+	// approval on with new reviewers does not lock them out until a restart
+	handle.SetReviewers(reviewers(next))
+
+	limits := cacheLimits(next)
+	for _, ws := range handle.Workspaces() {
+		ws.JointServices.SetDeletionApprovalRequired(next.GetReviewConfig().RequireDeletionApproval)
+		ws.JointServices.SetMaxBackupCount(next.GetBackupConfig().MaxCount)
+		ws.UserServices.CacheManager.SetImageLimits(limits.MaxImagesPerJob, limits.MaxReviewImages)
+	}
+
+	utils.ConfigureCompression(compressionSettings(next))
+
+	// The profiling listener itself cannot be started or moved without a restart
+	if cfg.GetProfilingConfig().Enabled {
+		applyProfilingRates(next.GetProfilingConfig())
+	}
+}
+
+func corsSettings(c *config.Config) cors.Config {
+	return cors.Config{
+		AllowOrigins:     c.GetCORSConfig().AllowedOrigins,
+		AllowMethods:     c.GetCORSConfig().AllowedMethods,
+		AllowHeaders:     c.GetCORSConfig().AllowedHeaders,
+		AllowCredentials: true,
+		ExposeHeaders:    []string{"Content-Length"},
+		MaxAge:           corsMaxAge,
+	}
+}
+
+func cacheLimits(c *config.Config) models_verify_viewer.CacheLimits {
+	cacheConfig := c.GetCacheConfig()
+	return models_verify_viewer.CacheLimits{
+		Expiration:      cacheConfig.Expiration,
+		CleanupInterval: cacheConfig.CleanupInterval,
+		MaxImagesPerJob: cacheConfig.MaxImagesPerJob,
+		MaxReviewImages: cacheConfig.MaxReviewImages,
+	}
+}
+
+func compressionSettings(c *config.Config) utils.CompressionSettings {
+	performance := c.GetPerformanceConfig()
+	return utils.CompressionSettings{
+		MaxSlots:           performance.MaxTaskSlots,
+		PageRangeThreshold: performance.PageRangeThreshold,
+		MaxImageWidth:      performance.MaxImageWidth,
+		ImageQuality:       performance.ImageQuality,
+		ImageTimeout:       performance.ImageTimeout,
+	}
+}
@@ -0,0 +1,44 @@
+package models_verify_viewer
+
+import "strings"
+
+// Classes returns the distinct classes of the annotation in first-seen order
+func (annotation Annotation) Classes() []string {
+	seen := make(map[string]bool, len(annotation.Objects))
+	classes := make([]string, 0, len(annotation.Objects))
+	for _, object := range annotation.Objects {
+		if !seen[object.Class] {
+			seen[object.Class] = true
+			classes = append(classes, object.Class)
+		}
+	}
+	return classes
+}
+
+func (object AnnotationObject) Area() float64 {
+	return object.BBox[2] * object.BBox[3]
+}
+
+// LabelNameForImage returns the label file name that belongs to an image (foo.jpg -> foo.json)
+func LabelNameForImage(imageName string) string {
+	if dot := strings.LastIndex(imageName, "."); dot > 0 {
+		imageName = imageName[:dot]
+	}
+	return imageName + ".json"
+}
+
+// LabelIndex indexes the labels of the dataset. Build it once per dataset
+// rather than once per image.
+func (dataset Dataset) LabelIndex() LabelIndex {
+	index := make(LabelIndex, len(dataset.Label))
+	for _, label := range dataset.Label {
+		index[label.Name] = label
+	}
+	return index
+}
+
+// For returns the label that belongs to the image
+func (index LabelIndex) For(imageName string) (Label, bool) {
+	label, found := index[LabelNameForImage(imageName)]
+	return label, found
+}
@@ -0,0 +1,25 @@
+package models_verify_viewer
+
+// Annotation is the normalized content of one label JSON file
+type Annotation struct {
+	ImageWidth  int                `json:"image_width"`
+	ImageHeight int                `json:"image_height"`
+	Objects     []AnnotationObject `json:"objects"`
+}
+
+// LabelIndex finds the label of an image of one dataset by label file name
+type LabelIndex map[string]Label
+
+// AnnotationObject is one labelled region. BBox is [x, y, width, height] in pixels.
+type AnnotationObject struct {
+	Class         string     `json:"class"`
+	BBox          [4]float64 `json:"bbox"`
+	Confidence    float64    `json:"confidence"`
+	HasConfidence bool       `json:"has_confidence"`
+}
+
+func NewAnnotation() Annotation {
+	return Annotation{
+		Objects: make([]AnnotationObject, 0),
+	}
+}
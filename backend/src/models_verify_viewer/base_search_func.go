@@ -0,0 +1,89 @@
+package models_verify_viewer
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (query ImageQuery) Validate() error {
+	if query.Decision != "" && query.Decision != SearchDecisionPending && query.Decision != SearchDecisionUndecided {
+		return fmt.Errorf("decision must be %s or %s", SearchDecisionPending, SearchDecisionUndecided)
+	}
+	if query.MaxSize > 0 && query.MinSize > query.MaxSize {
+		return fmt.Errorf("min_size is greater than max_size")
+	}
+	if query.MaxWidth > 0 && query.MinWidth > query.MaxWidth {
+		return fmt.Errorf("min_width is greater than max_width")
+	}
+	if query.MaxHeight > 0 && query.MinHeight > query.MaxHeight {
+		return fmt.Errorf("min_height is greater than max_height")
+	}
+	return nil
+}
+
+// NeedsDimensions reports whether matching requires decoding image headers
+func (query ImageQuery) NeedsDimensions() bool {
+	return query.MinWidth > 0 || query.MaxWidth > 0 || query.MinHeight > 0 || query.MaxHeight > 0
+}
+
+// MatchesName checks the dataset and name substring (case insensitive) conditions
+func (query ImageQuery) MatchesName(datasetName, imageName string) bool {
+	if query.DatasetName != "" && query.DatasetName != datasetName {
+		return false
+	}
+	if query.NameContains != "" && !strings.Contains(strings.ToLower(imageName), strings.ToLower(query.NameContains)) {
+		return false
+	}
+	return true
+}
+
+// Matches checks every condition against a hit whose fields are filled in.
+// Width and height are only checked when the hit has them.
+func (query ImageQuery) Matches(hit ImageHit) bool {
+	if !query.MatchesName(hit.DatasetName, hit.ImageName) {
+		return false
+	}
+	if query.MinSize > 0 && hit.Size < query.MinSize {
+		return false
+	}
+	if query.MaxSize > 0 && hit.Size > query.MaxSize {
+		return false
+	}
+	if query.NeedsDimensions() && !query.matchesDimensions(hit.Width, hit.Height) {
+		return false
+	}
+	if !query.ModifiedAfter.IsZero() && hit.ModTime.Before(query.ModifiedAfter) {
+		return false
+	}
+	if !query.ModifiedBefore.IsZero() && hit.ModTime.After(query.ModifiedBefore) {
+		return false
+	}
+	if query.HasLabel != nil && *query.HasLabel != hit.HasLabel {
+		return false
+	}
+	if query.Decision != "" && query.Decision != hit.Decision {
+		return false
+	}
+	return true
+}
+
+func (query ImageQuery) matchesDimensions(width, height int) bool {
+	if query.MinWidth > 0 && width < query.MinWidth {
+		return false
+	}
+	if query.MaxWidth > 0 && width > query.MaxWidth {
+		return false
+	}
+	if query.MinHeight > 0 && height < query.MinHeight {
+		return false
+	}
+	if query.MaxHeight > 0 && height > query.MaxHeight {
+		return false
+	}
+	return true
+}
+
+// Image converts the hit back into the scanned image model used by pages
+func (hit ImageHit) Image() Image {
+	return NewImage(hit.ImageName, hit.ImagePath)
+}
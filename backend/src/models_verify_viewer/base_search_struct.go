@@ -0,0 +1,38 @@
+package models_verify_viewer
+
+import "time"
+
+const (
+	SearchDecisionPending   = "pending"
+	SearchDecisionUndecided = "undecided"
+)
+
+// ImageQuery filters the images of a job. Zero values match everything; sizes
+// are in bytes and dimensions in pixels.
+type ImageQuery struct {
+	NameContains   string    `json:"name" form:"name"`
+	DatasetName    string    `json:"dataset" form:"dataset"`
+	MinSize        int64     `json:"min_size" form:"minSize"`
+	MaxSize        int64     `json:"max_size" form:"maxSize"`
+	MinWidth       int       `json:"min_width" form:"minWidth"`
+	MaxWidth       int       `json:"max_width" form:"maxWidth"`
+	MinHeight      int       `json:"min_height" form:"minHeight"`
+	MaxHeight      int       `json:"max_height" form:"maxHeight"`
+	ModifiedAfter  time.Time `json:"modified_after" form:"modifiedAfter"`
+	ModifiedBefore time.Time `json:"modified_before" form:"modifiedBefore"`
+	HasLabel       *bool     `json:"has_label" form:"hasLabel"`
+	Decision       string    `json:"decision" form:"decision"`
+}
+
+// ImageHit is one image matched by an ImageQuery
+type ImageHit struct {
+	DatasetName string    `json:"dataset_name"`
+	ImageName   string    `json:"image_name"`
+	ImagePath   string    `json:"image_path"`
+	Size        int64     `json:"size"`
+	Width       int       `json:"width,omitempty"`
+	Height      int       `json:"height,omitempty"`
+	ModTime     time.Time `json:"mod_time"`
+	HasLabel    bool      `json:"has_label"`
+	Decision    string    `json:"decision"`
+}
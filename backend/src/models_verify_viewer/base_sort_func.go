@@ -0,0 +1,36 @@
+package models_verify_viewer
+
+import "fmt"
+
+func (sort ImageSort) Validate() error {
+	switch sort.Key {
+	case SortKeyNone, SortKeyName, SortKeyModTime, SortKeySize, SortKeyConfidence, SortKeyRandom:
+	default:
+		return fmt.Errorf("unknown sort key: %s", sort.Key)
+	}
+
+	switch sort.Order {
+	case "", SortOrderAsc, SortOrderDesc:
+	default:
+		return fmt.Errorf("sort order must be %s or %s", SortOrderAsc, SortOrderDesc)
+	}
+	return nil
+}
+
+func (sort ImageSort) Descending() bool {
+	return sort.Order == SortOrderDesc
+}
+
+// Normalize fills in the default order and drops fields the key does not use,
+// so equal sorts compare equal
+func (sort ImageSort) Normalize() ImageSort {
+	if sort.Key == SortKeyNone || sort.Key == SortKeyRandom {
+		sort.Order = ""
+	} else if sort.Order == "" {
+		sort.Order = SortOrderAsc
+	}
+	if sort.Key != SortKeyRandom {
+		sort.Seed = 0
+	}
+	return sort
+}
@@ -0,0 +1,21 @@
+package models_verify_viewer
+
+const (
+	SortKeyNone       = ""
+	SortKeyName       = "name"
+	SortKeyModTime    = "mtime"
+	SortKeySize       = "size"
+	SortKeyConfidence = "confidence"
+	SortKeyRandom     = "random"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// ImageSort orders the images of each dataset when pages are built. An empty key
+// keeps directory order; Seed is only used by the random key.
+type ImageSort struct {
+	Key   string `json:"key"`
+	Order string `json:"order,omitempty"`
+	Seed  int64  `json:"seed,omitempty"`
+}
@@ -0,0 +1,110 @@
+package models_verify_viewer
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Begin registers a running analysis; it fails when one is already running for the job
+func (tracker *AnalysisJobTracker) Begin(jobName string, total int, cancel context.CancelFunc) (AnalysisJobStatus, error) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	if status, found := tracker.statuses[jobName]; found && status.State == AnalysisJobStateRunning {
+		return status, ErrAnalysisJobRunning
+	}
+
+	status := AnalysisJobStatus{
+		JobName:   jobName,
+		Kind:      tracker.kind,
+		State:     AnalysisJobStateRunning,
+		Total:     total,
+		StartedAt: time.Now(),
+	}
+	tracker.statuses[jobName] = status
+	tracker.cancels[jobName] = cancel
+	return status, nil
+}
+
+// Progress counts one processed image of the running analysis
+func (tracker *AnalysisJobTracker) Progress(jobName string, reused, failed bool) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	status := tracker.statuses[jobName]
+	status.Processed++
+	if reused {
+		status.Reused++
+	}
+	if failed {
+		status.Failed++
+	}
+	tracker.statuses[jobName] = status
+}
+
+// End marks the analysis of a job as finished in the given state
+func (tracker *AnalysisJobTracker) End(jobName string, state string, jobErr error) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	now := time.Now()
+	status := tracker.statuses[jobName]
+	status.State = state
+	status.FinishedAt = &now
+	if jobErr != nil {
+		status.Error = jobErr.Error()
+	}
+	tracker.statuses[jobName] = status
+	delete(tracker.cancels, jobName)
+}
+
+// Cancel stops the running analysis of a job
+func (tracker *AnalysisJobTracker) Cancel(jobName string) error {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	cancel, found := tracker.cancels[jobName]
+	if !found {
+		return ErrAnalysisJobNotRunning
+	}
+	cancel()
+	return nil
+}
+
+// CancelAll stops every running analysis
+func (tracker *AnalysisJobTracker) CancelAll() {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	for _, cancel := range tracker.cancels {
+		cancel()
+	}
+}
+
+// Running returns the status of every analysis still in progress, by job name
+func (tracker *AnalysisJobTracker) Running() []AnalysisJobStatus {
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+
+	result := make([]AnalysisJobStatus, 0, len(tracker.cancels))
+	for jobName := range tracker.cancels {
+		result = append(result, tracker.statuses[jobName])
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].JobName < result[j].JobName
+	})
+	return result
+}
+
+// Status returns the progress of the latest analysis of a job
+func (tracker *AnalysisJobTracker) Status(jobName string) (AnalysisJobStatus, error) {
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+
+	status, found := tracker.statuses[jobName]
+	if !found {
+		return AnalysisJobStatus{}, ErrAnalysisJobNotFound
+	}
+	return status, nil
+}
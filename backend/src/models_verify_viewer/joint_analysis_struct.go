@@ -0,0 +1,52 @@
+package models_verify_viewer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	AnalysisJobStateRunning   = "running"
+	AnalysisJobStateCompleted = "completed"
+	AnalysisJobStateCancelled = "cancelled"
+	AnalysisJobStateFailed    = "failed"
+)
+
+var (
+	ErrAnalysisJobRunning    = errors.New("analysis is already running for this job")
+	ErrAnalysisJobNotRunning = errors.New("no analysis is running for this job")
+	ErrAnalysisJobNotFound   = errors.New("no analysis has been started for this job")
+)
+
+// AnalysisJobStatus is the progress of a background pass over every image of a job
+type AnalysisJobStatus struct {
+	JobName    string     `json:"job_name"`
+	Kind       string     `json:"kind"`
+	State      string     `json:"state"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Reused     int        `json:"reused"`
+	Failed     int        `json:"failed"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// AnalysisJobTracker keeps the status and cancel function of at most one running
+// analysis per job
+type AnalysisJobTracker struct {
+	kind     string
+	statuses map[string]AnalysisJobStatus
+	cancels  map[string]context.CancelFunc
+	mu       sync.RWMutex
+}
+
+func NewAnalysisJobTracker(kind string) *AnalysisJobTracker {
+	return &AnalysisJobTracker{
+		kind:     kind,
+		statuses: make(map[string]AnalysisJobStatus),
+		cancels:  make(map[string]context.CancelFunc),
+	}
+}
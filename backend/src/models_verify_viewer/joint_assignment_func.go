@@ -0,0 +1,196 @@
+package models_verify_viewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const AssignmentBoardFilename = "assignments.json"
+
+func datasetKey(jobName, datasetName string) string {
+	return fmt.Sprintf("%s|%s", jobName, datasetName)
+}
+
+func (lock DatasetLock) Expired(now time.Time) bool {
+	return !now.Before(lock.ExpiresAt)
+}
+
+// ReplaceJobAssignments drops every assignment of the job and stores the new ones
+func (board *AssignmentBoard) ReplaceJobAssignments(jobName string, assignments []DatasetAssignment) {
+	board.mu.Lock()
+	defer board.mu.Unlock()
+
+	for key, assignment := range board.assignments {
+		if assignment.JobName == jobName {
+			delete(board.assignments, key)
+		}
+	}
+	for _, assignment := range assignments {
+		board.assignments[datasetKey(assignment.JobName, assignment.DatasetName)] = assignment
+	}
+}
+
+// Assignments returns the assignments of a job sorted by dataset name
+func (board *AssignmentBoard) Assignments(jobName string) []DatasetAssignment {
+	board.mu.RLock()
+	defer board.mu.RUnlock()
+
+	result := make([]DatasetAssignment, 0)
+	for _, assignment := range board.assignments {
+		if assignment.JobName == jobName {
+			result = append(result, assignment)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].DatasetName < result[j].DatasetName
+	})
+	return result
+}
+
+// Claim takes or renews the lock on a dataset. A live lock held by someone
+// else is returned together with ErrDatasetLocked.
+func (board *AssignmentBoard) Claim(jobName, datasetName, holder string, ttl time.Duration) (DatasetLock, error) {
+	board.mu.Lock()
+	defer board.mu.Unlock()
+
+	now := time.Now()
+	key := datasetKey(jobName, datasetName)
+
+	if existing, found := board.locks[key]; found && !existing.Expired(now) && existing.Holder != holder {
+		return existing, ErrDatasetLocked
+	}
+
+	lock := DatasetLock{
+		JobName:     jobName,
+		DatasetName: datasetName,
+		Holder:      holder,
+		ClaimedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+	if existing, found := board.locks[key]; found && !existing.Expired(now) {
+		lock.ClaimedAt = existing.ClaimedAt
+	}
+
+	board.locks[key] = lock
+	return lock, nil
+}
+
+// Release drops the lock on a dataset. Without force only the holder may release it.
+func (board *AssignmentBoard) Release(jobName, datasetName, holder string, force bool) error {
+	board.mu.Lock()
+	defer board.mu.Unlock()
+
+	key := datasetKey(jobName, datasetName)
+	existing, found := board.locks[key]
+	if !found || existing.Expired(time.Now()) {
+		delete(board.locks, key)
+		return ErrDatasetLockNotFound
+	}
+	if existing.Holder != holder && !force {
+		return ErrDatasetLockNotHeld
+	}
+
+	delete(board.locks, key)
+	return nil
+}
+
+// Locks returns the live locks of a job (all jobs when jobName is empty),
+// purging expired ones on the way.
+func (board *AssignmentBoard) Locks(jobName string) []DatasetLock {
+	board.mu.Lock()
+	defer board.mu.Unlock()
+
+	now := time.Now()
+	result := make([]DatasetLock, 0)
+	for key, lock := range board.locks {
+		if lock.Expired(now) {
+			delete(board.locks, key)
+			continue
+		}
+		if jobName == "" || lock.JobName == jobName {
+			result = append(result, lock)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].JobName != result[j].JobName {
+			return result[i].JobName < result[j].JobName
+		}
+		return result[i].DatasetName < result[j].DatasetName
+	})
+	return result
+}
+
+func (board *AssignmentBoard) SaveToFile(dir string) error {
+	board.mu.RLock()
+	temp := board.snapshot()
+	board.mu.RUnlock()
+
+	jsonData, err := json.MarshalIndent(temp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal assignments: %v", err)
+	}
+
+	ensureBackupDirectoryExists(dir)
+	path := filepath.Join(dir, AssignmentBoardFilename)
+	if err := os.WriteFile(path, jsonData, backupFilePermissions); err != nil {
+		return fmt.Errorf("failed to write assignments: %v", err)
+	}
+
+	return nil
+}
+
+func (board *AssignmentBoard) LoadFromFile(dir string) error {
+	path := filepath.Join(dir, AssignmentBoardFilename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read assignments: %v", err)
+	}
+
+	var temp assignmentBoardFile
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return fmt.Errorf("failed to unmarshal assignments: %v", err)
+	}
+
+	board.mu.Lock()
+	defer board.mu.Unlock()
+
+	now := time.Now()
+	for _, assignment := range temp.Assignments {
+		board.assignments[datasetKey(assignment.JobName, assignment.DatasetName)] = assignment
+	}
+	for _, lock := range temp.Locks {
+		if !lock.Expired(now) {
+			board.locks[datasetKey(lock.JobName, lock.DatasetName)] = lock
+		}
+	}
+	return nil
+}
+
+type assignmentBoardFile struct {
+	Assignments []DatasetAssignment `json:"assignments"`
+	Locks       []DatasetLock       `json:"locks"`
+}
+
+// snapshot must be called with the read lock held
+func (board *AssignmentBoard) snapshot() assignmentBoardFile {
+	temp := assignmentBoardFile{
+		Assignments: make([]DatasetAssignment, 0, len(board.assignments)),
+		Locks:       make([]DatasetLock, 0, len(board.locks)),
+	}
+	for _, assignment := range board.assignments {
+		temp.Assignments = append(temp.Assignments, assignment)
+	}
+	for _, lock := range board.locks {
+		temp.Locks = append(temp.Locks, lock)
+	}
+	return temp
+}
@@ -0,0 +1,65 @@
+package models_verify_viewer
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDatasetLocked       = errors.New("dataset is locked by another reviewer")
+	ErrDatasetLockNotHeld  = errors.New("dataset lock is not held by this reviewer")
+	ErrDatasetLockNotFound = errors.New("dataset is not locked")
+)
+
+type AssignmentBoard struct {
+	assignments map[string]DatasetAssignment
+	locks       map[string]DatasetLock
+	mu          sync.RWMutex
+}
+
+type DatasetAssignment struct {
+	JobName     string    `json:"job_name"`
+	DatasetName string    `json:"dataset_name"`
+	Reviewer    string    `json:"reviewer"`
+	ImageCount  int       `json:"image_count"`
+	AssignedBy  string    `json:"assigned_by"`
+	AssignedAt  time.Time `json:"assigned_at"`
+}
+
+// DatasetLock is a soft lock: it only signals who is reviewing a dataset and
+// lapses on its own once ExpiresAt has passed.
+type DatasetLock struct {
+	JobName     string    `json:"job_name"`
+	DatasetName string    `json:"dataset_name"`
+	Holder      string    `json:"holder"`
+	ClaimedAt   time.Time `json:"claimed_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// DatasetStatus combines the assignment and the active lock of one dataset
+type DatasetStatus struct {
+	DatasetName   string     `json:"dataset_name"`
+	AssignedTo    string     `json:"assigned_to,omitempty"`
+	ImageCount    int        `json:"image_count"`
+	LockedBy      string     `json:"locked_by,omitempty"`
+	LockExpiresAt *time.Time `json:"lock_expires_at,omitempty"`
+}
+
+func NewAssignmentBoard() *AssignmentBoard {
+	return &AssignmentBoard{
+		assignments: make(map[string]DatasetAssignment),
+		locks:       make(map[string]DatasetLock),
+	}
+}
+
+func NewDatasetAssignment(jobName, datasetName, reviewer string, imageCount int, assignedBy string) DatasetAssignment {
+	return DatasetAssignment{
+		JobName:     jobName,
+		DatasetName: datasetName,
+		Reviewer:    reviewer,
+		ImageCount:  imageCount,
+		AssignedBy:  assignedBy,
+		AssignedAt:  time.Now(),
+	}
+}
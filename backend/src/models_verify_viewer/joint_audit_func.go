@@ -0,0 +1,400 @@
+package models_verify_viewer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	AuditLogFilename     = "audit_log.jsonl"
+	auditFilePermissions = 0644
+	auditMaxLineSize     = 1024 * 1024
+	auditMaxDetailSize   = 64 * 1024
+	auditTruncatedSuffix = " ... (truncated)"
+)
+
+// NewAuditLog opens the audit log in logDir and recovers the tail of the hash
+// chain. A torn final line left by a crash mid-append is cut off and the chain
+// continues from the last complete entry. An existing file that cannot be read
+// otherwise is moved aside and a new chain is started, so entries never
+// continue a chain they cannot link to. If the file cannot be moved either,
+// the returned log refuses every append.
+func NewAuditLog(logDir string) (*AuditLog, error) {
+	al := &AuditLog{path: filepath.Join(logDir, AuditLogFilename)}
+
+	ensureBackupDirectoryExists(logDir)
+	if err := al.loadTail(); err != nil {
+		return al, al.rotateUnreadable(err)
+	}
+	return al, nil
+}
+
+func (al *AuditLog) rotateUnreadable(cause error) error {
+	rotatedPath := fmt.Sprintf("%s.unreadable_%s", al.path, time.Now().UTC().Format("20060102_150405"))
+	if err := os.Rename(al.path, rotatedPath); err != nil {
+		al.broken = fmt.Errorf("audit log is unreadable (%v) and the file could not be moved aside: %v", cause, err)
+		return al.broken
+	}
+	return fmt.Errorf("audit log is unreadable, moved it to %s and started a new chain: %v", rotatedPath, cause)
+}
+
+func (al *AuditLog) Path() string {
+	return al.path
+}
+
+func (al *AuditLog) loadTail() error {
+	last, end, torn, err := al.readTail()
+	if err != nil {
+		return err
+	}
+
+	if torn {
+		tornPath, err := al.cutTail(end)
+		if err != nil {
+			return fmt.Errorf("audit log ends in a torn line that could not be cut off: %v", err)
+		}
+		slog.Warn("Cut a torn line off the end of the audit log", "offset", end, "moved_to", tornPath)
+	}
+
+	if last != nil {
+		al.lastSeq = last.Seq
+		al.lastHash = last.Hash
+	}
+	return nil
+}
+
+// readTail reads the log line by line and returns its last complete entry and
+// the offset just past it. A final line that has no newline, does not parse or
+// exceeds auditMaxLineSize is what a crash mid-append leaves behind and is
+// reported as torn; such a line anywhere else is an error.
+func (al *AuditLog) readTail() (*AuditEntry, int64, bool, error) {
+	file, err := os.Open(al.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, false, nil
+		}
+		return nil, 0, false, fmt.Errorf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, auditMaxLineSize)
+	var last *AuditEntry
+	var end int64
+	lineNumber := 0
+	for {
+		line, err := reader.ReadSlice('\n')
+		if err == io.EOF && len(line) == 0 {
+			return last, end, false, nil
+		}
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, 0, false, fmt.Errorf("failed to read audit log: %v", err)
+		}
+		lineNumber++
+
+		if err == nil {
+			trimmed := bytes.TrimSpace(line)
+			if len(trimmed) == 0 {
+				end += int64(len(line))
+				continue
+			}
+			var entry AuditEntry
+			if json.Unmarshal(trimmed, &entry) == nil {
+				last = &entry
+				end += int64(len(line))
+				continue
+			}
+		}
+
+		// The line is unreadable: it is torn only if nothing follows it
+		for err == bufio.ErrBufferFull {
+			_, err = reader.ReadSlice('\n')
+		}
+		if err != nil && err != io.EOF {
+			return nil, 0, false, fmt.Errorf("failed to read audit log: %v", err)
+		}
+		if _, err := reader.Peek(1); err == io.EOF {
+			return last, end, true, nil
+		}
+		return nil, 0, false, fmt.Errorf("failed to parse audit log line %d", lineNumber)
+	}
+}
+
+// cutTail moves everything after offset into a side file, keeping the torn
+// bytes for inspection, and truncates the log there
+func (al *AuditLog) cutTail(offset int64) (string, error) {
+	file, err := os.OpenFile(al.path, os.O_RDWR, auditFilePermissions)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	tornPath := fmt.Sprintf("%s.torn_%s", al.path, time.Now().UTC().Format("20060102_150405"))
+	torn, err := os.OpenFile(tornPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, auditFilePermissions)
+	if err != nil {
+		return "", err
+	}
+	_, copyErr := io.Copy(torn, file)
+	if err := errors.Join(copyErr, torn.Close()); err != nil {
+		return "", err
+	}
+
+	if err := file.Truncate(offset); err != nil {
+		return "", err
+	}
+	return tornPath, file.Sync()
+}
+
+// Append stamps the entry with a sequence number, timestamp and chained hash
+// and writes it as a single JSON line.
+func (al *AuditLog) Append(entry AuditEntry) (AuditEntry, error) {
+	appended, err := al.AppendAll([]AuditEntry{entry})
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	return appended[0], nil
+}
+
+// AppendAll stamps and chains the entries like Append and writes them with a
+// single write, so a change touching many images costs one sync. Details longer
+// than auditMaxDetailSize are truncated to keep every line readable.
+func (al *AuditLog) AppendAll(entries []AuditEntry) ([]AuditEntry, error) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	if al.broken != nil {
+		return nil, al.broken
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	seq, prevHash := al.lastSeq, al.lastHash
+	appended := make([]AuditEntry, 0, len(entries))
+	var data []byte
+	for _, entry := range entries {
+		seq++
+		entry.Seq = seq
+		entry.Timestamp = now
+		entry.PrevHash = prevHash
+		entry.Detail = truncateAuditDetail(entry.Detail)
+		entry.Hash = ""
+
+		hash, err := computeAuditHash(entry)
+		if err != nil {
+			return nil, err
+		}
+		entry.Hash = hash
+
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal audit entry: %v", err)
+		}
+		if len(line) >= auditMaxLineSize {
+			return nil, fmt.Errorf("audit entry of %d bytes exceeds the %d byte line limit", len(line), auditMaxLineSize)
+		}
+
+		data = append(append(data, line...), '\n')
+		appended = append(appended, entry)
+		prevHash = hash
+	}
+
+	if err := al.writeLines(data); err != nil {
+		return nil, err
+	}
+
+	al.lastSeq = seq
+	al.lastHash = prevHash
+	return appended, nil
+}
+
+func (al *AuditLog) writeLines(data []byte) error {
+	file, err := os.OpenFile(al.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, auditFilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write audit log: %v", err)
+	}
+	return file.Sync()
+}
+
+// truncateAuditDetail cuts detail to auditMaxDetailSize on a rune boundary
+func truncateAuditDetail(detail string) string {
+	if len(detail) <= auditMaxDetailSize {
+		return detail
+	}
+
+	cut := auditMaxDetailSize - len(auditTruncatedSuffix)
+	for cut > 0 && !utf8.RuneStart(detail[cut]) {
+		cut--
+	}
+	return detail[:cut] + auditTruncatedSuffix
+}
+
+// Ping checks that the audit log accepts appends and its file can be opened
+func (al *AuditLog) Ping() error {
+	al.mu.Lock()
+	broken := al.broken
+	al.mu.Unlock()
+	if broken != nil {
+		return broken
+	}
+
+	file, err := os.OpenFile(al.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, auditFilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+	return file.Close()
+}
+
+// Query returns the entries matching filter, newest first.
+func (al *AuditLog) Query(filter AuditFilter) ([]AuditEntry, error) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	entries := make([]AuditEntry, 0)
+	err := al.scan(func(entry AuditEntry) bool {
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reverseAuditEntries(entries)
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+// Verify walks the whole log and checks sequence numbers and the hash chain.
+// It returns the number of valid entries read before the first break.
+func (al *AuditLog) Verify() (int, error) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	count := 0
+	prevHash := ""
+	var verifyErr error
+
+	err := al.scan(func(entry AuditEntry) bool {
+		if entry.Seq != int64(count+1) {
+			verifyErr = fmt.Errorf("audit entry %d: expected seq %d", entry.Seq, count+1)
+			return false
+		}
+		if entry.PrevHash != prevHash {
+			verifyErr = fmt.Errorf("audit entry %d: previous hash mismatch", entry.Seq)
+			return false
+		}
+
+		stored := entry.Hash
+		entry.Hash = ""
+		hash, err := computeAuditHash(entry)
+		if err != nil || hash != stored {
+			verifyErr = fmt.Errorf("audit entry %d: hash mismatch", entry.Seq)
+			return false
+		}
+
+		prevHash = stored
+		count++
+		return true
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, verifyErr
+}
+
+// scan feeds every entry to fn in file order until fn returns false.
+// A missing file is treated as an empty log.
+func (al *AuditLog) scan(fn func(AuditEntry) bool) error {
+	file, err := os.Open(al.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), auditMaxLineSize)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("failed to parse audit log line %d: %v", lineNumber, err)
+		}
+		if !fn(entry) {
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read audit log: %v", err)
+	}
+	return nil
+}
+
+func computeAuditHash(entry AuditEntry) (string, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit entry: %v", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func reverseAuditEntries(entries []AuditEntry) {
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+}
+
+func (filter AuditFilter) Matches(entry AuditEntry) bool {
+	if filter.JobName != "" && entry.JobName != filter.JobName {
+		return false
+	}
+	if filter.User != "" && entry.User != filter.User {
+		return false
+	}
+	if filter.Action != "" && entry.Action != filter.Action {
+		return false
+	}
+	if !filter.Since.IsZero() && entry.Timestamp.Before(filter.Since) {
+		return false
+	}
+	if !filter.Until.IsZero() && entry.Timestamp.After(filter.Until) {
+		return false
+	}
+	return true
+}
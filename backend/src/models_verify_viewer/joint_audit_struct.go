@@ -0,0 +1,62 @@
+package models_verify_viewer
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	AuditActionReviewSaved       = "review_saved"
+	AuditActionReviewCleared     = "review_cleared"
+	AuditActionBackupRestored    = "backup_restored"
+	AuditActionImageDeleted      = "image_deleted"
+	AuditActionDecisionsImported = "decisions_imported"
+	AuditActionBulkFlagged       = "bulk_flagged"
+	AuditActionDuplicatesFlagged = "duplicates_flagged"
+
+	AuditActionDeletionRequested = "deletion_requested"
+	AuditActionDeletionApproved  = "deletion_approved"
+	AuditActionDeletionRejected  = "deletion_rejected"
+
+	AuditActionBlindReviewAdjudicated = "blind_review_adjudicated"
+)
+
+type AuditLog struct {
+	path     string
+	lastSeq  int64
+	lastHash string
+	broken   error // set when an unreadable log could not be moved aside
+	mu       sync.Mutex
+}
+
+// AuditEntry is one line of the audit log. Hash covers every other field,
+// including PrevHash, so editing or dropping a line breaks the chain.
+type AuditEntry struct {
+	Seq         int64     `json:"seq"`
+	Timestamp   time.Time `json:"timestamp"`
+	User        string    `json:"user"`
+	Action      string    `json:"action"`
+	JobName     string    `json:"job_name,omitempty"`
+	DatasetName string    `json:"dataset_name,omitempty"`
+	ImageName   string    `json:"image_name,omitempty"`
+	ImagePath   string    `json:"image_path,omitempty"`
+	Detail      string    `json:"detail,omitempty"`
+	PrevHash    string    `json:"prev_hash"`
+	Hash        string    `json:"hash"`
+}
+
+type AuditFilter struct {
+	JobName string
+	User    string
+	Action  string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
+
+func NewAuditEntry(user, action string) AuditEntry {
+	return AuditEntry{
+		User:   user,
+		Action: action,
+	}
+}
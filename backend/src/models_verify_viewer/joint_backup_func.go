@@ -3,7 +3,7 @@ package models_verify_viewer
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
@@ -12,7 +12,7 @@ import (
 )
 
 const (
-	MaxBackupCount        = 10
+	MaxBackupCount        = 10 // Default number of backups kept by rotation
 	backupFilenameFormat  = "pending_review_%s.json"
 	backupTimestampFormat = "20060102_150405"
 	backupFilePermissions = 0644
@@ -52,6 +52,7 @@ func (pr *PendingReview) RestoreFromBackup(backupDir string, filename string) er
 
 	pr.mu.Lock()
 	pr.items = temp.Items
+	pr.revision++
 	pr.mu.Unlock()
 
 	return nil
@@ -103,7 +104,7 @@ func (pr *PendingReview) writeBackupFile(backupPath string) error {
 func (pr *PendingReview) cleanupOldBackups(backupDir string) {
 	backupFiles, err := pr.getBackupFiles(backupDir)
 	if err != nil {
-		log.Printf("Warning: failed to cleanup old backups: %v", err)
+		slog.Warn("Failed to clean up old backups", "error", err)
 		return
 	}
 
@@ -139,12 +140,31 @@ func (pr *PendingReview) sortBackupFilesByDate(backupFiles []os.DirEntry) {
 	})
 }
 
+// SetMaxBackupCount sets how many backups rotation keeps; values below one
+// fall back to MaxBackupCount so rotation never deletes every backup
+func (pr *PendingReview) SetMaxBackupCount(count int) {
+	if count < 1 {
+		count = MaxBackupCount
+	}
+
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	pr.maxBackups = count
+}
+
+func (pr *PendingReview) maxBackupCount() int {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+	return pr.maxBackups
+}
+
 func (pr *PendingReview) removeExcessBackups(backupDir string, backupFiles []os.DirEntry) {
-	if len(backupFiles) <= MaxBackupCount {
+	maxBackups := pr.maxBackupCount()
+	if len(backupFiles) <= maxBackups {
 		return
 	}
 
-	for i := MaxBackupCount; i < len(backupFiles); i++ {
+	for i := maxBackups; i < len(backupFiles); i++ {
 		pr.removeBackupFile(backupDir, backupFiles[i])
 	}
 }
@@ -152,7 +172,7 @@ func (pr *PendingReview) removeExcessBackups(backupDir string, backupFiles []os.
 func (pr *PendingReview) removeBackupFile(backupDir string, file os.DirEntry) {
 	backupPath := filepath.Join(backupDir, file.Name())
 	if err := os.Remove(backupPath); err != nil {
-		log.Printf("Failed to remove old backup %s: %v", file.Name(), err)
+		slog.Warn("Failed to remove old backup", "backup", file.Name(), "error", err)
 	}
 }
 
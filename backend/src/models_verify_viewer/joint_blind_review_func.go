@@ -0,0 +1,376 @@
+package models_verify_viewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const BlindReviewFilename = "blind_reviews.json"
+
+func (session *BlindReviewSession) hasReviewer(reviewer string) bool {
+	for _, r := range session.Reviewers {
+		if r == reviewer {
+			return true
+		}
+	}
+	return false
+}
+
+// hasReviewerPair reports whether the session names exactly two distinct
+// reviewers, which every comparison between them relies on
+func (session *BlindReviewSession) hasReviewerPair() bool {
+	return len(session.Reviewers) == 2 && session.Reviewers[0] != session.Reviewers[1]
+}
+
+func (session *BlindReviewSession) sampleItem(key string) (PendingReviewItem, bool) {
+	for _, item := range session.Sample {
+		if item.Key() == key {
+			return item, true
+		}
+	}
+	return PendingReviewItem{}, false
+}
+
+func (session *BlindReviewSession) complete() bool {
+	for _, reviewer := range session.Reviewers {
+		if len(session.Decisions[reviewer]) < len(session.Sample) {
+			return false
+		}
+	}
+	return true
+}
+
+func (session *BlindReviewSession) summary() BlindReviewSummary {
+	progress := make(map[string]int, len(session.Reviewers))
+	for _, reviewer := range session.Reviewers {
+		progress[reviewer] = len(session.Decisions[reviewer])
+	}
+
+	return BlindReviewSummary{
+		ID:         session.ID,
+		JobName:    session.JobName,
+		Reviewers:  append([]string(nil), session.Reviewers...),
+		SampleSize: len(session.Sample),
+		Progress:   progress,
+		Complete:   session.complete(),
+		CreatedBy:  session.CreatedBy,
+		CreatedAt:  session.CreatedAt,
+	}
+}
+
+func (store *BlindReviewStore) Add(session *BlindReviewSession) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.sessions[session.ID] = session
+}
+
+// List returns session summaries, optionally limited to one job, newest first
+func (store *BlindReviewStore) List(jobName string) []BlindReviewSummary {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	result := make([]BlindReviewSummary, 0, len(store.sessions))
+	for _, session := range store.sessions {
+		if jobName == "" || session.JobName == jobName {
+			result = append(result, session.summary())
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+func (store *BlindReviewStore) Summary(id string) (BlindReviewSummary, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	session, found := store.sessions[id]
+	if !found {
+		return BlindReviewSummary{}, ErrBlindReviewNotFound
+	}
+	return session.summary(), nil
+}
+
+// ViewFor returns the sample together with the given reviewer's own decisions
+func (store *BlindReviewStore) ViewFor(id, reviewer string) (BlindReviewView, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	session, found := store.sessions[id]
+	if !found {
+		return BlindReviewView{}, ErrBlindReviewNotFound
+	}
+	if !session.hasReviewer(reviewer) {
+		return BlindReviewView{}, ErrNotBlindReviewer
+	}
+
+	own := session.Decisions[reviewer]
+	decisions := make([]ReviewDecision, 0, len(own))
+	for _, item := range session.Sample {
+		if decision, found := own[item.Key()]; found {
+			decisions = append(decisions, decision)
+		}
+	}
+
+	sample := make([]PendingReviewItem, len(session.Sample))
+	copy(sample, session.Sample)
+
+	return BlindReviewView{
+		ID:        session.ID,
+		JobName:   session.JobName,
+		Reviewer:  reviewer,
+		Sample:    sample,
+		Decisions: decisions,
+		Decided:   len(decisions),
+		Total:     len(sample),
+	}, nil
+}
+
+// RecordDecision stores or overwrites a reviewer's decision on a sampled image
+func (store *BlindReviewStore) RecordDecision(id string, decision ReviewDecision) error {
+	if !IsValidDecision(decision.Decision) {
+		return ErrInvalidDecision
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	session, found := store.sessions[id]
+	if !found {
+		return ErrBlindReviewNotFound
+	}
+	if !session.hasReviewer(decision.Reviewer) {
+		return ErrNotBlindReviewer
+	}
+
+	decision.JobName = session.JobName
+	if _, found := session.sampleItem(decision.Key()); !found {
+		return ErrImageNotInSample
+	}
+
+	decision.DecidedAt = time.Now()
+	session.Decisions[decision.Reviewer][decision.Key()] = decision
+	return nil
+}
+
+// Adjudicate records the final decision for an image the two reviewers disagreed on
+func (store *BlindReviewStore) Adjudicate(id string, decision ReviewDecision) (PendingReviewItem, error) {
+	if !IsValidDecision(decision.Decision) {
+		return PendingReviewItem{}, ErrInvalidDecision
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	session, found := store.sessions[id]
+	if !found {
+		return PendingReviewItem{}, ErrBlindReviewNotFound
+	}
+	if session.hasReviewer(decision.Reviewer) {
+		return PendingReviewItem{}, ErrOwnConflictAdjudicated
+	}
+
+	decision.JobName = session.JobName
+	key := decision.Key()
+	item, found := session.sampleItem(key)
+	if !found {
+		return PendingReviewItem{}, ErrImageNotInSample
+	}
+	if !session.inConflict(key) {
+		return PendingReviewItem{}, ErrBlindReviewNoConflict
+	}
+
+	decision.DecidedAt = time.Now()
+	session.Adjudications[key] = decision
+	return item, nil
+}
+
+func (session *BlindReviewSession) inConflict(key string) bool {
+	if !session.hasReviewerPair() {
+		return false
+	}
+	first, ok1 := session.Decisions[session.Reviewers[0]][key]
+	second, ok2 := session.Decisions[session.Reviewers[1]][key]
+	return ok1 && ok2 && first.Decision != second.Decision
+}
+
+// Agreement compares the two reviewers on every image both have decided
+func (store *BlindReviewStore) Agreement(id string) (AgreementReport, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	session, found := store.sessions[id]
+	if !found {
+		return AgreementReport{}, ErrBlindReviewNotFound
+	}
+	if !session.hasReviewerPair() {
+		return AgreementReport{}, ErrBlindReviewerCount
+	}
+
+	first := session.Decisions[session.Reviewers[0]]
+	second := session.Decisions[session.Reviewers[1]]
+
+	jobPairs := make([][2]string, 0, len(session.Sample))
+	datasetPairs := make(map[string][][2]string)
+	datasetOrder := make([]string, 0)
+	conflicts := make([]BlindReviewConflict, 0)
+
+	for _, item := range session.Sample {
+		key := item.Key()
+		a, ok1 := first[key]
+		b, ok2 := second[key]
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		pair := [2]string{a.Decision, b.Decision}
+		jobPairs = append(jobPairs, pair)
+		if _, seen := datasetPairs[item.DatasetName]; !seen {
+			datasetOrder = append(datasetOrder, item.DatasetName)
+		}
+		datasetPairs[item.DatasetName] = append(datasetPairs[item.DatasetName], pair)
+
+		if a.Decision != b.Decision {
+			conflicts = append(conflicts, BlindReviewConflict{
+				JobName:     item.JobName,
+				DatasetName: item.DatasetName,
+				ImageName:   item.ImageName,
+				ImagePath:   item.ImagePath,
+				Decisions: map[string]string{
+					a.Reviewer: a.Decision,
+					b.Reviewer: b.Decision,
+				},
+				Adjudication: session.Adjudications[key].Decision,
+			})
+		}
+	}
+
+	datasets := make([]AgreementStats, 0, len(datasetOrder))
+	for _, datasetName := range datasetOrder {
+		datasets = append(datasets, computeAgreement(datasetName, datasetPairs[datasetName]))
+	}
+
+	return AgreementReport{
+		SessionID:  session.ID,
+		JobName:    session.JobName,
+		Reviewers:  append([]string(nil), session.Reviewers...),
+		SampleSize: len(session.Sample),
+		Complete:   session.complete(),
+		Job:        computeAgreement(session.JobName, jobPairs),
+		Datasets:   datasets,
+		Conflicts:  conflicts,
+	}, nil
+}
+
+// computeAgreement returns percent agreement and Cohen's kappa for binary keep/drop pairs
+func computeAgreement(scope string, pairs [][2]string) AgreementStats {
+	stats := AgreementStats{Scope: scope, ComparedItems: len(pairs)}
+	if len(pairs) == 0 {
+		return stats
+	}
+
+	firstDrops, secondDrops := 0, 0
+	for _, pair := range pairs {
+		if pair[0] == pair[1] {
+			stats.Agreements++
+		}
+		if pair[0] == DecisionDrop {
+			firstDrops++
+		}
+		if pair[1] == DecisionDrop {
+			secondDrops++
+		}
+	}
+
+	n := float64(len(pairs))
+	observed := float64(stats.Agreements) / n
+	p1 := float64(firstDrops) / n
+	p2 := float64(secondDrops) / n
+	expected := p1*p2 + (1-p1)*(1-p2)
+
+	stats.PercentAgreement = observed * 100
+	if expected >= 1 {
+		// Both reviewers used a single category; kappa is undefined, report perfect agreement
+		stats.CohensKappa = 1
+	} else {
+		stats.CohensKappa = (observed - expected) / (1 - expected)
+	}
+	return stats
+}
+
+func (store *BlindReviewStore) SaveToFile(dir string) error {
+	store.mu.RLock()
+	sessions := make([]*BlindReviewSession, 0, len(store.sessions))
+	for _, session := range store.sessions {
+		sessions = append(sessions, session)
+	}
+	jsonData, err := json.MarshalIndent(sessions, "", "  ")
+	store.mu.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to marshal blind review sessions: %v", err)
+	}
+
+	ensureBackupDirectoryExists(dir)
+	path := filepath.Join(dir, BlindReviewFilename)
+	if err := os.WriteFile(path, jsonData, backupFilePermissions); err != nil {
+		return fmt.Errorf("failed to write blind review sessions: %v", err)
+	}
+
+	return nil
+}
+
+func (store *BlindReviewStore) LoadFromFile(dir string) error {
+	path := filepath.Join(dir, BlindReviewFilename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read blind review sessions: %v", err)
+	}
+
+	var sessions []*BlindReviewSession
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return fmt.Errorf("failed to unmarshal blind review sessions: %v", err)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	skipped := make([]string, 0)
+	for _, session := range sessions {
+		if session == nil {
+			continue
+		}
+		if !session.hasReviewerPair() {
+			skipped = append(skipped, session.ID)
+			continue
+		}
+		if session.Decisions == nil {
+			session.Decisions = make(map[string]map[string]ReviewDecision)
+		}
+		for _, reviewer := range session.Reviewers {
+			if session.Decisions[reviewer] == nil {
+				session.Decisions[reviewer] = make(map[string]ReviewDecision)
+			}
+		}
+		if session.Adjudications == nil {
+			session.Adjudications = make(map[string]ReviewDecision)
+		}
+		store.sessions[session.ID] = session
+	}
+
+	if len(skipped) > 0 {
+		return fmt.Errorf("skipped blind review sessions without exactly two distinct reviewers: %s", strings.Join(skipped, ", "))
+	}
+	return nil
+}
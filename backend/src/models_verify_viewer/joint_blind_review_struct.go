@@ -0,0 +1,121 @@
+package models_verify_viewer
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBlindReviewNotFound    = errors.New("blind review session not found")
+	ErrNotBlindReviewer       = errors.New("user is not a reviewer of this blind review session")
+	ErrImageNotInSample       = errors.New("image is not part of the blind review sample")
+	ErrInvalidDecision        = errors.New("decision must be 'keep' or 'drop'")
+	ErrBlindReviewIncomplete  = errors.New("blind review session is not complete yet")
+	ErrBlindReviewNoConflict  = errors.New("image is not in conflict")
+	ErrBlindReviewerCount     = errors.New("blind review requires exactly two distinct reviewers")
+	ErrBlindReviewEmptySample = errors.New("blind review sample is empty")
+	ErrOwnConflictAdjudicated = errors.New("reviewers of a blind review session cannot adjudicate its conflicts")
+)
+
+type BlindReviewStore struct {
+	sessions map[string]*BlindReviewSession
+	mu       sync.RWMutex
+}
+
+// BlindReviewSession holds the shared sample and every reviewer's independent
+// decisions. Decisions are keyed by reviewer and then by item key.
+type BlindReviewSession struct {
+	ID            string                               `json:"session_id"`
+	JobName       string                               `json:"job_name"`
+	Reviewers     []string                             `json:"reviewers"`
+	Seed          int64                                `json:"seed"`
+	Sample        []PendingReviewItem                  `json:"sample"`
+	CreatedBy     string                               `json:"created_by"`
+	CreatedAt     time.Time                            `json:"created_at"`
+	Decisions     map[string]map[string]ReviewDecision `json:"decisions"`
+	Adjudications map[string]ReviewDecision            `json:"adjudications"`
+}
+
+// BlindReviewSummary describes a session without exposing any decisions
+type BlindReviewSummary struct {
+	ID         string         `json:"session_id"`
+	JobName    string         `json:"job_name"`
+	Reviewers  []string       `json:"reviewers"`
+	SampleSize int            `json:"sample_size"`
+	Progress   map[string]int `json:"progress"`
+	Complete   bool           `json:"complete"`
+	CreatedBy  string         `json:"created_by"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// BlindReviewView is what a single reviewer sees: the sample and their own decisions only
+type BlindReviewView struct {
+	ID        string              `json:"session_id"`
+	JobName   string              `json:"job_name"`
+	Reviewer  string              `json:"reviewer"`
+	Sample    []PendingReviewItem `json:"sample"`
+	Decisions []ReviewDecision    `json:"decisions"`
+	Decided   int                 `json:"decided"`
+	Total     int                 `json:"total"`
+}
+
+type AgreementStats struct {
+	Scope            string  `json:"scope"`
+	ComparedItems    int     `json:"compared_items"`
+	Agreements       int     `json:"agreements"`
+	PercentAgreement float64 `json:"percent_agreement"`
+	CohensKappa      float64 `json:"cohens_kappa"`
+}
+
+type BlindReviewConflict struct {
+	JobName      string            `json:"job_name"`
+	DatasetName  string            `json:"dataset_name"`
+	ImageName    string            `json:"image_name"`
+	ImagePath    string            `json:"image_path"`
+	Decisions    map[string]string `json:"decisions"`
+	Adjudication string            `json:"adjudication,omitempty"`
+}
+
+type AgreementReport struct {
+	SessionID  string                `json:"session_id"`
+	JobName    string                `json:"job_name"`
+	Reviewers  []string              `json:"reviewers"`
+	SampleSize int                   `json:"sample_size"`
+	Complete   bool                  `json:"complete"`
+	Job        AgreementStats        `json:"job"`
+	Datasets   []AgreementStats      `json:"datasets"`
+	Conflicts  []BlindReviewConflict `json:"conflicts"`
+}
+
+func NewBlindReviewStore() *BlindReviewStore {
+	return &BlindReviewStore{
+		sessions: make(map[string]*BlindReviewSession),
+	}
+}
+
+func NewBlindReviewSession(id, jobName string, reviewers []string, seed int64, sample []PendingReviewItem, createdBy string) (*BlindReviewSession, error) {
+	if len(reviewers) != 2 || reviewers[0] == reviewers[1] {
+		return nil, ErrBlindReviewerCount
+	}
+	if len(sample) == 0 {
+		return nil, ErrBlindReviewEmptySample
+	}
+
+	decisions := make(map[string]map[string]ReviewDecision, len(reviewers))
+	for _, reviewer := range reviewers {
+		decisions[reviewer] = make(map[string]ReviewDecision)
+	}
+
+	return &BlindReviewSession{
+		ID:            id,
+		JobName:       jobName,
+		Reviewers:     reviewers,
+		Seed:          seed,
+		Sample:        sample,
+		CreatedBy:     createdBy,
+		CreatedAt:     time.Now(),
+		Decisions:     decisions,
+		Adjudications: make(map[string]ReviewDecision),
+	}, nil
+}
@@ -0,0 +1,125 @@
+package models_verify_viewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const DeletionQueueFilename = "deletion_batches.json"
+
+func (dq *DeletionQueue) Add(batch DeletionBatch) {
+	dq.mu.Lock()
+	defer dq.mu.Unlock()
+
+	dq.batches = append(dq.batches, batch)
+}
+
+func (dq *DeletionQueue) Get(id string) (DeletionBatch, bool) {
+	dq.mu.RLock()
+	defer dq.mu.RUnlock()
+
+	for _, batch := range dq.batches {
+		if batch.ID == id {
+			return batch, true
+		}
+	}
+	return DeletionBatch{}, false
+}
+
+// List returns batches with the given status, or every batch when status is empty
+func (dq *DeletionQueue) List(status string) []DeletionBatch {
+	dq.mu.RLock()
+	defer dq.mu.RUnlock()
+
+	result := make([]DeletionBatch, 0, len(dq.batches))
+	for _, batch := range dq.batches {
+		if status == "" || batch.Status == status {
+			result = append(result, batch)
+		}
+	}
+	return result
+}
+
+// Decide moves a pending batch to approved or rejected. The decider must not
+// be the user who requested the deletion.
+func (dq *DeletionQueue) Decide(id, decidedBy string, approve bool, reason string) (DeletionBatch, error) {
+	dq.mu.Lock()
+	defer dq.mu.Unlock()
+
+	for i := range dq.batches {
+		batch := &dq.batches[i]
+		if batch.ID != id {
+			continue
+		}
+
+		if batch.Status != DeletionStatusPending {
+			return *batch, ErrDeletionBatchNotPending
+		}
+		if batch.RequestedBy == decidedBy {
+			return *batch, ErrDeletionSelfApproval
+		}
+
+		now := time.Now()
+		batch.DecidedBy = decidedBy
+		batch.DecidedAt = &now
+		batch.Reason = reason
+		if approve {
+			batch.Status = DeletionStatusApproved
+		} else {
+			batch.Status = DeletionStatusRejected
+		}
+		return *batch, nil
+	}
+
+	return DeletionBatch{}, ErrDeletionBatchNotFound
+}
+
+func (dq *DeletionQueue) Len() int {
+	dq.mu.RLock()
+	defer dq.mu.RUnlock()
+
+	return len(dq.batches)
+}
+
+func (dq *DeletionQueue) SaveToFile(dir string) error {
+	dq.mu.RLock()
+	jsonData, err := json.MarshalIndent(dq.batches, "", "  ")
+	dq.mu.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to marshal deletion batches: %v", err)
+	}
+
+	ensureBackupDirectoryExists(dir)
+	path := filepath.Join(dir, DeletionQueueFilename)
+	if err := os.WriteFile(path, jsonData, backupFilePermissions); err != nil {
+		return fmt.Errorf("failed to write deletion batches: %v", err)
+	}
+
+	return nil
+}
+
+func (dq *DeletionQueue) LoadFromFile(dir string) error {
+	path := filepath.Join(dir, DeletionQueueFilename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read deletion batches: %v", err)
+	}
+
+	var batches []DeletionBatch
+	if err := json.Unmarshal(data, &batches); err != nil {
+		return fmt.Errorf("failed to unmarshal deletion batches: %v", err)
+	}
+
+	dq.mu.Lock()
+	dq.batches = batches
+	dq.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package models_verify_viewer
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	DeletionStatusPending  = "pending"
+	DeletionStatusApproved = "approved"
+	DeletionStatusRejected = "rejected"
+)
+
+var (
+	ErrDeletionBatchNotFound   = errors.New("deletion batch not found")
+	ErrDeletionBatchNotPending = errors.New("deletion batch has already been decided")
+	ErrDeletionSelfApproval    = errors.New("deletion batch must be decided by a different user than the requester")
+)
+
+type DeletionQueue struct {
+	batches []DeletionBatch
+	mu      sync.RWMutex
+}
+
+type DeletionBatch struct {
+	ID          string              `json:"batch_id"`
+	Items       []PendingReviewItem `json:"batch_items"`
+	Status      string              `json:"batch_status"`
+	RequestedBy string              `json:"requested_by"`
+	RequestedAt time.Time           `json:"requested_at"`
+	DecidedBy   string              `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time          `json:"decided_at,omitempty"`
+	Reason      string              `json:"reason,omitempty"`
+}
+
+func NewDeletionQueue() *DeletionQueue {
+	return &DeletionQueue{
+		batches: make([]DeletionBatch, 0),
+	}
+}
+
+func NewDeletionBatch(id string, items []PendingReviewItem, requestedBy string) DeletionBatch {
+	return DeletionBatch{
+		ID:          id,
+		Items:       items,
+		Status:      DeletionStatusPending,
+		RequestedBy: requestedBy,
+		RequestedAt: time.Now(),
+	}
+}
@@ -0,0 +1,102 @@
+package models_verify_viewer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Compile validates the filter and prepares the name regex. It must be called before Matches.
+func (filter *ImageFilter) Compile() error {
+	if filter.NameGlob != "" {
+		if _, err := path.Match(filter.NameGlob, ""); err != nil {
+			return fmt.Errorf("invalid name_glob: %v", err)
+		}
+	}
+
+	if filter.NameRegex != "" {
+		nameRegex, err := regexp.Compile(filter.NameRegex)
+		if err != nil {
+			return fmt.Errorf("invalid name_regex: %v", err)
+		}
+		filter.nameRegex = nameRegex
+	}
+
+	if filter.MinConfidence != nil && filter.MaxConfidence != nil && *filter.MinConfidence > *filter.MaxConfidence {
+		return fmt.Errorf("min_confidence is greater than max_confidence")
+	}
+
+	for _, flag := range filter.QualityFlags {
+		if !IsValidQualityFlag(flag) {
+			return fmt.Errorf("invalid quality flag %q", flag)
+		}
+	}
+	return nil
+}
+
+// IsEmpty reports whether the filter would match every image of a job
+func (filter ImageFilter) IsEmpty() bool {
+	return filter.DatasetName == "" && filter.NameGlob == "" && filter.NameRegex == "" &&
+		!filter.NeedsAnnotation() && !filter.NeedsQuality()
+}
+
+// NeedsAnnotation reports whether matching requires the image's label file
+func (filter ImageFilter) NeedsAnnotation() bool {
+	return filter.LabelClass != "" || filter.MinConfidence != nil || filter.MaxConfidence != nil
+}
+
+// NeedsQuality reports whether matching requires the job's quality records
+func (filter ImageFilter) NeedsQuality() bool {
+	return len(filter.QualityFlags) > 0
+}
+
+// MatchesName checks the dataset and file name conditions
+func (filter ImageFilter) MatchesName(datasetName, imageName string) bool {
+	if filter.DatasetName != "" && filter.DatasetName != datasetName {
+		return false
+	}
+	if filter.NameGlob != "" {
+		if matched, _ := path.Match(filter.NameGlob, imageName); !matched {
+			return false
+		}
+	}
+	if filter.nameRegex != nil && !filter.nameRegex.MatchString(imageName) {
+		return false
+	}
+	return true
+}
+
+// MatchesAnnotation checks the label conditions: at least one object of the class
+// (any class when empty) whose confidence lies within the bounds.
+func (filter ImageFilter) MatchesAnnotation(annotation Annotation) bool {
+	if !filter.NeedsAnnotation() {
+		return true
+	}
+
+	for _, object := range annotation.Objects {
+		if filter.LabelClass != "" && !strings.EqualFold(object.Class, filter.LabelClass) {
+			continue
+		}
+		if filter.matchesConfidence(object) {
+			return true
+		}
+	}
+	return false
+}
+
+func (filter ImageFilter) matchesConfidence(object AnnotationObject) bool {
+	if filter.MinConfidence == nil && filter.MaxConfidence == nil {
+		return true
+	}
+	if !object.HasConfidence {
+		return false
+	}
+	if filter.MinConfidence != nil && object.Confidence < *filter.MinConfidence {
+		return false
+	}
+	if filter.MaxConfidence != nil && object.Confidence > *filter.MaxConfidence {
+		return false
+	}
+	return true
+}
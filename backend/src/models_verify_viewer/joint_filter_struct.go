@@ -0,0 +1,18 @@
+package models_verify_viewer
+
+import "regexp"
+
+// ImageFilter selects images of a job. Empty fields match everything; confidence
+// bounds apply to label objects of LabelClass (any class when empty). QualityFlags
+// match images raising any of the flags in the job's latest quality check.
+type ImageFilter struct {
+	DatasetName   string   `json:"dataset"`
+	NameGlob      string   `json:"name_glob"`
+	NameRegex     string   `json:"name_regex"`
+	LabelClass    string   `json:"label_class"`
+	MinConfidence *float64 `json:"min_confidence"`
+	MaxConfidence *float64 `json:"max_confidence"`
+	QualityFlags  []string `json:"quality_flags"`
+
+	nameRegex *regexp.Regexp
+}
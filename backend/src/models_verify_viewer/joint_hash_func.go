@@ -0,0 +1,252 @@
+package models_verify_viewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+const hashIndexDirectory = "hashes"
+
+func IsValidHashAlgorithm(algorithm string) bool {
+	return algorithm == HashAlgorithmAHash || algorithm == HashAlgorithmDHash || algorithm == HashAlgorithmPHash
+}
+
+func (hash ImageHash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%016x", uint64(hash)))
+}
+
+func (hash *ImageHash) UnmarshalJSON(data []byte) error {
+	var hex string
+	if err := json.Unmarshal(data, &hex); err != nil {
+		return err
+	}
+	value, err := strconv.ParseUint(hex, 16, 64)
+	if err != nil {
+		return fmt.Errorf("invalid image hash %q: %v", hex, err)
+	}
+	*hash = ImageHash(value)
+	return nil
+}
+
+func (record ImageHashRecord) Key() string {
+	return record.DatasetName + "|" + record.ImageName
+}
+
+func (record ImageHashRecord) Hash(algorithm string) ImageHash {
+	switch algorithm {
+	case HashAlgorithmAHash:
+		return record.AHash
+	case HashAlgorithmDHash:
+		return record.DHash
+	default:
+		return record.PHash
+	}
+}
+
+// SetRecords replaces the hash index of a job
+func (hs *HashStore) SetRecords(jobName string, records []ImageHashRecord) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	hs.indexes[jobName] = newHashIndex(records)
+}
+
+// Records returns the hash index of a job
+func (hs *HashStore) Records(jobName string) ([]ImageHashRecord, bool) {
+	hs.mu.RLock()
+	defer hs.mu.RUnlock()
+
+	index, found := hs.indexes[jobName]
+	if !found {
+		return nil, false
+	}
+	return index.sortedRecords(), true
+}
+
+// DuplicateClusters returns the duplicate clusters of a job. They are computed
+// once per hash index, algorithm and threshold; callers must not modify them.
+func (hs *HashStore) DuplicateClusters(jobName, algorithm string, threshold int) ([]DuplicateCluster, bool) {
+	cacheKey := fmt.Sprintf("%s|%d", algorithm, threshold)
+
+	hs.mu.RLock()
+	index, found := hs.indexes[jobName]
+	var records []ImageHashRecord
+	if found {
+		if clusters, cached := index.clusters[cacheKey]; cached {
+			hs.mu.RUnlock()
+			return clusters, true
+		}
+		records = index.sortedRecords()
+	}
+	hs.mu.RUnlock()
+
+	if !found {
+		return nil, false
+	}
+
+	// Computed outside the lock; a hash job replacing the index meanwhile only
+	// means this result is cached on the discarded index
+	clusters := FindDuplicateClusters(records, algorithm, threshold)
+
+	hs.mu.Lock()
+	index.clusters[cacheKey] = clusters
+	hs.mu.Unlock()
+	return clusters, true
+}
+
+func newHashIndex(records []ImageHashRecord) *hashIndex {
+	index := &hashIndex{
+		records:  make(map[string]ImageHashRecord, len(records)),
+		clusters: make(map[string][]DuplicateCluster),
+	}
+	for _, record := range records {
+		index.records[record.Key()] = record
+	}
+	return index
+}
+
+func (index *hashIndex) sortedRecords() []ImageHashRecord {
+	records := make([]ImageHashRecord, 0, len(index.records))
+	for _, record := range index.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Key() < records[j].Key()
+	})
+	return records
+}
+
+func hashIndexPath(dir, jobName string) string {
+	return filepath.Join(dir, hashIndexDirectory, filepath.Base(jobName)+".json")
+}
+
+func (hs *HashStore) SaveToFile(dir, jobName string) error {
+	records, found := hs.Records(jobName)
+	if !found {
+		return nil
+	}
+
+	jsonData, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal hashes: %v", err)
+	}
+
+	path := hashIndexPath(dir, jobName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create hash directory: %v", err)
+	}
+	if err := os.WriteFile(path, jsonData, backupFilePermissions); err != nil {
+		return fmt.Errorf("failed to write hashes: %v", err)
+	}
+
+	return nil
+}
+
+// LoadFromFile loads the persisted hash index of a job unless one is already in memory
+func (hs *HashStore) LoadFromFile(dir, jobName string) error {
+	if _, found := hs.Records(jobName); found {
+		return nil
+	}
+
+	data, err := os.ReadFile(hashIndexPath(dir, jobName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read hashes: %v", err)
+	}
+
+	var records []ImageHashRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("failed to unmarshal hashes: %v", err)
+	}
+
+	hs.mu.Lock()
+	if _, found := hs.indexes[jobName]; !found {
+		hs.indexes[jobName] = newHashIndex(records)
+	}
+	hs.mu.Unlock()
+
+	return nil
+}
+
+// FindDuplicateClusters groups images around the image to keep: the largest
+// image not yet in a cluster takes every other unclustered image within threshold
+// bits of it. Each redundant image is therefore close to the image kept in its
+// place, where transitive grouping would chain near matches into clusters of
+// images far apart. Records with a hashing error are ignored. Clusters of two or
+// more images are returned, largest first.
+func FindDuplicateClusters(records []ImageHashRecord, algorithm string, threshold int) []DuplicateCluster {
+	valid := make([]ImageHashRecord, 0, len(records))
+	for _, record := range records {
+		if record.Error == "" {
+			valid = append(valid, record)
+		}
+	}
+	sortBySizeDescending(valid)
+
+	clustered := make([]bool, len(valid))
+	clusters := make([]DuplicateCluster, 0)
+	for i := range valid {
+		if clustered[i] {
+			continue
+		}
+
+		keepHash := uint64(valid[i].Hash(algorithm))
+		group := []ImageHashRecord{valid[i]}
+		for j := i + 1; j < len(valid); j++ {
+			if !clustered[j] && bits.OnesCount64(keepHash^uint64(valid[j].Hash(algorithm))) <= threshold {
+				clustered[j] = true
+				group = append(group, valid[j])
+			}
+		}
+		if len(group) > 1 {
+			clusters = append(clusters, newDuplicateCluster(group, algorithm))
+		}
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Redundant) != len(clusters[j].Redundant) {
+			return len(clusters[i].Redundant) > len(clusters[j].Redundant)
+		}
+		return clusters[i].Keep.DatasetName+"|"+clusters[i].Keep.ImageName < clusters[j].Keep.DatasetName+"|"+clusters[j].Keep.ImageName
+	})
+	for i := range clusters {
+		clusters[i].ID = i + 1
+	}
+	return clusters
+}
+
+func sortBySizeDescending(records []ImageHashRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Size != records[j].Size {
+			return records[i].Size > records[j].Size
+		}
+		return records[i].Key() < records[j].Key()
+	})
+}
+
+// newDuplicateCluster keeps the first image of group and marks the rest redundant
+func newDuplicateCluster(group []ImageHashRecord, algorithm string) DuplicateCluster {
+	keep := group[0]
+	keepHash := uint64(keep.Hash(algorithm))
+	cluster := DuplicateCluster{
+		Algorithm: algorithm,
+		Keep:      DuplicateItem{DatasetName: keep.DatasetName, ImageName: keep.ImageName, Size: keep.Size},
+		Redundant: make([]DuplicateItem, 0, len(group)-1),
+	}
+	for _, record := range group[1:] {
+		cluster.Redundant = append(cluster.Redundant, DuplicateItem{
+			DatasetName: record.DatasetName,
+			ImageName:   record.ImageName,
+			Size:        record.Size,
+			Distance:    bits.OnesCount64(keepHash ^ uint64(record.Hash(algorithm))),
+		})
+	}
+	return cluster
+}
@@ -0,0 +1,75 @@
+package models_verify_viewer
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	HashAlgorithmAHash = "ahash"
+	HashAlgorithmDHash = "dhash"
+	HashAlgorithmPHash = "phash"
+)
+
+var (
+	ErrHashesNotComputed    = errors.New("perceptual hashes have not been computed for this job")
+	ErrInvalidHashAlgorithm = errors.New("hash algorithm must be ahash, dhash or phash")
+)
+
+// ImageHash is a 64-bit perceptual hash, serialized as 16 hex digits because
+// JSON numbers cannot hold every uint64
+type ImageHash uint64
+
+// ImageHashRecord holds the hashes of one image. Size and ModTime detect changed
+// files so unchanged images are not hashed again.
+type ImageHashRecord struct {
+	DatasetName string    `json:"dataset_name"`
+	ImageName   string    `json:"image_name"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"mod_time"`
+	AHash       ImageHash `json:"ahash"`
+	DHash       ImageHash `json:"dhash"`
+	PHash       ImageHash `json:"phash"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// DuplicateCluster groups images within the hash distance threshold of Keep, the
+// suggested image to keep: the largest file of the cluster.
+type DuplicateCluster struct {
+	ID        int             `json:"cluster_id"`
+	Algorithm string          `json:"algorithm"`
+	Keep      DuplicateItem   `json:"keep"`
+	Redundant []DuplicateItem `json:"redundant"`
+}
+
+type DuplicateItem struct {
+	DatasetName string `json:"dataset_name"`
+	ImageName   string `json:"image_name"`
+	Size        int64  `json:"size"`
+	// Distance is the hash distance to the kept image
+	Distance int `json:"distance"`
+}
+
+// HashStore keeps the hash index and the hash job status of every job
+type HashStore struct {
+	*AnalysisJobTracker
+
+	indexes map[string]*hashIndex
+	mu      sync.RWMutex
+}
+
+// hashIndex is the hashes of one job. Setting new records replaces the whole
+// index, which drops the duplicate clusters computed from the old one.
+type hashIndex struct {
+	records map[string]ImageHashRecord
+	// clusters caches FindDuplicateClusters by "algorithm|threshold"
+	clusters map[string][]DuplicateCluster
+}
+
+func NewHashStore() *HashStore {
+	return &HashStore{
+		AnalysisJobTracker: NewAnalysisJobTracker("hash"),
+		indexes:            make(map[string]*hashIndex),
+	}
+}
@@ -0,0 +1,51 @@
+package models_verify_viewer
+
+import "time"
+
+// NewHealthCheck builds the result of a probe that started at start
+func NewHealthCheck(name string, critical bool, start time.Time, err error) HealthCheck {
+	check := HealthCheck{
+		Name:       name,
+		Status:     HealthStatusOK,
+		Critical:   critical,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		check.Status = HealthStatusFailing
+		check.Error = err.Error()
+	}
+	return check
+}
+
+// NewHealthReport collects checks into a report whose status reflects readiness
+func NewHealthReport(checks []HealthCheck) HealthReport {
+	report := HealthReport{
+		Status:    HealthStatusOK,
+		Checks:    checks,
+		CheckedAt: time.Now(),
+	}
+	if !report.Ready() {
+		report.Status = HealthStatusFailing
+	}
+	return report
+}
+
+// Live reports whether every critical check passed
+func (report HealthReport) Live() bool {
+	for _, check := range report.Checks {
+		if check.Critical && check.Status != HealthStatusOK {
+			return false
+		}
+	}
+	return true
+}
+
+// Ready reports whether every check passed
+func (report HealthReport) Ready() bool {
+	for _, check := range report.Checks {
+		if check.Status != HealthStatusOK {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,25 @@
+package models_verify_viewer
+
+import "time"
+
+const (
+	HealthStatusOK      = "ok"
+	HealthStatusFailing = "failing"
+)
+
+// HealthCheck is the outcome of one dependency probe. A failing critical check
+// means the process itself is broken; other failures only make it unready.
+type HealthCheck struct {
+	Name       string `json:"name"`
+	Workspace  string `json:"workspace,omitempty"`
+	Status     string `json:"status"`
+	Critical   bool   `json:"critical"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type HealthReport struct {
+	Status    string        `json:"status"`
+	Checks    []HealthCheck `json:"checks"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
@@ -0,0 +1,287 @@
+package models_verify_viewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const ProgressFilename = "review_progress.json"
+
+func progressKey(jobName, datasetName, reviewer string) string {
+	return fmt.Sprintf("%s|%s|%s", jobName, datasetName, reviewer)
+}
+
+// entry must be called with the write lock held
+func (pt *ProgressTracker) entry(jobName, datasetName, reviewer string, now time.Time) *reviewerDatasetProgress {
+	key := progressKey(jobName, datasetName, reviewer)
+	progress, found := pt.entries[key]
+	if !found {
+		progress = newReviewerDatasetProgress(jobName, datasetName, reviewer, now)
+		pt.entries[key] = progress
+	}
+	return progress
+}
+
+// RecordPageView marks the images of a page as seen by the reviewer and credits
+// the time since the reviewer's previous view to the datasets viewed then. Seen
+// images are keyed by job, dataset and image name, so progress survives the
+// page layout changing with sort, search or sampling.
+func (pt *ProgressTracker) RecordPageView(jobName, reviewer string, page PageItem) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	now := time.Now()
+	pt.creditElapsedTime(reviewer, now)
+
+	datasetNames, images := page.ImagesByDataset()
+	imageCounts := make(map[string]int, len(datasetNames))
+	for _, datasetName := range datasetNames {
+		progress := pt.entry(jobName, datasetName, reviewer, now)
+		progress.pageViews++
+		for _, image := range images[datasetName] {
+			progress.imagesSeen[image.Name] = true
+		}
+		progress.lastSeen = now
+		imageCounts[datasetName] = len(images[datasetName])
+	}
+
+	pt.lastView[reviewer] = progressView{
+		jobName:     jobName,
+		imageCounts: imageCounts,
+		at:          now,
+	}
+}
+
+// creditElapsedTime must be called with the write lock held
+func (pt *ProgressTracker) creditElapsedTime(reviewer string, now time.Time) {
+	previous, found := pt.lastView[reviewer]
+	if !found {
+		return
+	}
+
+	elapsed := now.Sub(previous.at)
+	if elapsed <= 0 || elapsed > progressIdleCutoff {
+		return
+	}
+
+	// Packed pages mix datasets; split the time by their share of the page
+	total := 0
+	for _, count := range previous.imageCounts {
+		total += count
+	}
+	for datasetName, count := range previous.imageCounts {
+		progress := pt.entry(previous.jobName, datasetName, reviewer, now)
+		progress.timeSpent += elapsed * time.Duration(count) / time.Duration(total)
+	}
+}
+
+// RecordDecisions marks images as decided by the reviewer
+func (pt *ProgressTracker) RecordDecisions(reviewer string, items []PendingReviewItem) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	now := time.Now()
+	for _, item := range items {
+		progress := pt.entry(item.JobName, item.DatasetName, reviewer, now)
+		progress.imagesDecided[item.ImageName] = true
+		progress.imagesSeen[item.ImageName] = true
+		progress.lastSeen = now
+	}
+}
+
+// Report builds per-reviewer and per-dataset progress for a job. datasetSizes
+// maps every dataset of the job to its image count; filters may be empty.
+func (pt *ProgressTracker) Report(jobName string, datasetSizes map[string]int, datasetFilter, reviewerFilter string) ProgressReport {
+	pt.mu.RLock()
+	defer pt.mu.RUnlock()
+
+	report := ProgressReport{
+		JobName:   jobName,
+		Datasets:  make([]DatasetCoverage, 0, len(datasetSizes)),
+		Reviewers: make([]ReviewerProgress, 0),
+	}
+
+	seenByDataset := make(map[string]map[string]bool)
+	decidedByDataset := make(map[string]map[string]bool)
+	timeByDataset := make(map[string]time.Duration)
+	reviewersByDataset := make(map[string][]string)
+
+	for _, progress := range pt.entries {
+		if progress.jobName != jobName {
+			continue
+		}
+		if datasetFilter != "" && progress.datasetName != datasetFilter {
+			continue
+		}
+		if reviewerFilter != "" && progress.reviewer != reviewerFilter {
+			continue
+		}
+
+		report.Reviewers = append(report.Reviewers, progress.toReviewerProgress())
+
+		dataset := progress.datasetName
+		if seenByDataset[dataset] == nil {
+			seenByDataset[dataset] = make(map[string]bool)
+			decidedByDataset[dataset] = make(map[string]bool)
+		}
+		for imageName := range progress.imagesSeen {
+			seenByDataset[dataset][imageName] = true
+		}
+		for imageName := range progress.imagesDecided {
+			decidedByDataset[dataset][imageName] = true
+		}
+		timeByDataset[dataset] += progress.timeSpent
+		reviewersByDataset[dataset] = append(reviewersByDataset[dataset], progress.reviewer)
+	}
+
+	for datasetName, totalImages := range datasetSizes {
+		if datasetFilter != "" && datasetName != datasetFilter {
+			continue
+		}
+
+		seen := len(seenByDataset[datasetName])
+		reviewers := reviewersByDataset[datasetName]
+		if reviewers == nil {
+			reviewers = []string{}
+		}
+		sort.Strings(reviewers)
+
+		report.Datasets = append(report.Datasets, DatasetCoverage{
+			DatasetName:      datasetName,
+			TotalImages:      totalImages,
+			ImagesSeen:       seen,
+			ImagesDecided:    len(decidedByDataset[datasetName]),
+			CoveragePercent:  percentOf(seen, totalImages),
+			TimeSpentSeconds: timeByDataset[datasetName].Seconds(),
+			Reviewers:        reviewers,
+		})
+		report.TotalImages += totalImages
+		report.ImagesSeen += seen
+	}
+	report.CoveragePercent = percentOf(report.ImagesSeen, report.TotalImages)
+
+	sort.Slice(report.Datasets, func(i, j int) bool {
+		return report.Datasets[i].DatasetName < report.Datasets[j].DatasetName
+	})
+	sort.Slice(report.Reviewers, func(i, j int) bool {
+		if report.Reviewers[i].DatasetName != report.Reviewers[j].DatasetName {
+			return report.Reviewers[i].DatasetName < report.Reviewers[j].DatasetName
+		}
+		return report.Reviewers[i].Reviewer < report.Reviewers[j].Reviewer
+	})
+	return report
+}
+
+func (progress *reviewerDatasetProgress) toReviewerProgress() ReviewerProgress {
+	return ReviewerProgress{
+		JobName:          progress.jobName,
+		DatasetName:      progress.datasetName,
+		Reviewer:         progress.reviewer,
+		PageViews:        progress.pageViews,
+		ImagesSeen:       len(progress.imagesSeen),
+		ImagesDecided:    len(progress.imagesDecided),
+		TimeSpentSeconds: progress.timeSpent.Seconds(),
+		FirstSeen:        progress.firstSeen,
+		LastSeen:         progress.lastSeen,
+	}
+}
+
+func percentOf(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
+
+type progressFileEntry struct {
+	JobName       string        `json:"job_name"`
+	DatasetName   string        `json:"dataset_name"`
+	Reviewer      string        `json:"reviewer"`
+	PageViews     int           `json:"page_views"`
+	ImagesSeen    []string      `json:"images_seen"`
+	ImagesDecided []string      `json:"images_decided"`
+	TimeSpent     time.Duration `json:"time_spent"`
+	FirstSeen     time.Time     `json:"first_seen"`
+	LastSeen      time.Time     `json:"last_seen"`
+}
+
+func (pt *ProgressTracker) SaveToFile(dir string) error {
+	pt.mu.RLock()
+	entries := make([]progressFileEntry, 0, len(pt.entries))
+	for _, progress := range pt.entries {
+		entries = append(entries, progress.toFileEntry())
+	}
+	pt.mu.RUnlock()
+
+	jsonData, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal review progress: %v", err)
+	}
+
+	ensureBackupDirectoryExists(dir)
+	path := filepath.Join(dir, ProgressFilename)
+	if err := os.WriteFile(path, jsonData, backupFilePermissions); err != nil {
+		return fmt.Errorf("failed to write review progress: %v", err)
+	}
+
+	return nil
+}
+
+func (pt *ProgressTracker) LoadFromFile(dir string) error {
+	path := filepath.Join(dir, ProgressFilename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read review progress: %v", err)
+	}
+
+	var entries []progressFileEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to unmarshal review progress: %v", err)
+	}
+
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	for _, fileEntry := range entries {
+		progress := newReviewerDatasetProgress(fileEntry.JobName, fileEntry.DatasetName, fileEntry.Reviewer, fileEntry.FirstSeen)
+		progress.pageViews = fileEntry.PageViews
+		for _, imageName := range fileEntry.ImagesSeen {
+			progress.imagesSeen[imageName] = true
+		}
+		for _, imageName := range fileEntry.ImagesDecided {
+			progress.imagesDecided[imageName] = true
+		}
+		progress.timeSpent = fileEntry.TimeSpent
+		progress.lastSeen = fileEntry.LastSeen
+		pt.entries[progressKey(fileEntry.JobName, fileEntry.DatasetName, fileEntry.Reviewer)] = progress
+	}
+	return nil
+}
+
+func (progress *reviewerDatasetProgress) toFileEntry() progressFileEntry {
+	fileEntry := progressFileEntry{
+		JobName:       progress.jobName,
+		DatasetName:   progress.datasetName,
+		Reviewer:      progress.reviewer,
+		PageViews:     progress.pageViews,
+		ImagesSeen:    make([]string, 0, len(progress.imagesSeen)),
+		ImagesDecided: make([]string, 0, len(progress.imagesDecided)),
+		TimeSpent:     progress.timeSpent,
+		FirstSeen:     progress.firstSeen,
+		LastSeen:      progress.lastSeen,
+	}
+	for imageName := range progress.imagesSeen {
+		fileEntry.ImagesSeen = append(fileEntry.ImagesSeen, imageName)
+	}
+	for imageName := range progress.imagesDecided {
+		fileEntry.ImagesDecided = append(fileEntry.ImagesDecided, imageName)
+	}
+	return fileEntry
+}
@@ -0,0 +1,88 @@
+package models_verify_viewer
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// progressIdleCutoff caps the time credited between two page views so a
+	// reviewer who walks away does not accumulate review time.
+	progressIdleCutoff = 5 * time.Minute
+)
+
+type ProgressTracker struct {
+	entries  map[string]*reviewerDatasetProgress
+	lastView map[string]progressView
+	mu       sync.RWMutex
+}
+
+type reviewerDatasetProgress struct {
+	jobName       string
+	datasetName   string
+	reviewer      string
+	pageViews     int
+	imagesSeen    map[string]bool
+	imagesDecided map[string]bool
+	timeSpent     time.Duration
+	firstSeen     time.Time
+	lastSeen      time.Time
+}
+
+type progressView struct {
+	jobName     string
+	imageCounts map[string]int
+	at          time.Time
+}
+
+// ReviewerProgress is the progress of one reviewer on one dataset
+type ReviewerProgress struct {
+	JobName          string    `json:"job_name"`
+	DatasetName      string    `json:"dataset_name"`
+	Reviewer         string    `json:"reviewer"`
+	PageViews        int       `json:"page_views"`
+	ImagesSeen       int       `json:"images_seen"`
+	ImagesDecided    int       `json:"images_decided"`
+	TimeSpentSeconds float64   `json:"time_spent_seconds"`
+	FirstSeen        time.Time `json:"first_seen"`
+	LastSeen         time.Time `json:"last_seen"`
+}
+
+// DatasetCoverage aggregates every reviewer's progress on one dataset
+type DatasetCoverage struct {
+	DatasetName      string   `json:"dataset_name"`
+	TotalImages      int      `json:"total_images"`
+	ImagesSeen       int      `json:"images_seen"`
+	ImagesDecided    int      `json:"images_decided"`
+	CoveragePercent  float64  `json:"coverage_percent"`
+	TimeSpentSeconds float64  `json:"time_spent_seconds"`
+	Reviewers        []string `json:"reviewers"`
+}
+
+type ProgressReport struct {
+	JobName         string             `json:"job_name"`
+	TotalImages     int                `json:"total_images"`
+	ImagesSeen      int                `json:"images_seen"`
+	CoveragePercent float64            `json:"coverage_percent"`
+	Datasets        []DatasetCoverage  `json:"datasets"`
+	Reviewers       []ReviewerProgress `json:"reviewers"`
+}
+
+func NewProgressTracker() *ProgressTracker {
+	return &ProgressTracker{
+		entries:  make(map[string]*reviewerDatasetProgress),
+		lastView: make(map[string]progressView),
+	}
+}
+
+func newReviewerDatasetProgress(jobName, datasetName, reviewer string, now time.Time) *reviewerDatasetProgress {
+	return &reviewerDatasetProgress{
+		jobName:       jobName,
+		datasetName:   datasetName,
+		reviewer:      reviewer,
+		imagesSeen:    make(map[string]bool),
+		imagesDecided: make(map[string]bool),
+		firstSeen:     now,
+		lastSeen:      now,
+	}
+}
@@ -0,0 +1,219 @@
+package models_verify_viewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const qualityDirectory = "quality"
+
+func DefaultQualityThresholds() QualityThresholds {
+	return QualityThresholds{
+		MinBlurScore:    100,
+		MinBrightness:   40,
+		MaxBrightness:   215,
+		MaxClippedRatio: 0.5,
+		MinShortSide:    64,
+	}
+}
+
+func IsValidQualityFlag(flag string) bool {
+	switch flag {
+	case QualityFlagCorrupt, QualityFlagBlurry, QualityFlagUnderexposed, QualityFlagOverexposed, QualityFlagLowResolution:
+		return true
+	}
+	return false
+}
+
+// Normalize fills zero fields with the defaults
+func (thresholds QualityThresholds) Normalize() QualityThresholds {
+	defaults := DefaultQualityThresholds()
+	if thresholds.MinBlurScore <= 0 {
+		thresholds.MinBlurScore = defaults.MinBlurScore
+	}
+	if thresholds.MinBrightness <= 0 {
+		thresholds.MinBrightness = defaults.MinBrightness
+	}
+	if thresholds.MaxBrightness <= 0 {
+		thresholds.MaxBrightness = defaults.MaxBrightness
+	}
+	if thresholds.MaxClippedRatio <= 0 {
+		thresholds.MaxClippedRatio = defaults.MaxClippedRatio
+	}
+	if thresholds.MinShortSide <= 0 {
+		thresholds.MinShortSide = defaults.MinShortSide
+	}
+	return thresholds
+}
+
+func (thresholds QualityThresholds) Validate() error {
+	if thresholds.MinBrightness >= thresholds.MaxBrightness {
+		return fmt.Errorf("min_brightness must be below max_brightness")
+	}
+	if thresholds.MaxBrightness > 255 {
+		return fmt.Errorf("max_brightness must be at most 255")
+	}
+	if thresholds.MaxClippedRatio > 1 {
+		return fmt.Errorf("max_clipped_ratio must be at most 1")
+	}
+	return nil
+}
+
+// Flags returns the quality flags a record raises under the thresholds
+func (thresholds QualityThresholds) Flags(record ImageQualityRecord) []string {
+	if record.Error != "" {
+		return []string{QualityFlagCorrupt}
+	}
+
+	flags := make([]string, 0)
+	if record.BlurScore < thresholds.MinBlurScore {
+		flags = append(flags, QualityFlagBlurry)
+	}
+	if record.MeanBrightness < thresholds.MinBrightness || record.DarkRatio > thresholds.MaxClippedRatio {
+		flags = append(flags, QualityFlagUnderexposed)
+	}
+	if record.MeanBrightness > thresholds.MaxBrightness || record.BrightRatio > thresholds.MaxClippedRatio {
+		flags = append(flags, QualityFlagOverexposed)
+	}
+	if min(record.Width, record.Height) < thresholds.MinShortSide {
+		flags = append(flags, QualityFlagLowResolution)
+	}
+	return flags
+}
+
+func (record ImageQualityRecord) Key() string {
+	return record.DatasetName + "|" + record.ImageName
+}
+
+// HasAnyFlag reports whether the record raises one of the flags
+func (record ImageQualityRecord) HasAnyFlag(flags []string) bool {
+	for _, flag := range flags {
+		for _, recorded := range record.Flags {
+			if recorded == flag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetRecords replaces the quality records of a job and the thresholds they were flagged with
+func (qs *QualityStore) SetRecords(jobName string, thresholds QualityThresholds, records []ImageQualityRecord) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	index := make(map[string]ImageQualityRecord, len(records))
+	for _, record := range records {
+		index[record.Key()] = record
+	}
+	qs.records[jobName] = index
+	qs.thresholds[jobName] = thresholds
+}
+
+// Records returns the quality records of a job sorted by dataset and image name
+func (qs *QualityStore) Records(jobName string) ([]ImageQualityRecord, QualityThresholds, bool) {
+	qs.mu.RLock()
+	defer qs.mu.RUnlock()
+
+	index, found := qs.records[jobName]
+	if !found {
+		return nil, QualityThresholds{}, false
+	}
+
+	records := make([]ImageQualityRecord, 0, len(index))
+	for _, record := range index {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Key() < records[j].Key()
+	})
+	return records, qs.thresholds[jobName], true
+}
+
+// Report counts the flags of a job and lists the images raising the flag, or
+// any flag when flag is empty
+func (qs *QualityStore) Report(jobName, flag string) (QualityReport, error) {
+	records, thresholds, found := qs.Records(jobName)
+	if !found {
+		return QualityReport{}, ErrQualityNotAnalyzed
+	}
+
+	report := QualityReport{
+		JobName:    jobName,
+		Thresholds: thresholds,
+		Analyzed:   len(records),
+		FlagCounts: make(map[string]int),
+		Images:     make([]ImageQualityRecord, 0),
+	}
+	for _, record := range records {
+		for _, recorded := range record.Flags {
+			report.FlagCounts[recorded]++
+		}
+		if (flag == "" && len(record.Flags) > 0) || (flag != "" && record.HasAnyFlag([]string{flag})) {
+			report.Images = append(report.Images, record)
+		}
+	}
+	return report, nil
+}
+
+type qualityFile struct {
+	Thresholds QualityThresholds    `json:"thresholds"`
+	Records    []ImageQualityRecord `json:"records"`
+}
+
+func qualityFilePath(dir, jobName string) string {
+	return filepath.Join(dir, qualityDirectory, filepath.Base(jobName)+".json")
+}
+
+func (qs *QualityStore) SaveToFile(dir, jobName string) error {
+	records, thresholds, found := qs.Records(jobName)
+	if !found {
+		return nil
+	}
+
+	jsonData, err := json.MarshalIndent(qualityFile{Thresholds: thresholds, Records: records}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal quality records: %v", err)
+	}
+
+	path := qualityFilePath(dir, jobName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create quality directory: %v", err)
+	}
+	if err := os.WriteFile(path, jsonData, backupFilePermissions); err != nil {
+		return fmt.Errorf("failed to write quality records: %v", err)
+	}
+
+	return nil
+}
+
+// LoadFromFile loads the persisted quality records of a job unless they are already in memory
+func (qs *QualityStore) LoadFromFile(dir, jobName string) error {
+	if _, _, found := qs.Records(jobName); found {
+		return nil
+	}
+
+	data, err := os.ReadFile(qualityFilePath(dir, jobName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read quality records: %v", err)
+	}
+
+	var file qualityFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to unmarshal quality records: %v", err)
+	}
+
+	qs.mu.RLock()
+	_, found := qs.records[jobName]
+	qs.mu.RUnlock()
+	if !found {
+		qs.SetRecords(jobName, file.Thresholds, file.Records)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package models_verify_viewer
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	QualityFlagCorrupt       = "corrupt"
+	QualityFlagBlurry        = "blurry"
+	QualityFlagUnderexposed  = "underexposed"
+	QualityFlagOverexposed   = "overexposed"
+	QualityFlagLowResolution = "low_resolution"
+)
+
+var (
+	ErrInvalidQualityFlag = errors.New("quality flag must be corrupt, blurry, underexposed, overexposed or low_resolution")
+	ErrQualityNotAnalyzed = errors.New("image quality has not been analyzed for this job")
+)
+
+// QualityThresholds decide which metrics raise a quality flag. Zero fields take
+// the defaults.
+type QualityThresholds struct {
+	// MinBlurScore is the Laplacian variance below which an image is blurry
+	MinBlurScore float64 `json:"min_blur_score"`
+	// MinBrightness and MaxBrightness bound the mean brightness (0-255)
+	MinBrightness float64 `json:"min_brightness"`
+	MaxBrightness float64 `json:"max_brightness"`
+	// MaxClippedRatio is the largest fraction of near-black or near-white pixels
+	MaxClippedRatio float64 `json:"max_clipped_ratio"`
+	// MinShortSide is the smallest acceptable width or height in pixels
+	MinShortSide int `json:"min_short_side"`
+}
+
+// ImageQualityRecord holds the quality metrics and flags of one image. Size and
+// ModTime detect changed files so unchanged images are not analyzed again.
+type ImageQualityRecord struct {
+	DatasetName    string    `json:"dataset_name"`
+	ImageName      string    `json:"image_name"`
+	Size           int64     `json:"size"`
+	ModTime        time.Time `json:"mod_time"`
+	Width          int       `json:"width"`
+	Height         int       `json:"height"`
+	BlurScore      float64   `json:"blur_score"`
+	MeanBrightness float64   `json:"mean_brightness"`
+	DarkRatio      float64   `json:"dark_ratio"`
+	BrightRatio    float64   `json:"bright_ratio"`
+	Flags          []string  `json:"flags"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// QualityReport summarizes the flagged images of a job
+type QualityReport struct {
+	JobName    string               `json:"job_name"`
+	Thresholds QualityThresholds    `json:"thresholds"`
+	Analyzed   int                  `json:"analyzed"`
+	FlagCounts map[string]int       `json:"flag_counts"`
+	Images     []ImageQualityRecord `json:"images"`
+}
+
+// QualityStore keeps the quality records and the quality job status of every job
+type QualityStore struct {
+	*AnalysisJobTracker
+
+	records    map[string]map[string]ImageQualityRecord
+	thresholds map[string]QualityThresholds
+	mu         sync.RWMutex
+}
+
+func NewQualityStore() *QualityStore {
+	return &QualityStore{
+		AnalysisJobTracker: NewAnalysisJobTracker("quality"),
+		records:            make(map[string]map[string]ImageQualityRecord),
+		thresholds:         make(map[string]QualityThresholds),
+	}
+}
@@ -1,15 +1,29 @@
 package models_verify_viewer
 
-import (
-	"fmt"
-	"os"
-)
-
 // Merge merges another PendingReview into this one, preserving items that exist in both
 func (pr *PendingReview) Merge(other *PendingReview) {
 	pr.mu.Lock()
 	defer pr.mu.Unlock()
 
+	pr.merge(other)
+}
+
+// MergeAt merges like Merge, but only when the list is still at revision. It
+// returns the new revision, or ErrPendingReviewStale with the current one so a
+// client saving a list loaded before another change cannot drop that change.
+func (pr *PendingReview) MergeAt(other *PendingReview, revision uint64) (uint64, error) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	if pr.revision != revision {
+		return pr.revision, ErrPendingReviewStale
+	}
+	pr.merge(other)
+	return pr.revision, nil
+}
+
+// merge must be called with the write lock held
+func (pr *PendingReview) merge(other *PendingReview) {
 	other.mu.RLock()
 	defer other.mu.RUnlock()
 
@@ -36,6 +50,7 @@ func (pr *PendingReview) Merge(other *PendingReview) {
 	}
 
 	pr.items = merged
+	pr.revision++
 }
 
 func (pr *PendingReview) Clear() {
@@ -43,6 +58,7 @@ func (pr *PendingReview) Clear() {
 	defer pr.mu.Unlock()
 
 	pr.items = pr.items[:0]
+	pr.revision++
 }
 
 func (pr *PendingReview) Items() []PendingReviewItem {
@@ -59,6 +75,7 @@ func (pr *PendingReview) Replace(items []PendingReviewItem) {
 	defer pr.mu.Unlock()
 
 	pr.items = items
+	pr.revision++
 }
 
 func (pr *PendingReview) Add(item PendingReviewItem) {
@@ -72,6 +89,7 @@ func (pr *PendingReview) Add(item PendingReviewItem) {
 	}
 
 	pr.items = append(pr.items, item)
+	pr.revision++
 }
 
 func (pr *PendingReview) Remove(item PendingReviewItem) {
@@ -85,9 +103,20 @@ func (pr *PendingReview) Remove(item PendingReviewItem) {
 			newItems = append(newItems, existing)
 		}
 	}
+	if len(newItems) != len(pr.items) {
+		pr.revision++
+	}
 	pr.items = newItems
 }
 
+// Revision returns the current revision of the list
+func (pr *PendingReview) Revision() uint64 {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+
+	return pr.revision
+}
+
 func (pr *PendingReview) Len() int {
 	pr.mu.RLock()
 	defer pr.mu.RUnlock()
@@ -95,10 +124,13 @@ func (pr *PendingReview) Len() int {
 	return len(pr.items)
 }
 
-func DeleteImageFile(imagePath string) error {
-	if _, err := os.Stat(imagePath); os.IsNotExist(err) {
-		return fmt.Errorf("image file not found: %s", imagePath)
+// TryLen is Len without blocking: it reports false when the lock is held by a
+// writer
+func (pr *PendingReview) TryLen() (int, bool) {
+	if !pr.mu.TryRLock() {
+		return 0, false
 	}
+	defer pr.mu.RUnlock()
 
-	return os.Remove(imagePath)
+	return len(pr.items), true
 }
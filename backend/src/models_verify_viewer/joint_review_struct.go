@@ -2,27 +2,44 @@ package models_verify_viewer
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"sync"
+	"time"
 )
 
+// ErrPendingReviewStale is returned when a save was based on an older revision
+// of the pending review list
+var ErrPendingReviewStale = errors.New("pending review list changed since it was loaded")
+
+// PendingReview is the list of images flagged for deletion. revision grows with
+// every change so clients can tell whether the list they loaded is still current.
 type PendingReview struct {
-	items []PendingReviewItem
-	mu    sync.RWMutex
+	items      []PendingReviewItem
+	revision   uint64
+	maxBackups int
+	mu         sync.RWMutex
 }
 
 type PendingReviewItem struct {
-	JobName     string `json:"item_job_name"`
-	DatasetName string `json:"item_dataset_name"`
-	ImageName   string `json:"item_image_name"`
-	ImagePath   string `json:"item_image_path"`
+	JobName     string     `json:"item_job_name"`
+	DatasetName string     `json:"item_dataset_name"`
+	ImageName   string     `json:"item_image_name"`
+	ImagePath   string     `json:"item_image_path"`
+	Reviewer    string     `json:"item_reviewer,omitempty"`
+	Note        string     `json:"item_note,omitempty"`
+	FlaggedAt   *time.Time `json:"item_flagged_at,omitempty"`
 }
 
 func NewPendingReview() *PendingReview {
 	return &PendingReview{
 		items: make([]PendingReviewItem, 0),
+		// Start from the clock so a revision loaded before a restart never
+		// matches again; milliseconds stay exact as JavaScript numbers
+		revision:   uint64(time.Now().UnixMilli()),
+		maxBackups: MaxBackupCount,
 	}
 }
 
@@ -60,9 +77,11 @@ func (pr *PendingReview) MarshalJSON() ([]byte, error) {
 
 	// Create a temporary struct with public field for JSON serialization
 	temp := struct {
-		Items []PendingReviewItem `json:"items"`
+		Items    []PendingReviewItem `json:"items"`
+		Revision uint64              `json:"revision"`
 	}{
-		Items: pr.items,
+		Items:    pr.items,
+		Revision: pr.revision,
 	}
 
 	return json.Marshal(temp)
@@ -70,6 +89,30 @@ func (pr *PendingReview) MarshalJSON() ([]byte, error) {
 
 func ensureBackupDirectoryExists(backupDir string) {
 	if err := os.MkdirAll(backupDir, 0755); err != nil {
-		log.Printf("Failed to create backup directory: %v", err)
+		slog.Error("Failed to create backup directory", "dir", backupDir, "error", err)
 	}
 }
+
+const (
+	DecisionKeep = "keep"
+	DecisionDrop = "drop"
+)
+
+// ReviewDecision is one reviewer's verdict on one image
+type ReviewDecision struct {
+	JobName     string    `json:"job_name"`
+	DatasetName string    `json:"dataset_name"`
+	ImageName   string    `json:"image_name"`
+	Decision    string    `json:"decision"`
+	Reviewer    string    `json:"reviewer"`
+	Note        string    `json:"note,omitempty"`
+	DecidedAt   time.Time `json:"decided_at"`
+}
+
+func IsValidDecision(decision string) bool {
+	return decision == DecisionKeep || decision == DecisionDrop
+}
+
+func (decision ReviewDecision) Key() string {
+	return fmt.Sprintf("%s|%s|%s", decision.JobName, decision.DatasetName, decision.ImageName)
+}
@@ -0,0 +1,190 @@
+package models_verify_viewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+const SampleStoreFilename = "samples.json"
+
+// z value of a two-sided 95% confidence interval
+const confidenceZ95 = 1.96
+
+func (spec SampleSpec) Validate() error {
+	switch spec.Method {
+	case SampleMethodNone:
+		return nil
+	case SampleMethodRandom:
+	case SampleMethodStratified:
+		if spec.Stratify != StratifyByDataset && spec.Stratify != StratifyByLabelClass {
+			return fmt.Errorf("stratify must be %s or %s", StratifyByDataset, StratifyByLabelClass)
+		}
+	default:
+		return fmt.Errorf("sample method must be %s or %s", SampleMethodRandom, SampleMethodStratified)
+	}
+
+	hasRate, hasCount := spec.Rate != 0, spec.Count != 0
+	if hasRate == hasCount {
+		return fmt.Errorf("sample needs exactly one of rate or count")
+	}
+	if hasRate && (spec.Rate < 0 || spec.Rate > 1) {
+		return fmt.Errorf("sample rate must be in (0, 1]")
+	}
+	if hasCount && spec.Count < 0 {
+		return fmt.Errorf("sample count must be positive")
+	}
+	return nil
+}
+
+func (spec SampleSpec) Enabled() bool {
+	return spec.Method != SampleMethodNone
+}
+
+// Normalize drops fields the method does not use, so equal specs compare equal
+func (spec SampleSpec) Normalize() SampleSpec {
+	if !spec.Enabled() {
+		return SampleSpec{}
+	}
+	if spec.Method == SampleMethodRandom {
+		spec.Stratify = ""
+	}
+	return spec
+}
+
+// ImageKeys returns "dataset|image" keys of the sampled images
+func (record SampleRecord) ImageKeys() map[string]bool {
+	keys := make(map[string]bool, len(record.Items))
+	for _, item := range record.Items {
+		keys[item.DatasetName+"|"+item.ImageName] = true
+	}
+	return keys
+}
+
+// Estimate extrapolates the flagged images of the sample to the population of every
+// stratum. flagged reports whether a sampled image is pending review.
+func (record SampleRecord) Estimate(flagged func(item SampleItem) bool) SampleEstimate {
+	flaggedByStratum := make(map[string]int)
+	for _, item := range record.Items {
+		if flagged(item) {
+			flaggedByStratum[item.Stratum]++
+		}
+	}
+
+	estimate := SampleEstimate{
+		SampleID:   record.ID,
+		JobName:    record.JobName,
+		Population: record.Population,
+		Strata:     make([]SampleStratumEstimate, 0, len(record.Strata)),
+	}
+	for _, stratum := range record.Strata {
+		stratumEstimate := SampleStratumEstimate{
+			Name:       stratum.Name,
+			Population: stratum.Population,
+			Sampled:    stratum.Sampled,
+			Flagged:    flaggedByStratum[stratum.Name],
+		}
+		if stratum.Sampled > 0 {
+			rate := float64(stratumEstimate.Flagged) / float64(stratum.Sampled)
+			stratumEstimate.FlaggedRate = rate
+			stratumEstimate.EstimatedFlagged = rate * float64(stratum.Population)
+			stratumEstimate.MarginOfError95 = marginOfError(rate, stratum.Sampled, stratum.Population)
+		}
+
+		estimate.Sampled += stratumEstimate.Sampled
+		estimate.Flagged += stratumEstimate.Flagged
+		estimate.EstimatedFlagged += stratumEstimate.EstimatedFlagged
+		estimate.Strata = append(estimate.Strata, stratumEstimate)
+	}
+	if estimate.Population > 0 {
+		estimate.EstimatedRate = estimate.EstimatedFlagged / float64(estimate.Population)
+	}
+	return estimate
+}
+
+// marginOfError is the 95% margin on the rate with finite population correction
+func marginOfError(rate float64, sampled, population int) float64 {
+	if sampled <= 1 || population <= 1 {
+		return 0
+	}
+	correction := math.Sqrt(float64(population-sampled) / float64(population-1))
+	return confidenceZ95 * math.Sqrt(rate*(1-rate)/float64(sampled)) * correction
+}
+
+func (ss *SampleStore) Add(record SampleRecord) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.records = append(ss.records, record)
+}
+
+func (ss *SampleStore) Get(id string) (SampleRecord, error) {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	for _, record := range ss.records {
+		if record.ID == id {
+			return record, nil
+		}
+	}
+	return SampleRecord{}, ErrSampleNotFound
+}
+
+// List returns the samples of a job (every job when empty), newest first, without their items
+func (ss *SampleStore) List(jobName string) []SampleRecord {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	records := make([]SampleRecord, 0)
+	for i := len(ss.records) - 1; i >= 0; i-- {
+		record := ss.records[i]
+		if jobName != "" && record.JobName != jobName {
+			continue
+		}
+		record.Items = nil
+		records = append(records, record)
+	}
+	return records
+}
+
+func (ss *SampleStore) SaveToFile(dir string) error {
+	ss.mu.RLock()
+	jsonData, err := json.MarshalIndent(ss.records, "", "  ")
+	ss.mu.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to marshal samples: %v", err)
+	}
+
+	ensureBackupDirectoryExists(dir)
+	path := filepath.Join(dir, SampleStoreFilename)
+	if err := os.WriteFile(path, jsonData, backupFilePermissions); err != nil {
+		return fmt.Errorf("failed to write samples: %v", err)
+	}
+
+	return nil
+}
+
+func (ss *SampleStore) LoadFromFile(dir string) error {
+	path := filepath.Join(dir, SampleStoreFilename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read samples: %v", err)
+	}
+
+	var records []SampleRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("failed to unmarshal samples: %v", err)
+	}
+
+	ss.mu.Lock()
+	ss.records = records
+	ss.mu.Unlock()
+
+	return nil
+}
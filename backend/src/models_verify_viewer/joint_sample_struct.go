@@ -0,0 +1,90 @@
+package models_verify_viewer
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	SampleMethodNone       = ""
+	SampleMethodRandom     = "random"
+	SampleMethodStratified = "stratified"
+
+	StratifyByDataset    = "dataset"
+	StratifyByLabelClass = "label_class"
+
+	// UnlabeledStratum holds images without a label object when stratifying by class
+	UnlabeledStratum = "unlabeled"
+)
+
+var ErrSampleNotFound = errors.New("sample not found")
+
+// SampleSpec defines a spot-check sample: either Rate (0-1] of every stratum or
+// Count images in total, allocated to strata in proportion to their size.
+type SampleSpec struct {
+	Method   string  `json:"method"`
+	Stratify string  `json:"stratify,omitempty"`
+	Rate     float64 `json:"rate,omitempty"`
+	Count    int     `json:"count,omitempty"`
+	Seed     int64   `json:"seed,omitempty"`
+}
+
+// SampleStratum reports the population and sample size of one stratum
+type SampleStratum struct {
+	Name       string `json:"name"`
+	Population int    `json:"population"`
+	Sampled    int    `json:"sampled"`
+}
+
+// SampleRecord is the persisted definition of a drawn sample, kept so review
+// results on the sample can be extrapolated to the whole job
+type SampleRecord struct {
+	ID         string          `json:"sample_id"`
+	JobName    string          `json:"job_name"`
+	Spec       SampleSpec      `json:"spec"`
+	Population int             `json:"population"`
+	Strata     []SampleStratum `json:"strata"`
+	Items      []SampleItem    `json:"items,omitempty"`
+	CreatedBy  string          `json:"created_by"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type SampleItem struct {
+	DatasetName string `json:"dataset_name"`
+	ImageName   string `json:"image_name"`
+	Stratum     string `json:"stratum"`
+}
+
+// SampleStratumEstimate extrapolates the flagged share of a stratum's sample to its population
+type SampleStratumEstimate struct {
+	Name             string  `json:"name"`
+	Population       int     `json:"population"`
+	Sampled          int     `json:"sampled"`
+	Flagged          int     `json:"flagged"`
+	FlaggedRate      float64 `json:"flagged_rate"`
+	EstimatedFlagged float64 `json:"estimated_flagged"`
+	MarginOfError95  float64 `json:"margin_of_error_95"`
+}
+
+type SampleEstimate struct {
+	SampleID         string                  `json:"sample_id"`
+	JobName          string                  `json:"job_name"`
+	Population       int                     `json:"population"`
+	Sampled          int                     `json:"sampled"`
+	Flagged          int                     `json:"flagged"`
+	EstimatedFlagged float64                 `json:"estimated_flagged"`
+	EstimatedRate    float64                 `json:"estimated_rate"`
+	Strata           []SampleStratumEstimate `json:"strata"`
+}
+
+type SampleStore struct {
+	records []SampleRecord
+	mu      sync.RWMutex
+}
+
+func NewSampleStore() *SampleStore {
+	return &SampleStore{
+		records: make([]SampleRecord, 0),
+	}
+}
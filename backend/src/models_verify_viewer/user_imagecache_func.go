@@ -1,13 +1,41 @@
 package models_verify_viewer
 
 import (
-	"log"
+	"log/slog"
+	"sort"
 
 	"github.com/patrickmn/go-cache"
 )
 
+// Limits returns the limits applied to newly created cache entries
+func (cm *CacheManager) Limits() CacheLimits {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.limits
+}
+
+// SetImageLimits changes the per-entry image limits of both stores. Existing
+// entries are trimmed to the new limit; expiration cannot change after creation.
+func (cm *CacheManager) SetImageLimits(maxImagesPerJob, maxReviewImages int) {
+	cm.mu.Lock()
+	cm.limits.MaxImagesPerJob = maxImagesPerJob
+	cm.limits.MaxReviewImages = maxReviewImages
+	cm.mu.Unlock()
+
+	resizeStoreEntries(cm.ImageCacheStore, maxImagesPerJob)
+	resizeStoreEntries(cm.ReviewCacheStore, maxReviewImages)
+}
+
+func resizeStoreEntries(store *cache.Cache, maxImages int) {
+	for _, item := range store.Items() {
+		if cacheData, ok := item.Object.(*Base64ImageCache); ok {
+			cacheData.SetMaxImages(maxImages)
+		}
+	}
+}
+
 func (cm *CacheManager) SetImageCacheStore(jobName string) {
-	cacheData := NewBase64ImageCache(jobName)
+	cacheData := NewBase64ImageCacheWithLimit(jobName, cm.Limits().MaxImagesPerJob)
 	cm.ImageCacheStore.Set(jobName, cacheData, cache.DefaultExpiration)
 }
 
@@ -35,26 +63,30 @@ func (cm *CacheManager) ExistsImageCacheStore(jobName string) bool {
 
 func (cm *CacheManager) ClearImageCacheStore(jobName string) {
 	cm.ImageCacheStore.Delete(jobName)
-	log.Printf("Cleared cache for job: %s", jobName)
+	slog.Debug("Cleared image cache", "job", jobName)
 }
 
 func (cm *CacheManager) CheckImageCacheStoreStats() {
 	itemCount := cm.ImageCacheStore.ItemCount()
-	log.Printf("[Memory] Total cached jobs: %d", itemCount)
+	slog.Debug("Image cache stats", "jobs", itemCount)
 
 	// Log details for each cached job
 	items := cm.ImageCacheStore.Items()
 	for jobName, item := range items {
 		if cacheData, ok := item.Object.(*Base64ImageCache); ok {
-			log.Printf("[Memory] Job: %s - Cached images: %d/%d",
-				jobName, cacheData.Len(), cacheData.maxImages)
+			slog.Debug("Image cache job stats", "job", jobName, "images", cacheData.Len(), "max_images", cacheData.maxImages)
 		}
 	}
 }
 
+// ImageCacheBytes returns the total size of the base64 images cached for all jobs
+func (cm *CacheManager) ImageCacheBytes() int {
+	return storeBytes(cm.ImageCacheStore)
+}
+
 // Review cache management functions
 func (cm *CacheManager) SetReviewImageCacheStore(cacheKey string) {
-	cacheData := NewBase64ImageCacheWithLimit(cacheKey, maxImagesPerJobReview)
+	cacheData := NewBase64ImageCacheWithLimit(cacheKey, cm.Limits().MaxReviewImages)
 	cm.ReviewCacheStore.Set(cacheKey, cacheData, cache.DefaultExpiration)
 }
 
@@ -78,23 +110,71 @@ func (cm *CacheManager) ExistsReviewImageCacheStore(cacheKey string) bool {
 
 func (cm *CacheManager) ClearReviewImageCacheStore(cacheKey string) {
 	cm.ReviewCacheStore.Delete(cacheKey)
-	log.Printf("Cleared review cache for key: %s", cacheKey)
+	slog.Debug("Cleared review cache", "key", cacheKey)
 }
 
 func (cm *CacheManager) CheckReviewCacheStoreStats() {
 	itemCount := cm.ReviewCacheStore.ItemCount()
-	log.Printf("[Memory] Total review cache keys: %d", itemCount)
+	slog.Debug("Review cache stats", "keys", itemCount)
 
 	// Log details for each review cache
 	items := cm.ReviewCacheStore.Items()
 	for cacheKey, item := range items {
 		if cacheData, ok := item.Object.(*Base64ImageCache); ok {
-			log.Printf("[Memory] Review Cache: %s - Cached images: %d/%d",
-				cacheKey, cacheData.Len(), cacheData.maxImages)
+			slog.Debug("Review cache key stats", "key", cacheKey, "images", cacheData.Len(), "max_images", cacheData.maxImages)
 		}
 	}
 }
 
+// ReviewCacheBytes returns the total size of the base64 images cached for review
+func (cm *CacheManager) ReviewCacheBytes() int {
+	return storeBytes(cm.ReviewCacheStore)
+}
+
+// ImageCacheStats summarizes the image cache of every job
+func (cm *CacheManager) ImageCacheStats() CacheStoreStats {
+	return storeStats(cm.ImageCacheStore)
+}
+
+// ReviewCacheStats summarizes the review cache
+func (cm *CacheManager) ReviewCacheStats() CacheStoreStats {
+	return storeStats(cm.ReviewCacheStore)
+}
+
+func storeStats(store *cache.Cache) CacheStoreStats {
+	stats := CacheStoreStats{Entries: make([]CacheEntryStats, 0)}
+	for key, item := range store.Items() {
+		cacheData, ok := item.Object.(*Base64ImageCache)
+		if !ok {
+			continue
+		}
+		entry := CacheEntryStats{
+			Key:       key,
+			Images:    cacheData.Len(),
+			MaxImages: cacheData.MaxImages(),
+			Bytes:     cacheData.Bytes(),
+		}
+		stats.Entries = append(stats.Entries, entry)
+		stats.Images += entry.Images
+		stats.Bytes += entry.Bytes
+	}
+
+	sort.Slice(stats.Entries, func(i, j int) bool {
+		return stats.Entries[i].Key < stats.Entries[j].Key
+	})
+	return stats
+}
+
+func storeBytes(store *cache.Cache) int {
+	total := 0
+	for _, item := range store.Items() {
+		if cacheData, ok := item.Object.(*Base64ImageCache); ok {
+			total += cacheData.Bytes()
+		}
+	}
+	return total
+}
+
 // JobName returns the job name
 func (cache *Base64ImageCache) JobName() string {
 	cache.mu.RLock()
@@ -109,6 +189,35 @@ func (cache *Base64ImageCache) Len() int {
 	return len(cache.imageMap)
 }
 
+// Bytes returns the total size of the cached base64 images
+func (cache *Base64ImageCache) Bytes() int {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+
+	total := 0
+	for _, base64Image := range cache.imageMap {
+		total += len(base64Image)
+	}
+	return total
+}
+
+// MaxImages returns the image limit
+func (cache *Base64ImageCache) MaxImages() int {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+	return cache.maxImages
+}
+
+// SetMaxImages changes the image limit, evicting the least recently used
+// images when the cache is now over it
+func (cache *Base64ImageCache) SetMaxImages(maxImages int) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.maxImages = maxImages
+	cache.cleanupOldImagesIfNeeded()
+}
+
 // Set adds or updates a single image in the cache
 func (cache *Base64ImageCache) Set(imageName, base64Image string) {
 	cache.mu.Lock()
@@ -123,7 +232,8 @@ func (cache *Base64ImageCache) Set(imageName, base64Image string) {
 // SetBatch adds multiple images to the cache
 func (cache *Base64ImageCache) SetBatch(imagePaths []string, base64Images []string) {
 	if len(imagePaths) != len(base64Images) {
-		log.Println("Error: Image path set and Base64 image set lengths do not match")
+		slog.Error("Image path set and base64 image set lengths do not match",
+			"paths", len(imagePaths), "images", len(base64Images))
 		return
 	}
 
@@ -191,7 +301,7 @@ func (cache *Base64ImageCache) RemoveByPaths(imagePaths []string) int {
 	}
 
 	if removedCount > 0 {
-		log.Printf("Removed %d images from cache for job %s", removedCount, cache.jobName)
+		slog.Debug("Removed images from cache", "job", cache.jobName, "count", removedCount)
 	}
 
 	return removedCount
@@ -252,3 +362,7 @@ func (cache *Base64ImageCache) cleanupOldImagesIfNeeded() {
 	// Update access order
 	cache.accessOrder = cache.accessOrder[toRemove:]
 }
+
+func (result ImageResult) OK() bool {
+	return result.Status == ImageStatusOK
+}
@@ -14,9 +14,20 @@ const (
 	maxImagesPerJobReview  = 1000             // Maximum number of images to cache for review modal
 )
 
+// CacheLimits sizes the image and review caches. Expiration and cleanup apply
+// to whole job entries; the image limits evict the least recently used images.
+type CacheLimits struct {
+	Expiration      time.Duration
+	CleanupInterval time.Duration
+	MaxImagesPerJob int
+	MaxReviewImages int
+}
+
 type CacheManager struct {
 	ImageCacheStore  *cache.Cache
 	ReviewCacheStore *cache.Cache
+	limits           CacheLimits
+	mu               sync.RWMutex
 }
 
 type Base64ImageCache struct {
@@ -27,10 +38,24 @@ type Base64ImageCache struct {
 	mu          sync.RWMutex
 }
 
+func DefaultCacheLimits() CacheLimits {
+	return CacheLimits{
+		Expiration:      defaultCacheExpiration,
+		CleanupInterval: defaultCleanupInterval,
+		MaxImagesPerJob: maxImagesPerJob,
+		MaxReviewImages: maxImagesPerJobReview,
+	}
+}
+
 func NewCacheManager() *CacheManager {
+	return NewCacheManagerWithLimits(DefaultCacheLimits())
+}
+
+func NewCacheManagerWithLimits(limits CacheLimits) *CacheManager {
 	return &CacheManager{
-		ImageCacheStore:  cache.New(defaultCacheExpiration, defaultCleanupInterval),
-		ReviewCacheStore: cache.New(defaultCacheExpiration, defaultCleanupInterval),
+		ImageCacheStore:  cache.New(limits.Expiration, limits.CleanupInterval),
+		ReviewCacheStore: cache.New(limits.Expiration, limits.CleanupInterval),
+		limits:           limits,
 	}
 }
 
@@ -51,3 +76,34 @@ func NewBase64ImageCacheWithLimit(jobName string, maxImages int) *Base64ImageCac
 		maxImages:   maxImages,
 	}
 }
+
+// CacheStoreStats summarizes one cache store and each of its entries
+type CacheStoreStats struct {
+	Entries []CacheEntryStats `json:"entries"`
+	Images  int               `json:"images"`
+	Bytes   int               `json:"bytes"`
+}
+
+type CacheEntryStats struct {
+	Key       string `json:"key"`
+	Images    int    `json:"images"`
+	MaxImages int    `json:"max_images"`
+	Bytes     int    `json:"bytes"`
+}
+
+const (
+	ImageStatusOK          = "ok"
+	ImageStatusDecodeError = "decode_error"
+	ImageStatusMissing     = "missing"
+	ImageStatusCancelled   = "cancelled"
+	ImageStatusTimedOut    = "timed_out"
+)
+
+// ImageResult is the outcome of compressing one image of a page. Base64 is only
+// set when Status is ok.
+type ImageResult struct {
+	ImagePath string `json:"image_path"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	Base64    string `json:"-"`
+}
@@ -1,6 +1,6 @@
 package models_verify_viewer
 
-import "log"
+import "log/slog"
 
 func (pages *Pages) JobName() string {
 	pages.mu.RLock()
@@ -16,12 +16,77 @@ func (pages *Pages) SetJobName(jobName string) {
 	pages.jobName = jobName
 }
 
+func (pages *Pages) SetSearchQuery(query ImageQuery) {
+	pages.mu.Lock()
+	defer pages.mu.Unlock()
+
+	pages.searchQuery = &query
+}
+
+// SearchQuery returns the query the pages were built from, if they are a search result
+func (pages *Pages) SearchQuery() (ImageQuery, bool) {
+	pages.mu.RLock()
+	defer pages.mu.RUnlock()
+
+	if pages.searchQuery == nil {
+		return ImageQuery{}, false
+	}
+	return *pages.searchQuery, true
+}
+
+func (pages *Pages) SetLayout(layout PageLayout) {
+	pages.mu.Lock()
+	defer pages.mu.Unlock()
+
+	pages.layout = layout
+}
+
+func (pages *Pages) Layout() PageLayout {
+	pages.mu.RLock()
+	defer pages.mu.RUnlock()
+
+	return pages.layout
+}
+
+func (pages *Pages) SetSampleID(sampleID string) {
+	pages.mu.Lock()
+	defer pages.mu.Unlock()
+
+	pages.sampleID = sampleID
+}
+
+// SampleID returns the recorded sample the pages were built from, if any
+func (pages *Pages) SampleID() string {
+	pages.mu.RLock()
+	defer pages.mu.RUnlock()
+
+	return pages.sampleID
+}
+
+// GetDatasetNames returns the dataset of the first image of every page
 func (pages *Pages) GetDatasetNames() []string {
 	pages.mu.RLock()
 	defer pages.mu.RUnlock()
 
 	datasets := make([]string, len(pages.datasets))
-	copy(datasets, pages.datasets)
+	for i, pageDatasets := range pages.datasets {
+		if len(pageDatasets) > 0 {
+			datasets[i] = pageDatasets[0]
+		}
+	}
+	return datasets
+}
+
+// GetPageDatasetNames returns every dataset on every page; packed pages can
+// hold several
+func (pages *Pages) GetPageDatasetNames() [][]string {
+	pages.mu.RLock()
+	defer pages.mu.RUnlock()
+
+	datasets := make([][]string, len(pages.datasets))
+	for i, pageDatasets := range pages.datasets {
+		datasets[i] = append([]string(nil), pageDatasets...)
+	}
 	return datasets
 }
 
@@ -52,7 +117,24 @@ func (pages *Pages) AddPage(datasetName string, imageSet []Image) {
 	pages.pageItems = append(pages.pageItems, pageItem)
 
 	// Cache dataset name for this page (allows duplicates)
-	pages.datasets = append(pages.datasets, datasetName)
+	pages.datasets = append(pages.datasets, []string{datasetName})
+}
+
+// AddPackedPage adds a page whose images may come from several datasets.
+// datasetNames holds the dataset of every image.
+func (pages *Pages) AddPackedPage(imageSet []Image, datasetNames []string) {
+	pages.mu.Lock()
+	defer pages.mu.Unlock()
+
+	pageItem := PageItem{
+		DatasetNames: datasetNames,
+		ImageSet:     imageSet,
+	}
+	if len(datasetNames) > 0 {
+		pageItem.DatasetName = datasetNames[0]
+	}
+	pages.pageItems = append(pages.pageItems, pageItem)
+	pages.datasets = append(pages.datasets, pageItem.Datasets())
 }
 
 func (pages *Pages) Clear() {
@@ -60,6 +142,9 @@ func (pages *Pages) Clear() {
 	defer pages.mu.Unlock()
 
 	pages.jobName = ""
+	pages.searchQuery = nil
+	pages.layout = PageLayout{}
+	pages.sampleID = ""
 	pages.datasets = pages.datasets[:0]
 	pages.pageItems = pages.pageItems[:0]
 }
@@ -69,7 +154,7 @@ func (pages *Pages) PageAt(index int) (PageItem, bool) {
 	defer pages.mu.RUnlock()
 
 	if index < 0 || index >= len(pages.pageItems) {
-		log.Printf("Index %d out of range in PageAt (len=%d)", index, len(pages.pageItems))
+		slog.Debug("Page index out of range", "job", pages.jobName, "page", index, "pages", len(pages.pageItems))
 		return PageItem{}, false
 	}
 	return pages.pageItems[index], true
@@ -132,9 +217,16 @@ func (pages *Pages) RemoveImages(imagePaths []string) int {
 
 	for _, pageItem := range pages.pageItems {
 		newImageSet := make([]Image, 0, len(pageItem.ImageSet))
-		for _, image := range pageItem.ImageSet {
+		var newDatasetNames []string
+		if pageItem.DatasetNames != nil {
+			newDatasetNames = make([]string, 0, len(pageItem.DatasetNames))
+		}
+		for i, image := range pageItem.ImageSet {
 			if !pathsToRemove[image.Path] {
 				newImageSet = append(newImageSet, image)
+				if newDatasetNames != nil {
+					newDatasetNames = append(newDatasetNames, pageItem.DatasetOf(i))
+				}
 			} else {
 				totalRemoved++
 			}
@@ -142,15 +234,62 @@ func (pages *Pages) RemoveImages(imagePaths []string) int {
 
 		if len(newImageSet) > 0 {
 			pageItem.ImageSet = newImageSet
+			if newDatasetNames != nil {
+				pageItem.DatasetNames = newDatasetNames
+				pageItem.DatasetName = newDatasetNames[0]
+			}
 			newPageItems = append(newPageItems, pageItem)
 		}
 	}
 
 	pages.pageItems = newPageItems
+	pages.datasets = make([][]string, 0, len(newPageItems))
+	for _, pageItem := range newPageItems {
+		pages.datasets = append(pages.datasets, pageItem.Datasets())
+	}
 
 	if totalRemoved > 0 {
-		log.Printf("Removed %d images from page data for job %s", totalRemoved, pages.jobName)
+		slog.Debug("Removed images from page data", "job", pages.jobName, "count", totalRemoved)
 	}
 
 	return totalRemoved
 }
+
+// DatasetOf returns the dataset of the i-th image of the page
+func (pageItem PageItem) DatasetOf(i int) string {
+	if i < len(pageItem.DatasetNames) {
+		return pageItem.DatasetNames[i]
+	}
+	return pageItem.DatasetName
+}
+
+// Datasets returns the datasets on the page, in order of first appearance
+func (pageItem PageItem) Datasets() []string {
+	if len(pageItem.DatasetNames) == 0 {
+		return []string{pageItem.DatasetName}
+	}
+
+	seen := make(map[string]bool, 1)
+	datasets := make([]string, 0, 1)
+	for _, datasetName := range pageItem.DatasetNames {
+		if !seen[datasetName] {
+			seen[datasetName] = true
+			datasets = append(datasets, datasetName)
+		}
+	}
+	return datasets
+}
+
+// ImagesByDataset groups the images of the page by dataset, in order of first appearance
+func (pageItem PageItem) ImagesByDataset() ([]string, map[string][]Image) {
+	order := make([]string, 0, 1)
+	groups := make(map[string][]Image)
+	for i, image := range pageItem.ImageSet {
+		datasetName := pageItem.DatasetOf(i)
+		if _, found := groups[datasetName]; !found {
+			order = append(order, datasetName)
+		}
+		groups[datasetName] = append(groups[datasetName], image)
+	}
+	return order, groups
+}
@@ -3,21 +3,43 @@ package models_verify_viewer
 import "sync"
 
 type Pages struct {
-	jobName   string
-	datasets  []string
+	jobName string
+	// datasets caches the datasets on every page, in order of first appearance
+	datasets  [][]string
 	pageItems []PageItem
-	mu        sync.RWMutex
+	// searchQuery is set when the pages hold search results instead of the whole job
+	searchQuery *ImageQuery
+	layout      PageLayout
+	sampleID    string
+	mu          sync.RWMutex
 }
 
+const (
+	// PageModeDataset chunks every dataset separately, so a page never mixes datasets
+	PageModeDataset = "dataset"
+	// PageModePacked fills every page up to the page size across dataset boundaries
+	PageModePacked = "packed"
+)
+
+// PageLayout describes how the current pages were built
+type PageLayout struct {
+	Mode   string     `json:"mode"`
+	Sort   ImageSort  `json:"sort"`
+	Sample SampleSpec `json:"sample"`
+}
+
+// PageItem is one page. DatasetName is the dataset of the first image; packed pages
+// also carry DatasetNames, the dataset of every image in ImageSet order.
 type PageItem struct {
-	DatasetName string  `json:"item_dataset_name"`
-	ImageSet    []Image `json:"item_image_set"`
+	DatasetName  string   `json:"item_dataset_name"`
+	DatasetNames []string `json:"item_dataset_names,omitempty"`
+	ImageSet     []Image  `json:"item_image_set"`
 }
 
 func NewPages() *Pages {
 	return &Pages{
 		jobName:   "",
-		datasets:  make([]string, 0),
+		datasets:  make([][]string, 0),
 		pageItems: make([]PageItem, 0),
 	}
 }
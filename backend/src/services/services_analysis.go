@@ -0,0 +1,93 @@
+package services
+
+import (
+	"backend/src/utils"
+	"context"
+	"runtime"
+	"sync"
+)
+
+// imageTask is one image visited by a background analysis pass
+type imageTask struct {
+	datasetName string
+	imageName   string
+	imagePath   string
+}
+
+// listImageTasks returns every image of a job as an analysis task
+func (js *JointServices) listImageTasks(ctx context.Context, jobName string) []imageTask {
+	root := js.root
+	jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, js.storage, jobName)
+	tasks := make([]imageTask, 0)
+	for _, dataset := range jobData.Datasets {
+		for _, image := range dataset.Image {
+			tasks = append(tasks, imageTask{
+				datasetName: dataset.Name,
+				imageName:   image.Name,
+				imagePath:   buildImagePath(root, jobName, dataset.Name, image.Name),
+			})
+		}
+	}
+	return tasks
+}
+
+// runImageTasks calls process for every task on one worker per CPU and returns
+// once all started tasks are done. Cancelling ctx stops handing out new tasks.
+func runImageTasks(ctx context.Context, tasks []imageTask, process func(imageTask)) {
+	taskChan := make(chan imageTask)
+
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range taskChan {
+				process(task)
+			}
+		}()
+	}
+
+feed:
+	for _, task := range tasks {
+		select {
+		case <-ctx.Done():
+			break feed
+		case taskChan <- task:
+		}
+	}
+	close(taskChan)
+	wg.Wait()
+}
+
+// startAnalysis runs a hash, quality or export pass in the background and tracks it so
+// shutdown can wait for its results to be saved
+func (js *JointServices) startAnalysis(run func()) {
+	js.analyses.Add(1)
+	go func() {
+		defer js.analyses.Done()
+		run()
+	}()
+}
+
+// CancelAnalysisJobs stops every running hash, quality and export pass
+func (js *JointServices) CancelAnalysisJobs() {
+	js.Hashes.CancelAll()
+	js.Quality.CancelAll()
+	js.Exports.CancelAll()
+}
+
+// waitForAnalysisJobs blocks until every pass has saved its results or ctx ends
+func (js *JointServices) waitForAnalysisJobs(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		js.analyses.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDatasetLockTTL = 15 * time.Minute
+	maxDatasetLockTTL     = 8 * time.Hour
+)
+
+var (
+	ErrJobNotFound     = errors.New("job not found")
+	ErrDatasetNotFound = errors.New("dataset not found")
+	ErrNoReviewers     = errors.New("at least one reviewer is required")
+)
+
+func (js *JointServices) restoreAssignmentBoard() {
+	if err := js.AssignmentBoard.LoadFromFile(js.backupDir); err != nil {
+		slog.Warn("Failed to load dataset assignments", "error", err)
+	}
+}
+
+func (js *JointServices) persistAssignmentBoard() {
+	if err := js.AssignmentBoard.SaveToFile(js.backupDir); err != nil {
+		slog.Warn("Failed to save dataset assignments", "error", err)
+	}
+}
+
+// AssignDatasets splits the datasets of a job among reviewers, balancing the
+// number of images each reviewer receives. Existing assignments of the job are replaced.
+func (js *JointServices) AssignDatasets(ctx context.Context, jobName string, reviewers []string, assignedBy string) ([]models_verify_viewer.DatasetAssignment, error) {
+	if !js.JobExists(jobName) {
+		return nil, ErrJobNotFound
+	}
+
+	reviewers = normalizeReviewers(reviewers)
+	if len(reviewers) == 0 {
+		return nil, ErrNoReviewers
+	}
+
+	jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, js.storage, jobName)
+	assignments := splitDatasets(jobData, reviewers, assignedBy)
+
+	js.AssignmentBoard.ReplaceJobAssignments(jobName, assignments)
+	js.persistAssignmentBoard()
+
+	slog.Info("Datasets assigned", "job", jobName, "datasets", len(assignments), "reviewers", len(reviewers))
+	return js.AssignmentBoard.Assignments(jobName), nil
+}
+
+// splitDatasets hands the largest remaining dataset to the reviewer with the fewest images so far
+func splitDatasets(jobData models_verify_viewer.Job, reviewers []string, assignedBy string) []models_verify_viewer.DatasetAssignment {
+	datasets := make([]models_verify_viewer.Dataset, len(jobData.Datasets))
+	copy(datasets, jobData.Datasets)
+	sort.SliceStable(datasets, func(i, j int) bool {
+		return datasets[i].GetImageLength() > datasets[j].GetImageLength()
+	})
+
+	load := make([]int, len(reviewers))
+	assignments := make([]models_verify_viewer.DatasetAssignment, 0, len(datasets))
+	for _, dataset := range datasets {
+		target := 0
+		for i := range reviewers {
+			if load[i] < load[target] {
+				target = i
+			}
+		}
+
+		imageCount := dataset.GetImageLength()
+		load[target] += imageCount
+		assignments = append(assignments, models_verify_viewer.NewDatasetAssignment(
+			jobData.Name, dataset.Name, reviewers[target], imageCount, assignedBy))
+	}
+
+	return assignments
+}
+
+func normalizeReviewers(reviewers []string) []string {
+	seen := make(map[string]bool, len(reviewers))
+	result := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		reviewer = strings.TrimSpace(reviewer)
+		if reviewer == "" || seen[reviewer] {
+			continue
+		}
+		seen[reviewer] = true
+		result = append(result, reviewer)
+	}
+	return result
+}
+
+// GetDatasetStatuses lists every dataset of a job with its assignee and current lock holder
+func (js *JointServices) GetDatasetStatuses(ctx context.Context, jobName string) ([]models_verify_viewer.DatasetStatus, error) {
+	if !js.JobExists(jobName) {
+		return nil, ErrJobNotFound
+	}
+
+	assignments := make(map[string]models_verify_viewer.DatasetAssignment)
+	for _, assignment := range js.AssignmentBoard.Assignments(jobName) {
+		assignments[assignment.DatasetName] = assignment
+	}
+
+	locks := make(map[string]models_verify_viewer.DatasetLock)
+	for _, lock := range js.AssignmentBoard.Locks(jobName) {
+		locks[lock.DatasetName] = lock
+	}
+
+	jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, js.storage, jobName)
+	statuses := make([]models_verify_viewer.DatasetStatus, 0, len(jobData.Datasets))
+	for _, dataset := range jobData.Datasets {
+		status := models_verify_viewer.DatasetStatus{
+			DatasetName: dataset.Name,
+			ImageCount:  dataset.GetImageLength(),
+		}
+		if assignment, found := assignments[dataset.Name]; found {
+			status.AssignedTo = assignment.Reviewer
+		}
+		if lock, found := locks[dataset.Name]; found {
+			expiresAt := lock.ExpiresAt
+			status.LockedBy = lock.Holder
+			status.LockExpiresAt = &expiresAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// ClaimDataset takes or renews the soft lock on a dataset for the given reviewer
+func (js *JointServices) ClaimDataset(ctx context.Context, jobName, datasetName, user string, ttl time.Duration) (models_verify_viewer.DatasetLock, error) {
+	if !utils.DatasetExists(ctx, js.storage, jobName, datasetName) {
+		return models_verify_viewer.DatasetLock{}, ErrDatasetNotFound
+	}
+
+	lock, err := js.AssignmentBoard.Claim(jobName, datasetName, user, clampLockTTL(ttl))
+	if err != nil {
+		return lock, err
+	}
+
+	js.persistAssignmentBoard()
+	return lock, nil
+}
+
+// ReleaseDataset drops the soft lock on a dataset
+func (js *JointServices) ReleaseDataset(jobName, datasetName, user string, force bool) error {
+	if err := js.AssignmentBoard.Release(jobName, datasetName, user, force); err != nil {
+		return err
+	}
+
+	js.persistAssignmentBoard()
+	return nil
+}
+
+// GetDatasetLocks returns live dataset locks, optionally limited to one job
+func (js *JointServices) GetDatasetLocks(jobName string) []models_verify_viewer.DatasetLock {
+	return js.AssignmentBoard.Locks(jobName)
+}
+
+func clampLockTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return DefaultDatasetLockTTL
+	}
+	if ttl > maxDatasetLockTTL {
+		return maxDatasetLockTTL
+	}
+	return ttl
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"log/slog"
+	"sort"
+)
+
+func openAuditLog(backupDir string) *models_verify_viewer.AuditLog {
+	auditLog, err := models_verify_viewer.NewAuditLog(backupDir)
+	if err != nil {
+		slog.Warn("Failed to resume audit log chain", "error", err)
+	}
+	return auditLog
+}
+
+// recordAudit appends an entry to the audit log. Failures are logged but never
+// block the mutation that is being recorded.
+func (js *JointServices) recordAudit(entry models_verify_viewer.AuditEntry) {
+	if _, err := js.AuditLog.Append(entry); err != nil {
+		slog.Error("Failed to write audit entry", "action", entry.Action, "user", entry.User, "error", err)
+	}
+}
+
+func auditEntry(user, action, detail string) models_verify_viewer.AuditEntry {
+	entry := models_verify_viewer.NewAuditEntry(user, action)
+	entry.Detail = detail
+	return entry
+}
+
+// recordAudits appends entries to the audit log in a single write
+func (js *JointServices) recordAudits(entries []models_verify_viewer.AuditEntry) {
+	if _, err := js.AuditLog.AppendAll(entries); err != nil {
+		slog.Error("Failed to write audit entries", "entries", len(entries), "error", err)
+	}
+}
+
+func itemAuditEntry(user, action string, item models_verify_viewer.PendingReviewItem, detail string) models_verify_viewer.AuditEntry {
+	entry := auditEntry(user, action, detail)
+	entry.JobName = item.JobName
+	entry.DatasetName = item.DatasetName
+	entry.ImageName = item.ImageName
+	entry.ImagePath = item.ImagePath
+	return entry
+}
+
+func (js *JointServices) recordItemAudit(user, action string, item models_verify_viewer.PendingReviewItem, detail string) {
+	js.recordAudit(itemAuditEntry(user, action, item, detail))
+}
+
+// recordReviewChanges compares the pending review set before and after a change
+// and writes one entry per added or removed image, like deletions, so no entry
+// grows with the size of the change
+func (js *JointServices) recordReviewChanges(user, action string, before, after []models_verify_viewer.PendingReviewItem) {
+	entries := make([]models_verify_viewer.AuditEntry, 0)
+	for _, item := range reviewDiff(after, before) {
+		entries = append(entries, itemAuditEntry(user, action, item, "added to pending review"))
+	}
+	for _, item := range reviewDiff(before, after) {
+		entries = append(entries, itemAuditEntry(user, action, item, "removed from pending review"))
+	}
+	js.recordAudits(entries)
+}
+
+// reviewDiff returns the items missing from other, sorted by job, dataset and image
+func reviewDiff(items, other []models_verify_viewer.PendingReviewItem) []models_verify_viewer.PendingReviewItem {
+	otherKeys := make(map[string]bool, len(other))
+	for _, item := range other {
+		otherKeys[item.Key()] = true
+	}
+
+	diff := make([]models_verify_viewer.PendingReviewItem, 0)
+	for _, item := range items {
+		if !otherKeys[item.Key()] {
+			diff = append(diff, item)
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool {
+		a, b := diff[i], diff[j]
+		if a.JobName != b.JobName {
+			return a.JobName < b.JobName
+		}
+		if a.DatasetName != b.DatasetName {
+			return a.DatasetName < b.DatasetName
+		}
+		return a.ImageName < b.ImageName
+	})
+	return diff
+}
+
+// QueryAuditLog returns audit entries matching the filter, newest first
+func (js *JointServices) QueryAuditLog(filter models_verify_viewer.AuditFilter) ([]models_verify_viewer.AuditEntry, error) {
+	return js.AuditLog.Query(filter)
+}
+
+// VerifyAuditLog checks the hash chain and returns the number of valid entries
+func (js *JointServices) VerifyAuditLog() (int, error) {
+	return js.AuditLog.Verify()
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// JobSummary describes the scanned content of a job and its pending review items
+type JobSummary struct {
+	JobName      string           `json:"job_name"`
+	DatasetCount int              `json:"dataset_count"`
+	ImageCount   int              `json:"image_count"`
+	LabelCount   int              `json:"label_count"`
+	PendingCount int              `json:"pending_count"`
+	Datasets     []DatasetSummary `json:"datasets"`
+}
+
+type DatasetSummary struct {
+	DatasetName  string `json:"dataset_name"`
+	ImageCount   int    `json:"image_count"`
+	LabelCount   int    `json:"label_count"`
+	PendingCount int    `json:"pending_count"`
+}
+
+// ImportResult reports how imported decisions were applied to the pending review set
+type ImportResult struct {
+	Dropped int      `json:"dropped"`
+	Kept    int      `json:"kept"`
+	Skipped int      `json:"skipped"`
+	Errors  []string `json:"errors"`
+}
+
+// GetJobSummary scans a job and counts its datasets, images, labels and pending items
+func (js *JointServices) GetJobSummary(ctx context.Context, jobName string) (JobSummary, error) {
+	if !js.JobExists(jobName) {
+		return JobSummary{}, ErrJobNotFound
+	}
+
+	pending := make(map[string]int)
+	for _, item := range js.GetPendingReviewItems() {
+		if item.JobName == jobName {
+			pending[item.DatasetName]++
+		}
+	}
+
+	jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, js.storage, jobName)
+	summary := JobSummary{
+		JobName:      jobName,
+		DatasetCount: len(jobData.Datasets),
+		Datasets:     make([]DatasetSummary, 0, len(jobData.Datasets)),
+	}
+	for _, dataset := range jobData.Datasets {
+		datasetSummary := DatasetSummary{
+			DatasetName:  dataset.Name,
+			ImageCount:   dataset.GetImageLength(),
+			LabelCount:   len(dataset.Label),
+			PendingCount: pending[dataset.Name],
+		}
+		summary.ImageCount += datasetSummary.ImageCount
+		summary.LabelCount += datasetSummary.LabelCount
+		summary.PendingCount += datasetSummary.PendingCount
+		summary.Datasets = append(summary.Datasets, datasetSummary)
+	}
+
+	return summary, nil
+}
+
+// ImportDecisions applies a decision list to the pending review set: "drop" rows are
+// flagged, "keep" rows are unflagged and anything else (e.g. "deleted") is skipped.
+func (js *JointServices) ImportDecisions(records []models_verify_viewer.ReviewDecision, user string) ImportResult {
+	result := ImportResult{Errors: make([]string, 0)}
+	root := js.root
+	now := time.Now()
+
+	for i, record := range records {
+		if !isValidImageItem(record.JobName, record.DatasetName, record.ImageName) {
+			result.Skipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("record %d: missing job, dataset or image", i+1))
+			continue
+		}
+		if !js.JobExists(record.JobName) {
+			result.Skipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("record %d: job not found: %s", i+1, record.JobName))
+			continue
+		}
+
+		item := models_verify_viewer.PendingReviewItem{
+			JobName:     record.JobName,
+			DatasetName: record.DatasetName,
+			ImageName:   record.ImageName,
+			ImagePath:   buildImagePath(root, record.JobName, record.DatasetName, record.ImageName),
+			Reviewer:    record.Reviewer,
+			Note:        record.Note,
+		}
+
+		switch record.Decision {
+		case models_verify_viewer.DecisionDrop:
+			if item.Reviewer == "" {
+				item.Reviewer = user
+			}
+			flaggedAt := record.DecidedAt
+			if flaggedAt.IsZero() {
+				flaggedAt = now
+			}
+			item.FlaggedAt = &flaggedAt
+			js.PendingReviewData.Add(item)
+			result.Dropped++
+		case models_verify_viewer.DecisionKeep:
+			js.PendingReviewData.Remove(item)
+			result.Kept++
+		default:
+			result.Skipped++
+		}
+	}
+
+	if result.Dropped+result.Kept == 0 {
+		return result
+	}
+
+	if err := js.PendingReviewData.CreateBackup(js.backupDir); err != nil {
+		slog.Warn("Failed to create backup after import", "error", err)
+	}
+	js.recordAudit(auditEntry(user, models_verify_viewer.AuditActionDecisionsImported,
+		fmt.Sprintf("%d dropped, %d kept, %d skipped", result.Dropped, result.Kept, result.Skipped)))
+	return result
+}
+
+// PendingDeletionItems returns the pending review items of a job (every job when
+// empty) with image paths resolved against the image root.
+func (js *JointServices) PendingDeletionItems(jobName string) []models_verify_viewer.PendingReviewItem {
+	root := js.root
+	items := make([]models_verify_viewer.PendingReviewItem, 0)
+	for _, item := range js.GetPendingReviewItems() {
+		if jobName != "" && item.JobName != jobName {
+			continue
+		}
+		item.ImagePath = buildImagePath(root, item.JobName, item.DatasetName, item.ImageName)
+		items = append(items, item)
+	}
+	return items
+}
+
+// ApplyPendingDeletions deletes every pending review image of a job (every job
+// when empty). When deletion approval is required a deletion batch is created instead.
+func (js *JointServices) ApplyPendingDeletions(ctx context.Context, jobName string, user string) (*DeleteImageResult, error) {
+	if jobName != "" && !js.JobExists(jobName) {
+		return nil, ErrJobNotFound
+	}
+
+	items := js.PendingDeletionItems(jobName)
+	if js.DeletionApprovalRequired() {
+		return js.requestDeletion(items, user)
+	}
+	return js.deleteItems(ctx, items, user, ""), nil
+}
+
+// CreateBackup writes a backup of the current pending review set and returns its file name
+func (js *JointServices) CreateBackup() (string, error) {
+	backupDir := js.backupDir
+	if err := js.PendingReviewData.CreateBackup(backupDir); err != nil {
+		return "", err
+	}
+	return js.PendingReviewData.GetLatestBackup(backupDir)
+}
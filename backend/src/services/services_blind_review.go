@@ -0,0 +1,156 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+func (js *JointServices) restoreBlindReviews() {
+	if err := js.BlindReviews.LoadFromFile(js.backupDir); err != nil {
+		slog.Warn("Failed to load blind review sessions", "error", err)
+	}
+}
+
+func (js *JointServices) persistBlindReviews() {
+	if err := js.BlindReviews.SaveToFile(js.backupDir); err != nil {
+		slog.Warn("Failed to save blind review sessions", "error", err)
+	}
+}
+
+// CreateBlindReview draws a reproducible random sample of a job's images that
+// two reviewers will decide on independently. A zero seed picks one from the clock.
+func (js *JointServices) CreateBlindReview(ctx context.Context, jobName string, reviewers []string, sampleSize int, seed int64, createdBy string) (models_verify_viewer.BlindReviewSummary, error) {
+	if !js.JobExists(jobName) {
+		return models_verify_viewer.BlindReviewSummary{}, ErrJobNotFound
+	}
+
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, js.storage, jobName)
+	sample := sampleJobImages(jobData, sampleSize, seed)
+
+	session, err := models_verify_viewer.NewBlindReviewSession(
+		newRecordID("blind"), jobName, normalizeReviewers(reviewers), seed, sample, createdBy)
+	if err != nil {
+		return models_verify_viewer.BlindReviewSummary{}, err
+	}
+
+	js.BlindReviews.Add(session)
+	js.persistBlindReviews()
+
+	slog.Info("Blind review created", "session_id", session.ID, "job", jobName, "images", len(sample), "seed", seed)
+	return js.BlindReviews.Summary(session.ID)
+}
+
+// sampleJobImages picks sampleSize images uniformly from every dataset of the job.
+// A non-positive sampleSize or one larger than the job takes every image.
+func sampleJobImages(jobData models_verify_viewer.Job, sampleSize int, seed int64) []models_verify_viewer.PendingReviewItem {
+	items := make([]models_verify_viewer.PendingReviewItem, 0)
+	for _, dataset := range jobData.Datasets {
+		for _, image := range dataset.Image {
+			items = append(items, models_verify_viewer.PendingReviewItem{
+				JobName:     jobData.Name,
+				DatasetName: dataset.Name,
+				ImageName:   image.Name,
+				ImagePath:   image.Path,
+			})
+		}
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+
+	if sampleSize > 0 && sampleSize < len(items) {
+		items = items[:sampleSize]
+	}
+	return items
+}
+
+// ListBlindReviews returns session summaries without any decisions
+func (js *JointServices) ListBlindReviews(jobName string) []models_verify_viewer.BlindReviewSummary {
+	return js.BlindReviews.List(jobName)
+}
+
+// GetBlindReviewView returns the sample and only the requesting reviewer's decisions
+func (js *JointServices) GetBlindReviewView(sessionID, user string) (models_verify_viewer.BlindReviewView, error) {
+	return js.BlindReviews.ViewFor(sessionID, user)
+}
+
+// SubmitBlindDecision records one reviewer's independent decision on a sampled image
+func (js *JointServices) SubmitBlindDecision(sessionID, user, datasetName, imageName, decision, note string) error {
+	err := js.BlindReviews.RecordDecision(sessionID, models_verify_viewer.ReviewDecision{
+		DatasetName: datasetName,
+		ImageName:   imageName,
+		Decision:    decision,
+		Reviewer:    user,
+		Note:        note,
+	})
+	if err != nil {
+		return err
+	}
+	js.persistBlindReviews()
+
+	summary, err := js.BlindReviews.Summary(sessionID)
+	if err == nil {
+		js.recordDecisions(user, []models_verify_viewer.PendingReviewItem{{
+			JobName:     summary.JobName,
+			DatasetName: datasetName,
+			ImageName:   imageName,
+		}})
+	}
+	return nil
+}
+
+// GetBlindReviewAgreement reports agreement statistics and conflicts. To keep the
+// review blind, only an adjudicator sees it before both reviewers have finished.
+func (js *JointServices) GetBlindReviewAgreement(sessionID string, adjudicator bool) (models_verify_viewer.AgreementReport, error) {
+	summary, err := js.BlindReviews.Summary(sessionID)
+	if err != nil {
+		return models_verify_viewer.AgreementReport{}, err
+	}
+
+	if !summary.Complete && !adjudicator {
+		return models_verify_viewer.AgreementReport{}, models_verify_viewer.ErrBlindReviewIncomplete
+	}
+
+	return js.BlindReviews.Agreement(sessionID)
+}
+
+// AdjudicateBlindReview settles a conflict. A final "drop" puts the image into
+// the pending review set; a final "keep" takes it out.
+func (js *JointServices) AdjudicateBlindReview(sessionID, user, datasetName, imageName, decision, note string) error {
+	item, err := js.BlindReviews.Adjudicate(sessionID, models_verify_viewer.ReviewDecision{
+		DatasetName: datasetName,
+		ImageName:   imageName,
+		Decision:    decision,
+		Reviewer:    user,
+		Note:        note,
+	})
+	if err != nil {
+		return err
+	}
+	js.persistBlindReviews()
+
+	if decision == models_verify_viewer.DecisionDrop {
+		js.PendingReviewData.Add(item)
+	} else {
+		js.PendingReviewData.Remove(item)
+	}
+
+	if err := js.PendingReviewData.CreateBackup(js.backupDir); err != nil {
+		slog.Warn("Failed to create backup after adjudication", "error", err)
+	}
+
+	js.recordItemAudit(user, models_verify_viewer.AuditActionBlindReviewAdjudicated, item,
+		fmt.Sprintf("session %s: %s", sessionID, decision))
+	return nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// maxBulkFlagSample limits how many matched items are echoed back
+const maxBulkFlagSample = 50
+
+var (
+	ErrInvalidFilter = errors.New("invalid filter")
+	ErrEmptyFilter   = errors.New("filter must contain at least one condition")
+)
+
+type BulkFlagResult struct {
+	JobName        string                                   `json:"job_name"`
+	DryRun         bool                                     `json:"dry_run"`
+	MatchedCount   int                                      `json:"matched_count"`
+	AlreadyPending int                                      `json:"already_pending"`
+	AddedCount     int                                      `json:"added_count"`
+	InvalidLabels  int                                      `json:"invalid_labels"`
+	Sample         []models_verify_viewer.PendingReviewItem `json:"sample"`
+}
+
+// MatchJobImages returns the images of a job matching the filter and the number of
+// label files that could not be parsed while matching.
+func (js *JointServices) MatchJobImages(ctx context.Context, jobName string, filter models_verify_viewer.ImageFilter) ([]models_verify_viewer.PendingReviewItem, int, error) {
+	if !js.JobExists(jobName) {
+		return nil, 0, ErrJobNotFound
+	}
+	if err := filter.Compile(); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+
+	var qualityRecords map[string]models_verify_viewer.ImageQualityRecord
+	if filter.NeedsQuality() {
+		records, err := js.qualityRecordsByKey(jobName)
+		if err != nil {
+			return nil, 0, err
+		}
+		qualityRecords = records
+	}
+
+	root := js.root
+	jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, js.storage, jobName)
+	items := make([]models_verify_viewer.PendingReviewItem, 0)
+	invalidLabels := 0
+
+	for _, dataset := range jobData.Datasets {
+		if filter.DatasetName != "" && dataset.Name != filter.DatasetName {
+			continue
+		}
+
+		labels := dataset.LabelIndex()
+		for _, image := range dataset.Image {
+			if !filter.MatchesName(dataset.Name, image.Name) {
+				continue
+			}
+
+			if filter.NeedsQuality() {
+				record, found := qualityRecords[dataset.Name+"|"+image.Name]
+				if !found || !record.HasAnyFlag(filter.QualityFlags) {
+					continue
+				}
+			}
+
+			if filter.NeedsAnnotation() {
+				label, ok := labels.For(image.Name)
+				if !ok {
+					continue
+				}
+				annotation, err := utils.ReadAnnotation(ctx, js.storage, label.Path)
+				if err != nil {
+					invalidLabels++
+					continue
+				}
+				if !filter.MatchesAnnotation(annotation) {
+					continue
+				}
+			}
+
+			items = append(items, models_verify_viewer.PendingReviewItem{
+				JobName:     jobName,
+				DatasetName: dataset.Name,
+				ImageName:   image.Name,
+				ImagePath:   buildImagePath(root, jobName, dataset.Name, image.Name),
+			})
+		}
+	}
+
+	return items, invalidLabels, nil
+}
+
+// BulkFlag adds every image of a job matching the filter to the pending review set.
+// With dryRun nothing is changed and only the match counts are returned.
+func (js *JointServices) BulkFlag(ctx context.Context, jobName string, filter models_verify_viewer.ImageFilter, dryRun bool, note, user string) (BulkFlagResult, error) {
+	if filter.IsEmpty() {
+		return BulkFlagResult{}, ErrEmptyFilter
+	}
+
+	items, invalidLabels, err := js.MatchJobImages(ctx, jobName, filter)
+	if err != nil {
+		return BulkFlagResult{}, err
+	}
+
+	result := BulkFlagResult{
+		JobName:       jobName,
+		DryRun:        dryRun,
+		MatchedCount:  len(items),
+		InvalidLabels: invalidLabels,
+		Sample:        items[:min(len(items), maxBulkFlagSample)],
+	}
+
+	added, alreadyPending := js.newFlaggedItems(items, note, user)
+	result.AlreadyPending = alreadyPending
+	result.AddedCount = len(added)
+
+	if dryRun || len(added) == 0 {
+		return result, nil
+	}
+
+	js.addFlaggedItems(jobName, user, models_verify_viewer.AuditActionBulkFlagged, added,
+		fmt.Sprintf("%d items added (%d matched)", result.AddedCount, result.MatchedCount))
+	return result, nil
+}
+
+// newFlaggedItems stamps the items that are not pending yet with the reviewer and
+// note, and counts the ones already pending
+func (js *JointServices) newFlaggedItems(items []models_verify_viewer.PendingReviewItem, note, user string) ([]models_verify_viewer.PendingReviewItem, int) {
+	pending := make(map[string]bool)
+	for _, item := range js.GetPendingReviewItems() {
+		pending[item.Key()] = true
+	}
+
+	now := time.Now()
+	alreadyPending := 0
+	added := make([]models_verify_viewer.PendingReviewItem, 0, len(items))
+	for _, item := range items {
+		if pending[item.Key()] {
+			alreadyPending++
+			continue
+		}
+		item.Reviewer = user
+		item.Note = note
+		item.FlaggedAt = &now
+		added = append(added, item)
+	}
+	return added, alreadyPending
+}
+
+// addFlaggedItems puts flagged items into the pending review set, then backs up
+// and records one audit entry for the whole batch
+func (js *JointServices) addFlaggedItems(jobName, user, action string, items []models_verify_viewer.PendingReviewItem, detail string) {
+	for _, item := range items {
+		js.PendingReviewData.Add(item)
+	}
+	js.recordDecisions(user, items)
+
+	if err := js.PendingReviewData.CreateBackup(js.backupDir); err != nil {
+		slog.Warn("Failed to create backup after flagging", "job", jobName, "items", len(items), "error", err)
+	}
+	entry := auditEntry(user, action, detail)
+	entry.JobName = jobName
+	js.recordAudit(entry)
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// SetDeletionApprovalRequired toggles the two-person approval workflow for deletions
+func (js *JointServices) SetDeletionApprovalRequired(required bool) {
+	js.deletionApproval.Store(required)
+	slog.Info("Deletion approval configured", "required", required)
+}
+
+func (js *JointServices) DeletionApprovalRequired() bool {
+	return js.deletionApproval.Load()
+}
+
+func (js *JointServices) restoreDeletionQueue() {
+	if err := js.DeletionQueue.LoadFromFile(js.backupDir); err != nil {
+		slog.Warn("Failed to load deletion batches", "error", err)
+		return
+	}
+	if count := len(js.DeletionQueue.List(models_verify_viewer.DeletionStatusPending)); count > 0 {
+		slog.Info("Restored pending deletion batches", "count", count)
+	}
+}
+
+func (js *JointServices) persistDeletionQueue() {
+	if err := js.DeletionQueue.SaveToFile(js.backupDir); err != nil {
+		slog.Warn("Failed to save deletion batches", "error", err)
+	}
+}
+
+// requestDeletion records a pending deletion batch that another user must approve
+func (js *JointServices) requestDeletion(items []models_verify_viewer.PendingReviewItem, user string) (*DeleteImageResult, error) {
+	if len(items) == 0 {
+		return &DeleteImageResult{DeletedCount: 0, CacheCleared: false}, nil
+	}
+
+	batch := models_verify_viewer.NewDeletionBatch(newRecordID("del"), items, user)
+	js.DeletionQueue.Add(batch)
+	js.persistDeletionQueue()
+
+	js.recordAudit(auditEntry(user, models_verify_viewer.AuditActionDeletionRequested,
+		fmt.Sprintf("batch %s (%d items)", batch.ID, len(items))))
+	slog.Info("Deletion batch awaiting approval", "batch_id", batch.ID, "user", user, "items", len(items))
+
+	return &DeleteImageResult{
+		DeletedCount:    0,
+		CacheCleared:    false,
+		AffectedJobs:    []string{},
+		DeletedPaths:    []string{},
+		PendingApproval: true,
+		BatchID:         batch.ID,
+	}, nil
+}
+
+// GetDeletionBatches returns deletion batches filtered by status (empty for all)
+func (js *JointServices) GetDeletionBatches(status string) []models_verify_viewer.DeletionBatch {
+	return js.DeletionQueue.List(status)
+}
+
+// ApproveDeletionBatch approves a pending batch and physically deletes its images
+func (js *JointServices) ApproveDeletionBatch(ctx context.Context, batchID string, user string) (*DeleteImageResult, error) {
+	batch, err := js.DeletionQueue.Decide(batchID, user, true, "")
+	if err != nil {
+		return nil, err
+	}
+	js.persistDeletionQueue()
+
+	detail := fmt.Sprintf("batch %s requested by %s", batch.ID, batch.RequestedBy)
+	js.recordAudit(auditEntry(user, models_verify_viewer.AuditActionDeletionApproved, detail))
+
+	result := js.deleteItems(ctx, batch.Items, user, detail)
+	result.BatchID = batch.ID
+	return result, nil
+}
+
+// RejectDeletionBatch rejects a pending batch; no files are touched
+func (js *JointServices) RejectDeletionBatch(batchID string, user string, reason string) (models_verify_viewer.DeletionBatch, error) {
+	batch, err := js.DeletionQueue.Decide(batchID, user, false, reason)
+	if err != nil {
+		return batch, err
+	}
+	js.persistDeletionQueue()
+
+	js.recordAudit(auditEntry(user, models_verify_viewer.AuditActionDeletionRejected,
+		fmt.Sprintf("batch %s requested by %s: %s", batch.ID, batch.RequestedBy, reason)))
+	return batch, nil
+}
+
+// newRecordID builds a sortable, collision-resistant identifier such as del-20250101120000-1a2b3c4d
+func newRecordID(prefix string) string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%s-%s-%s", prefix, time.Now().Format("20060102150405"), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	ExportFormatCSV   = "csv"
+	ExportFormatJSONL = "jsonl"
+
+	// DecisionDeleted marks images that were already removed from disk
+	DecisionDeleted = "deleted"
+
+	ManifestKeep = "keep"
+	ManifestDrop = "drop"
+
+	manifestFilePermissions = 0644
+
+	trainingSetExportDir = "exports"
+)
+
+var ErrInvalidAnnotationFormat = errors.New("invalid annotation format")
+
+var decisionCSVHeader = []string{"job", "dataset", "image", "decision", "reviewer", "note", "time"}
+
+// DatasetManifest lists the images of one dataset to keep and to drop.
+// Paths are relative to the image root so the lists stay portable.
+type DatasetManifest struct {
+	JobName     string   `json:"job_name"`
+	DatasetName string   `json:"dataset_name"`
+	Keep        []string `json:"keep"`
+	Drop        []string `json:"drop"`
+}
+
+func IsValidExportFormat(format string) bool {
+	return format == ExportFormatCSV || format == ExportFormatJSONL
+}
+
+// BuildDecisionRecords collects the decisions of a job (every job when jobName is
+// empty): pending review items become "drop", images deleted according to the
+// audit log become "deleted" and, with includeKeep, every other image is "keep".
+func (js *JointServices) BuildDecisionRecords(ctx context.Context, jobName string, includeKeep bool) ([]models_verify_viewer.ReviewDecision, error) {
+	if jobName != "" && !js.JobExists(jobName) {
+		return nil, ErrJobNotFound
+	}
+
+	records := make([]models_verify_viewer.ReviewDecision, 0)
+	decided := make(map[string]bool)
+
+	for _, item := range js.GetPendingReviewItems() {
+		if jobName != "" && item.JobName != jobName {
+			continue
+		}
+		record := pendingItemToDecision(item)
+		records = append(records, record)
+		decided[record.Key()] = true
+	}
+
+	deleted, err := js.deletedImageRecords(jobName)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range deleted {
+		if !decided[record.Key()] {
+			records = append(records, record)
+			decided[record.Key()] = true
+		}
+	}
+
+	if includeKeep {
+		for _, job := range js.exportJobs(jobName) {
+			jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, js.storage, job)
+			for _, dataset := range jobData.Datasets {
+				for _, image := range dataset.Image {
+					record := models_verify_viewer.ReviewDecision{
+						JobName:     job,
+						DatasetName: dataset.Name,
+						ImageName:   image.Name,
+						Decision:    models_verify_viewer.DecisionKeep,
+					}
+					if !decided[record.Key()] {
+						records = append(records, record)
+					}
+				}
+			}
+		}
+	}
+
+	sortDecisionRecords(records)
+	return records, nil
+}
+
+func pendingItemToDecision(item models_verify_viewer.PendingReviewItem) models_verify_viewer.ReviewDecision {
+	record := models_verify_viewer.ReviewDecision{
+		JobName:     item.JobName,
+		DatasetName: item.DatasetName,
+		ImageName:   item.ImageName,
+		Decision:    models_verify_viewer.DecisionDrop,
+		Reviewer:    item.Reviewer,
+		Note:        item.Note,
+	}
+	if item.FlaggedAt != nil {
+		record.DecidedAt = *item.FlaggedAt
+	}
+	return record
+}
+
+func (js *JointServices) deletedImageRecords(jobName string) ([]models_verify_viewer.ReviewDecision, error) {
+	entries, err := js.QueryAuditLog(models_verify_viewer.AuditFilter{
+		JobName: jobName,
+		Action:  models_verify_viewer.AuditActionImageDeleted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]models_verify_viewer.ReviewDecision, 0, len(entries))
+	for _, entry := range entries {
+		records = append(records, models_verify_viewer.ReviewDecision{
+			JobName:     entry.JobName,
+			DatasetName: entry.DatasetName,
+			ImageName:   entry.ImageName,
+			Decision:    DecisionDeleted,
+			Reviewer:    entry.User,
+			Note:        entry.Detail,
+			DecidedAt:   entry.Timestamp,
+		})
+	}
+	return records, nil
+}
+
+func (js *JointServices) exportJobs(jobName string) []string {
+	if jobName != "" {
+		return []string{jobName}
+	}
+	return js.GetJobList()
+}
+
+func sortDecisionRecords(records []models_verify_viewer.ReviewDecision) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].JobName != records[j].JobName {
+			return records[i].JobName < records[j].JobName
+		}
+		if records[i].DatasetName != records[j].DatasetName {
+			return records[i].DatasetName < records[j].DatasetName
+		}
+		return records[i].ImageName < records[j].ImageName
+	})
+}
+
+// WriteDecisionRecords writes records as CSV (with header) or JSON Lines
+func WriteDecisionRecords(w io.Writer, format string, records []models_verify_viewer.ReviewDecision) error {
+	switch format {
+	case ExportFormatCSV:
+		return writeDecisionsCSV(w, records)
+	case ExportFormatJSONL:
+		return writeDecisionsJSONL(w, records)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+func writeDecisionsCSV(w io.Writer, records []models_verify_viewer.ReviewDecision) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(decisionCSVHeader); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		row := []string{
+			record.JobName,
+			record.DatasetName,
+			record.ImageName,
+			record.Decision,
+			record.Reviewer,
+			record.Note,
+			formatDecisionTime(record.DecidedAt),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeDecisionsJSONL(w io.Writer, records []models_verify_viewer.ReviewDecision) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatDecisionTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// BuildManifests returns keep and drop lists for every dataset of a job. Images
+// pending review are dropped; deleted images no longer exist and are left out.
+func (js *JointServices) BuildManifests(ctx context.Context, jobName string) ([]DatasetManifest, error) {
+	if !js.JobExists(jobName) {
+		return nil, ErrJobNotFound
+	}
+
+	dropped := make(map[string]bool)
+	for _, item := range js.GetPendingReviewItems() {
+		if item.JobName == jobName {
+			dropped[createItemKey(item.JobName, item.DatasetName, item.ImageName)] = true
+		}
+	}
+
+	jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, js.storage, jobName)
+	manifests := make([]DatasetManifest, 0, len(jobData.Datasets))
+	for _, dataset := range jobData.Datasets {
+		manifest := DatasetManifest{
+			JobName:     jobName,
+			DatasetName: dataset.Name,
+			Keep:        make([]string, 0, dataset.GetImageLength()),
+			Drop:        make([]string, 0),
+		}
+
+		for _, image := range dataset.Image {
+			relPath := filepath.ToSlash(filepath.Join(jobName, dataset.Name, "image", image.Name))
+			if dropped[createItemKey(jobName, dataset.Name, image.Name)] {
+				manifest.Drop = append(manifest.Drop, relPath)
+			} else {
+				manifest.Keep = append(manifest.Keep, relPath)
+			}
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	return manifests, nil
+}
+
+// List returns the keep or drop list of the manifest
+func (manifest DatasetManifest) List(kind string) ([]string, bool) {
+	switch kind {
+	case ManifestKeep:
+		return manifest.Keep, true
+	case ManifestDrop:
+		return manifest.Drop, true
+	default:
+		return nil, false
+	}
+}
+
+// WriteManifestFiles writes <job>/<dataset>.keep.txt and .drop.txt under outputDir
+// and returns the written file paths.
+func WriteManifestFiles(outputDir string, manifests []DatasetManifest) ([]string, error) {
+	written := make([]string, 0, len(manifests)*2)
+	for _, manifest := range manifests {
+		jobDir := filepath.Join(outputDir, manifest.JobName)
+		if err := os.MkdirAll(jobDir, 0755); err != nil {
+			return written, fmt.Errorf("failed to create manifest directory: %v", err)
+		}
+
+		for _, kind := range []string{ManifestKeep, ManifestDrop} {
+			paths, _ := manifest.List(kind)
+			path := filepath.Join(jobDir, fmt.Sprintf("%s.%s.txt", manifest.DatasetName, kind))
+			if err := writeLines(path, paths); err != nil {
+				return written, err
+			}
+			written = append(written, path)
+		}
+	}
+	return written, nil
+}
+
+func writeLines(path string, lines []string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, manifestFilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	defer file.Close()
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(file, line); err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// ExportTrainingSet writes the surviving images of a job (everything not pending
+// review) with COCO and/or YOLO annotations. An empty outputPath exports into
+// <backup>/exports/<job>_<timestamp>[.tar.gz].
+func (js *JointServices) ExportTrainingSet(ctx context.Context, jobName string, formats []string, archive bool, outputPath string) (utils.TrainingSetSummary, error) {
+	export, err := js.prepareTrainingSetExport(ctx, jobName, formats, archive, outputPath)
+	if err != nil {
+		return utils.TrainingSetSummary{}, err
+	}
+	return utils.ExportTrainingSet(ctx, js.storage, export.job, export.outputPath, export.options)
+}
+
+// trainingSetExport is a validated export waiting to be written
+type trainingSetExport struct {
+	job        models_verify_viewer.Job
+	outputPath string
+	options    utils.TrainingSetOptions
+	images     int
+}
+
+func (js *JointServices) prepareTrainingSetExport(ctx context.Context, jobName string, formats []string, archive bool, outputPath string) (trainingSetExport, error) {
+	if !js.JobExists(jobName) {
+		return trainingSetExport{}, ErrJobNotFound
+	}
+	for _, format := range formats {
+		if !utils.IsValidAnnotationFormat(format) {
+			return trainingSetExport{}, fmt.Errorf("%w: %s", ErrInvalidAnnotationFormat, format)
+		}
+	}
+
+	if outputPath == "" {
+		outputPath = js.defaultTrainingSetPath(jobName, archive)
+	}
+
+	excluded := make(map[string]bool)
+	for _, item := range js.GetPendingReviewItems() {
+		if item.JobName == jobName {
+			excluded[createItemKey(item.JobName, item.DatasetName, item.ImageName)] = true
+		}
+	}
+
+	jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, js.storage, jobName)
+	images := 0
+	for _, dataset := range jobData.Datasets {
+		for _, img := range dataset.Image {
+			if !excluded[createItemKey(jobName, dataset.Name, img.Name)] {
+				images++
+			}
+		}
+	}
+
+	return trainingSetExport{
+		job:        jobData,
+		outputPath: outputPath,
+		options: utils.TrainingSetOptions{
+			Formats:  formats,
+			Excluded: excluded,
+			Archive:  archive,
+		},
+		images: images,
+	}, nil
+}
+
+func (js *JointServices) defaultTrainingSetPath(jobName string, archive bool) string {
+	name := fmt.Sprintf("%s_%s", jobName, time.Now().Format("20060102_150405"))
+	if archive {
+		name += ".tar.gz"
+	}
+	return filepath.Join(js.backupDir, trainingSetExportDir, name)
+}
+
+// ReadDecisionRecords parses decisions written by WriteDecisionRecords. CSV input
+// must start with the header row; unknown columns are ignored.
+func ReadDecisionRecords(r io.Reader, format string) ([]models_verify_viewer.ReviewDecision, error) {
+	switch format {
+	case ExportFormatCSV:
+		return readDecisionsCSV(r)
+	case ExportFormatJSONL:
+		return readDecisionsJSONL(r)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+func readDecisionsCSV(r io.Reader) ([]models_verify_viewer.ReviewDecision, error) {
+	reader := csv.NewReader(r)
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %v", err)
+	}
+	if len(rows) == 0 {
+		return []models_verify_viewer.ReviewDecision{}, nil
+	}
+
+	columns := make(map[string]int, len(rows[0]))
+	for i, name := range rows[0] {
+		columns[name] = i
+	}
+	for _, required := range []string{"job", "dataset", "image", "decision"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing CSV column: %s", required)
+		}
+	}
+
+	column := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	records := make([]models_verify_viewer.ReviewDecision, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := models_verify_viewer.ReviewDecision{
+			JobName:     column(row, "job"),
+			DatasetName: column(row, "dataset"),
+			ImageName:   column(row, "image"),
+			Decision:    column(row, "decision"),
+			Reviewer:    column(row, "reviewer"),
+			Note:        column(row, "note"),
+		}
+		if decidedAt, err := time.Parse(time.RFC3339, column(row, "time")); err == nil {
+			record.DecidedAt = decidedAt
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func readDecisionsJSONL(r io.Reader) ([]models_verify_viewer.ReviewDecision, error) {
+	decoder := json.NewDecoder(r)
+	records := make([]models_verify_viewer.ReviewDecision, 0)
+	for {
+		var record models_verify_viewer.ReviewDecision
+		if err := decoder.Decode(&record); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read JSON Lines: %v", err)
+		}
+		records = append(records, record)
+	}
+}
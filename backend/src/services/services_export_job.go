@@ -0,0 +1,116 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// TrainingSetExportStatus is the progress of a background training set export.
+// Summary is set once the export has finished.
+type TrainingSetExportStatus struct {
+	models_verify_viewer.AnalysisJobStatus
+	Summary *utils.TrainingSetSummary `json:"summary,omitempty"`
+}
+
+// TrainingSetExports tracks the background training set export of each job and
+// keeps the summary of the latest one
+type TrainingSetExports struct {
+	*models_verify_viewer.AnalysisJobTracker
+
+	summaries map[string]utils.TrainingSetSummary
+	mu        sync.RWMutex
+}
+
+func NewTrainingSetExports() *TrainingSetExports {
+	return &TrainingSetExports{
+		AnalysisJobTracker: models_verify_viewer.NewAnalysisJobTracker("export"),
+		summaries:          make(map[string]utils.TrainingSetSummary),
+	}
+}
+
+func (exports *TrainingSetExports) setSummary(jobName string, summary *utils.TrainingSetSummary) {
+	exports.mu.Lock()
+	defer exports.mu.Unlock()
+
+	if summary == nil {
+		delete(exports.summaries, jobName)
+		return
+	}
+	exports.summaries[jobName] = *summary
+}
+
+func (exports *TrainingSetExports) status(jobName string) (TrainingSetExportStatus, error) {
+	status, err := exports.Status(jobName)
+	if err != nil {
+		return TrainingSetExportStatus{}, err
+	}
+
+	exports.mu.RLock()
+	defer exports.mu.RUnlock()
+
+	result := TrainingSetExportStatus{AnalysisJobStatus: status}
+	if summary, found := exports.summaries[jobName]; found && status.State != models_verify_viewer.AnalysisJobStateRunning {
+		result.Summary = &summary
+	}
+	return result, nil
+}
+
+// StartTrainingSetExport validates the export and writes it in the background
+// into <backup>/exports. Progress counts the copied images.
+func (js *JointServices) StartTrainingSetExport(ctx context.Context, jobName string, formats []string, archive bool) (TrainingSetExportStatus, error) {
+	export, err := js.prepareTrainingSetExport(ctx, jobName, formats, archive, "")
+	if err != nil {
+		return TrainingSetExportStatus{}, err
+	}
+
+	jobCtx, cancel := context.WithCancel(context.Background())
+	status, err := js.Exports.Begin(jobName, export.images, cancel)
+	if err != nil {
+		cancel()
+		return TrainingSetExportStatus{AnalysisJobStatus: status}, err
+	}
+	js.Exports.setSummary(jobName, nil)
+
+	export.options.Progress = func(failed bool) { js.Exports.Progress(jobName, false, failed) }
+	js.startAnalysis(func() { js.runTrainingSetExport(jobCtx, jobName, export) })
+
+	slog.Info("Training set export started", "job", jobName, "images", export.images, "output", export.outputPath)
+	return TrainingSetExportStatus{AnalysisJobStatus: status}, nil
+}
+
+func (js *JointServices) runTrainingSetExport(ctx context.Context, jobName string, export trainingSetExport) {
+	summary, err := utils.ExportTrainingSet(ctx, js.storage, export.job, export.outputPath, export.options)
+	js.Exports.setSummary(jobName, &summary)
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		js.Exports.End(jobName, models_verify_viewer.AnalysisJobStateCancelled, nil)
+		slog.Info("Training set export cancelled", "job", jobName, "output", export.outputPath)
+	case err != nil:
+		js.Exports.End(jobName, models_verify_viewer.AnalysisJobStateFailed, err)
+		slog.Error("Training set export failed", "job", jobName, "error", err)
+	default:
+		js.Exports.End(jobName, models_verify_viewer.AnalysisJobStateCompleted, nil)
+		slog.Info("Training set export completed", "job", jobName,
+			"images", summary.ImageCount, "output", summary.OutputPath)
+	}
+}
+
+// CancelTrainingSetExport stops the running training set export of a job
+func (js *JointServices) CancelTrainingSetExport(jobName string) error {
+	return js.Exports.Cancel(jobName)
+}
+
+// GetTrainingSetExportStatus returns the progress of the latest training set
+// export of a job, with its summary once finished
+func (js *JointServices) GetTrainingSetExportStatus(jobName string) (TrainingSetExportStatus, error) {
+	if !js.JobExists(jobName) {
+		return TrainingSetExportStatus{}, ErrJobNotFound
+	}
+
+	return js.Exports.status(jobName)
+}
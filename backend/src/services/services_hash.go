@@ -0,0 +1,196 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// DefaultDuplicateThreshold is the largest hash distance, in bits, between two
+// images treated as duplicates
+const DefaultDuplicateThreshold = 6
+
+// StartHashJob computes perceptual hashes for every image of a job in the
+// background. Images whose size and modification time did not change since
+// the previous run keep their stored hashes.
+func (js *JointServices) StartHashJob(ctx context.Context, jobName string) (models_verify_viewer.AnalysisJobStatus, error) {
+	if !js.JobExists(jobName) {
+		return models_verify_viewer.AnalysisJobStatus{}, ErrJobNotFound
+	}
+
+	backupDir := js.backupDir
+	if err := js.Hashes.LoadFromFile(backupDir, jobName); err != nil {
+		slog.Warn("Failed to load hashes", "job", jobName, "error", err)
+	}
+
+	tasks := js.listImageTasks(ctx, jobName)
+
+	jobCtx, cancel := context.WithCancel(context.Background())
+	status, err := js.Hashes.Begin(jobName, len(tasks), cancel)
+	if err != nil {
+		cancel()
+		return status, err
+	}
+
+	js.startAnalysis(func() { js.runHashJob(jobCtx, jobName, tasks) })
+
+	slog.Info("Hash job started", "job", jobName, "images", len(tasks))
+	return status, nil
+}
+
+func (js *JointServices) runHashJob(ctx context.Context, jobName string, tasks []imageTask) {
+	previous, _ := js.Hashes.Records(jobName)
+	known := make(map[string]models_verify_viewer.ImageHashRecord, len(previous))
+	for _, record := range previous {
+		known[record.Key()] = record
+	}
+
+	var mu sync.Mutex
+	records := make([]models_verify_viewer.ImageHashRecord, 0, len(tasks))
+	runImageTasks(ctx, tasks, func(task imageTask) {
+		record, reused := hashImage(ctx, js.storage, task, known)
+		js.Hashes.Progress(jobName, reused, record.Error != "")
+
+		mu.Lock()
+		records = append(records, record)
+		mu.Unlock()
+	})
+
+	if ctx.Err() != nil {
+		// Keep the previous hashes of images not reached so the next run can reuse them
+		done := make(map[string]bool, len(records))
+		for _, record := range records {
+			done[record.Key()] = true
+		}
+		for key, record := range known {
+			if !done[key] {
+				records = append(records, record)
+			}
+		}
+		js.Hashes.SetRecords(jobName, records)
+		js.Hashes.End(jobName, models_verify_viewer.AnalysisJobStateCancelled, nil)
+		slog.Info("Hash job cancelled", "job", jobName)
+	} else {
+		js.Hashes.SetRecords(jobName, records)
+		js.Hashes.End(jobName, models_verify_viewer.AnalysisJobStateCompleted, nil)
+		slog.Info("Hash job completed", "job", jobName, "images", len(records))
+	}
+
+	if err := js.Hashes.SaveToFile(js.backupDir, jobName); err != nil {
+		slog.Warn("Failed to save hashes", "job", jobName, "error", err)
+	}
+}
+
+// hashImage reuses the known record when the file is unchanged and hashes it otherwise
+func hashImage(ctx context.Context, store utils.Storage, task imageTask, known map[string]models_verify_viewer.ImageHashRecord) (models_verify_viewer.ImageHashRecord, bool) {
+	record := models_verify_viewer.ImageHashRecord{
+		DatasetName: task.datasetName,
+		ImageName:   task.imageName,
+	}
+
+	info, err := store.Stat(ctx, task.imagePath)
+	if err != nil {
+		record.Error = err.Error()
+		return record, false
+	}
+	record.Size = info.Size
+	record.ModTime = info.ModTime
+
+	if previous, found := known[record.Key()]; found && previous.Error == "" &&
+		previous.Size == record.Size && previous.ModTime.Equal(record.ModTime) {
+		return previous, true
+	}
+
+	hashes, err := utils.ComputePerceptualHashes(ctx, store, task.imagePath)
+	if err != nil {
+		record.Error = err.Error()
+		return record, false
+	}
+	record.AHash = models_verify_viewer.ImageHash(hashes.AHash)
+	record.DHash = models_verify_viewer.ImageHash(hashes.DHash)
+	record.PHash = models_verify_viewer.ImageHash(hashes.PHash)
+	return record, false
+}
+
+// CancelHashJob stops the running hash job of a job
+func (js *JointServices) CancelHashJob(jobName string) error {
+	return js.Hashes.Cancel(jobName)
+}
+
+// GetHashJobStatus returns the progress of the latest hash job of a job
+func (js *JointServices) GetHashJobStatus(jobName string) (models_verify_viewer.AnalysisJobStatus, error) {
+	if !js.JobExists(jobName) {
+		return models_verify_viewer.AnalysisJobStatus{}, ErrJobNotFound
+	}
+
+	return js.Hashes.Status(jobName)
+}
+
+// GetDuplicateClusters groups the images of a job by perceptual hash distance.
+// An empty algorithm means phash and a negative threshold the default.
+func (js *JointServices) GetDuplicateClusters(jobName, algorithm string, threshold int) ([]models_verify_viewer.DuplicateCluster, error) {
+	if !js.JobExists(jobName) {
+		return nil, ErrJobNotFound
+	}
+	if algorithm == "" {
+		algorithm = models_verify_viewer.HashAlgorithmPHash
+	}
+	if !models_verify_viewer.IsValidHashAlgorithm(algorithm) {
+		return nil, models_verify_viewer.ErrInvalidHashAlgorithm
+	}
+	if threshold < 0 {
+		threshold = DefaultDuplicateThreshold
+	}
+
+	if err := js.Hashes.LoadFromFile(js.backupDir, jobName); err != nil {
+		slog.Warn("Failed to load hashes", "job", jobName, "error", err)
+	}
+	clusters, found := js.Hashes.DuplicateClusters(jobName, algorithm, threshold)
+	if !found {
+		return nil, models_verify_viewer.ErrHashesNotComputed
+	}
+	return clusters, nil
+}
+
+// FlagDuplicates adds the redundant images of every duplicate cluster, all but
+// the suggested image to keep, to the pending review set
+func (js *JointServices) FlagDuplicates(jobName, algorithm string, threshold int, dryRun bool, note, user string) (BulkFlagResult, error) {
+	clusters, err := js.GetDuplicateClusters(jobName, algorithm, threshold)
+	if err != nil {
+		return BulkFlagResult{}, err
+	}
+
+	root := js.root
+	items := make([]models_verify_viewer.PendingReviewItem, 0)
+	for _, cluster := range clusters {
+		for _, redundant := range cluster.Redundant {
+			items = append(items, models_verify_viewer.PendingReviewItem{
+				JobName:     jobName,
+				DatasetName: redundant.DatasetName,
+				ImageName:   redundant.ImageName,
+				ImagePath:   buildImagePath(root, jobName, redundant.DatasetName, redundant.ImageName),
+			})
+		}
+	}
+
+	added, alreadyPending := js.newFlaggedItems(items, note, user)
+	result := BulkFlagResult{
+		JobName:        jobName,
+		DryRun:         dryRun,
+		MatchedCount:   len(items),
+		AlreadyPending: alreadyPending,
+		AddedCount:     len(added),
+		Sample:         items[:min(len(items), maxBulkFlagSample)],
+	}
+
+	if dryRun || len(added) == 0 {
+		return result, nil
+	}
+
+	js.addFlaggedItems(jobName, user, models_verify_viewer.AuditActionDuplicatesFlagged, added,
+		fmt.Sprintf("%d duplicates added from %d clusters", result.AddedCount, len(clusters)))
+	return result, nil
+}
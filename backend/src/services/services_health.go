@@ -0,0 +1,174 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+)
+
+const (
+	storeProbeTimeout  = 2 * time.Second
+	storeProbeInterval = 20 * time.Millisecond
+)
+
+var (
+	errJobWatcherStopped = errors.New("job watcher is not running")
+	errStoreUnresponsive = fmt.Errorf("pending review store did not respond within %v", storeProbeTimeout)
+)
+
+// CheckHealth probes the image root, the backup directory, the job watcher and
+// the review stores. The watcher and the in-memory store are critical: when they
+// fail the process has to be restarted, the rest may recover on their own.
+func (js *JointServices) CheckHealth(ctx context.Context) models_verify_viewer.HealthReport {
+	checks := []models_verify_viewer.HealthCheck{
+		runHealthCheck("image_root", false, func() error { return js.checkImageRoot(ctx) }),
+		runHealthCheck("backup_dir", false, js.checkBackupDir),
+		runHealthCheck("job_watcher", true, js.checkJobWatcher),
+		runHealthCheck("review_store", true, js.checkReviewStore),
+		runHealthCheck("audit_log", false, js.AuditLog.Ping),
+	}
+	return models_verify_viewer.NewHealthReport(checks)
+}
+
+func runHealthCheck(name string, critical bool, probe func() error) models_verify_viewer.HealthCheck {
+	start := time.Now()
+	err := probe()
+	return models_verify_viewer.NewHealthCheck(name, critical, start, err)
+}
+
+func (js *JointServices) checkImageRoot(ctx context.Context) error {
+	root := js.root
+	if root == "" {
+		return errors.New("image root is not configured")
+	}
+	if _, err := js.storage.List(ctx, root); err != nil {
+		return fmt.Errorf("image root is not readable: %v", err)
+	}
+	return nil
+}
+
+func (js *JointServices) checkBackupDir() error {
+	dir := js.backupDir
+	if dir == "" {
+		return errors.New("backup directory is not configured")
+	}
+
+	file, err := os.CreateTemp(dir, ".healthcheck-*")
+	if err != nil {
+		return fmt.Errorf("backup directory is not writable: %v", err)
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+func (js *JointServices) checkJobWatcher() error {
+	if !js.watcherRunning() {
+		return errJobWatcherStopped
+	}
+	return nil
+}
+
+// checkReviewStore fails when the pending review lock cannot be taken in time,
+// which is how a stuck writer shows up from the outside. The lock is polled
+// rather than waited on so a stuck probe never outlives the check.
+func (js *JointServices) checkReviewStore() error {
+	deadline := time.Now().Add(storeProbeTimeout)
+	for {
+		if _, ok := js.PendingReviewData.TryLen(); ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errStoreUnresponsive
+		}
+		time.Sleep(storeProbeInterval)
+	}
+}
+
+// DebugState is a point-in-time summary of the caches, review sessions and
+// background work of the running backend
+type DebugState struct {
+	Caches     DebugCacheState   `json:"caches"`
+	Sessions   DebugSessionState `json:"sessions"`
+	Tasks      DebugTaskState    `json:"tasks"`
+	Goroutines int               `json:"goroutines"`
+	CapturedAt time.Time         `json:"captured_at"`
+}
+
+type DebugCacheState struct {
+	Image  models_verify_viewer.CacheStoreStats `json:"image"`
+	Review models_verify_viewer.CacheStoreStats `json:"review"`
+}
+
+type DebugSessionState struct {
+	CurrentJob             string                                    `json:"current_job"`
+	Pages                  int                                       `json:"pages"`
+	SampleID               string                                    `json:"sample_id,omitempty"`
+	SearchActive           bool                                      `json:"search_active"`
+	Jobs                   int                                       `json:"jobs"`
+	PendingReviewItems     int                                       `json:"pending_review_items"`
+	PendingDeletionBatches int                                       `json:"pending_deletion_batches"`
+	BlindReviews           []models_verify_viewer.BlindReviewSummary `json:"blind_reviews"`
+	DatasetLocks           []models_verify_viewer.DatasetLock        `json:"dataset_locks"`
+}
+
+type DebugTaskState struct {
+	Running        int                                      `json:"running"`
+	MaxSlots       int                                      `json:"max_slots"`
+	Slots          []string                                 `json:"slots"`
+	PagesInFlight  int                                      `json:"pages_in_flight"`
+	HashJobs       []models_verify_viewer.AnalysisJobStatus `json:"hash_jobs"`
+	QualityChecks  []models_verify_viewer.AnalysisJobStatus `json:"quality_checks"`
+	Exports        []models_verify_viewer.AnalysisJobStatus `json:"training_set_exports"`
+	WatcherRunning bool                                     `json:"watcher_running"`
+}
+
+// BuildDebugState gathers the current state of both service halves
+func BuildDebugState(us *UserServices, js *JointServices) DebugState {
+	_, searchActive := us.CurrentPageData.SearchQuery()
+	running, maxSlots, slots := utils.GetTaskStatus()
+
+	return DebugState{
+		Caches: DebugCacheState{
+			Image:  us.CacheManager.ImageCacheStats(),
+			Review: us.CacheManager.ReviewCacheStats(),
+		},
+		Sessions: DebugSessionState{
+			CurrentJob:             us.CurrentPageData.JobName(),
+			Pages:                  us.CurrentPageData.Len(),
+			SampleID:               us.CurrentPageData.SampleID(),
+			SearchActive:           searchActive,
+			Jobs:                   len(js.JobList.Jobs()),
+			PendingReviewItems:     js.PendingReviewData.Len(),
+			PendingDeletionBatches: len(js.DeletionQueue.List(models_verify_viewer.DeletionStatusPending)),
+			BlindReviews:           js.BlindReviews.List(""),
+			DatasetLocks:           js.AssignmentBoard.Locks(""),
+		},
+		Tasks: DebugTaskState{
+			Running:        running,
+			MaxSlots:       maxSlots,
+			Slots:          slots,
+			PagesInFlight:  us.pagesInFlight(),
+			HashJobs:       js.Hashes.Running(),
+			QualityChecks:  js.Quality.Running(),
+			Exports:        js.Exports.Running(),
+			WatcherRunning: js.watcherRunning(),
+		},
+		Goroutines: runtime.NumGoroutine(),
+		CapturedAt: time.Now(),
+	}
+}
+
+// pagesInFlight counts the pages currently being compressed
+func (us *UserServices) pagesInFlight() int {
+	count := 0
+	us.processingLocks.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+	return count
+}
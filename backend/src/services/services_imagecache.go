@@ -3,8 +3,9 @@ package services
 import (
 	"backend/src/models_verify_viewer"
 	"backend/src/utils"
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"sync"
 )
@@ -12,48 +13,46 @@ import (
 func (us *UserServices) SetBase64ImageCache(jobName string) {
 	if !us.CacheManager.ExistsImageCacheStore(jobName) {
 		us.CacheManager.SetImageCacheStore(jobName)
-		log.Println("Image cache store created for job:", jobName)
+		slog.Debug("Image cache store created", "job", jobName)
 	} else {
-		log.Println("Image cache store already exists for job:", jobName)
+		slog.Debug("Image cache store already exists", "job", jobName)
 	}
 }
 
-func (us *UserServices) GetBase64ImageCacheByPage(jobName string, pageIndex int) ([]string, []string) {
-	log.Printf("[GetBase64ImageCacheByPage] START - job: %s, page: %d", jobName, pageIndex)
-
+// GetBase64ImageCacheByPage returns the cached images of a page. Images missing
+// from the cache are reported as missing.
+func (us *UserServices) GetBase64ImageCacheByPage(jobName string, pageIndex int) []models_verify_viewer.ImageResult {
 	imageCache := us.getOrCreateImageCache(jobName)
 	if imageCache == nil {
-		log.Printf("[GetBase64ImageCacheByPage] ERROR - imageCache is nil for job: %s", jobName)
-		return nil, nil
+		slog.Error("Image cache is missing", "job", jobName, "page", pageIndex)
+		return nil
 	}
 
 	// Periodic cleanup of empty entries
 	imageCache.CleanupEmpty()
 
 	imagePaths := us.CurrentPageData.ImagePathsAt(pageIndex)
-	log.Printf("[GetBase64ImageCacheByPage] Retrieved %d image paths from CurrentPageData for job: %s, page: %d", len(imagePaths), jobName, pageIndex)
-	if len(imagePaths) > 0 {
-		log.Printf("[GetBase64ImageCacheByPage] First image path: %s", imagePaths[0])
-	}
+	slog.Debug("Reading page images from cache", "job", jobName, "page", pageIndex, "images", len(imagePaths))
 
 	base64Images := imageCache.GetBatch(imagePaths)
-	log.Printf("[GetBase64ImageCacheByPage] Retrieved %d base64 images from cache", len(base64Images))
-
-	// Check for empty base64 strings
-	emptyCount := 0
-	for i, b64 := range base64Images {
-		if b64 == "" {
-			emptyCount++
-			if i < 3 { // Log first 3 empty entries
-				log.Printf("[GetBase64ImageCacheByPage] WARNING: Empty base64 at index %d for path: %s", i, imagePaths[i])
-			}
+	results := make([]models_verify_viewer.ImageResult, len(imagePaths))
+	missingCount := 0
+	for i, imagePath := range imagePaths {
+		results[i] = models_verify_viewer.ImageResult{
+			ImagePath: imagePath,
+			Status:    models_verify_viewer.ImageStatusOK,
+			Base64:    base64Images[i],
+		}
+		if base64Images[i] == "" {
+			results[i].Status = models_verify_viewer.ImageStatusMissing
+			results[i].Error = "image is not in the cache"
+			missingCount++
 		}
 	}
-	if emptyCount > 0 {
-		log.Printf("[GetBase64ImageCacheByPage] WARNING: Found %d empty base64 strings out of %d total", emptyCount, len(base64Images))
-	}
+	utils.RecordCacheLookups(utils.CacheStoreImage, len(results)-missingCount, missingCount)
 
-	return imagePaths, base64Images
+	logFailedImages(jobName, pageIndex, results)
+	return results
 }
 
 func (us *UserServices) getOrCreateImageCache(jobName string) *models_verify_viewer.Base64ImageCache {
@@ -65,7 +64,7 @@ func (us *UserServices) getOrCreateImageCache(jobName string) *models_verify_vie
 	us.CacheManager.SetImageCacheStore(jobName)
 	imageCache, exist = us.CacheManager.GetImageCacheStore(jobName)
 	if !exist {
-		log.Println("Failed to create image cache store for job:", jobName)
+		slog.Error("Failed to create image cache store", "job", jobName)
 		return nil
 	}
 
@@ -75,7 +74,7 @@ func (us *UserServices) getOrCreateImageCache(jobName string) *models_verify_vie
 func (us *UserServices) GetBase64ImageByPath(jobName string, imagePath string) string {
 	imageCache, exist := us.CacheManager.GetImageCacheStore(jobName)
 	if !exist {
-		log.Println("Image cache store not found for job:", jobName)
+		slog.Debug("Image cache store not found", "job", jobName)
 		return ""
 	}
 
@@ -83,7 +82,10 @@ func (us *UserServices) GetBase64ImageByPath(jobName string, imagePath string) s
 	return base64
 }
 
-func (us *UserServices) SetBase64ImageCacheByPage(jobName string, pageIndex int) ([]string, []string) {
+// SetBase64ImageCacheByPage compresses the images of a page missing from the
+// cache and caches the ones that succeeded. It returns nil when the page no
+// longer belongs to the job.
+func (us *UserServices) SetBase64ImageCacheByPage(jobName string, pageIndex int) []models_verify_viewer.ImageResult {
 	// Create unique key for this page
 	lockKey := fmt.Sprintf("%s::%d", jobName, pageIndex)
 
@@ -97,81 +99,101 @@ func (us *UserServices) SetBase64ImageCacheByPage(jobName string, pageIndex int)
 
 	// Double-check cache after acquiring lock (another request may have completed)
 	if us.ImageCacheExists(jobName, pageIndex) {
-		log.Printf("[Cache] Images already cached while waiting for lock: %s, page: %d", jobName, pageIndex)
+		slog.Debug("Images cached while waiting for page lock", "job", jobName, "page", pageIndex)
 		return us.GetBase64ImageCacheByPage(jobName, pageIndex)
 	}
 
 	us.ensureImageCacheExists(jobName)
 
-	log.Printf("[SetBase64ImageCacheByPage] START - job: %s, page: %d", jobName, pageIndex)
-
 	// CRITICAL: Verify CurrentPageData matches the requested job
 	currentJobName := us.CurrentPageData.JobName()
 	if currentJobName != jobName {
-		log.Printf("[SetBase64ImageCacheByPage] ERROR - Job mismatch! Requested: %s, CurrentPageData: %s. Job was switched during processing.", jobName, currentJobName)
-		return nil, nil // Return nil to indicate error
+		slog.Warn("Job was switched during processing", "job", jobName, "page", pageIndex, "current_job", currentJobName)
+		return nil // Return nil to indicate error
 	}
 
 	imagePaths := us.CurrentPageData.ImagePathsAt(pageIndex)
-	log.Printf("[SetBase64ImageCacheByPage] Retrieved %d image paths for job: %s, page: %d", len(imagePaths), jobName, pageIndex)
+	slog.Debug("Compressing page images", "job", jobName, "page", pageIndex, "images", len(imagePaths))
 	if len(imagePaths) > 0 {
-		log.Printf("[SetBase64ImageCacheByPage] First image path: %s", imagePaths[0])
-
 		// Double-check: verify first path contains job name as additional safety
 		if !strings.Contains(imagePaths[0], jobName) {
-			log.Printf("[SetBase64ImageCacheByPage] ERROR - Path validation failed! Path '%s' doesn't contain job name '%s'", imagePaths[0], jobName)
-			return nil, nil
+			slog.Error("Page image path does not belong to job", "job", jobName, "page", pageIndex, "path", imagePaths[0])
+			return nil
 		}
 	}
 
-	base64Images := utils.CompressImageSetToBase64(imagePaths, pageIndex)
-	log.Printf("[SetBase64ImageCacheByPage] Compressed %d images, result count: %d", len(imagePaths), len(base64Images))
-
-	// Check if processing was cancelled or failed (all empty results)
-	if isResultSetEmpty(base64Images) {
-		log.Printf("[Cache] Task was cancelled or failed for job: %s, page: %d - returning nil to trigger retry", jobName, pageIndex)
-		return nil, nil // Return nil to signal handler that request should fail/retry
+	cacheData, found := us.CacheManager.GetImageCacheStore(jobName)
+	if !found {
+		slog.Warn("Image cache store not found", "job", jobName)
+		utils.RecordCacheLookups(utils.CacheStoreImage, 0, len(imagePaths))
+		results := utils.CompressImageSet(us.storage, us.taskScope(jobName), imagePaths, pageIndex)
+		logFailedImages(jobName, pageIndex, results)
+		return results
 	}
 
-	// Check for individual empty base64 strings
-	emptyCount := 0
-	for i, b64 := range base64Images {
-		if b64 == "" {
-			emptyCount++
-			if i < 3 { // Log first 3 empty entries
-				log.Printf("[SetBase64ImageCacheByPage] WARNING: Empty base64 at index %d for path: %s", i, imagePaths[i])
-			}
+	// Only images missing from the cache are compressed, so an image that keeps
+	// failing does not recompress the rest of its page on every view
+	results := make([]models_verify_viewer.ImageResult, len(imagePaths))
+	missingPaths := make([]string, 0, len(imagePaths))
+	missingIndexes := make([]int, 0, len(imagePaths))
+	for i, base64 := range cacheData.GetBatch(imagePaths) {
+		if base64 == "" {
+			missingPaths = append(missingPaths, imagePaths[i])
+			missingIndexes = append(missingIndexes, i)
+			continue
+		}
+		results[i] = models_verify_viewer.ImageResult{
+			ImagePath: imagePaths[i],
+			Status:    models_verify_viewer.ImageStatusOK,
+			Base64:    base64,
 		}
 	}
-	if emptyCount > 0 {
-		log.Printf("[SetBase64ImageCacheByPage] WARNING: Found %d empty base64 strings out of %d total for job: %s, page: %d", emptyCount, len(base64Images), jobName, pageIndex)
-	}
+	utils.RecordCacheLookups(utils.CacheStoreImage, len(imagePaths)-len(missingPaths), len(missingPaths))
 
-	cacheData, found := us.CacheManager.GetImageCacheStore(jobName)
-	if !found {
-		log.Println("Image cache store not found for job:", jobName)
-		return nil, nil
+	compressed := utils.CompressImageSet(us.storage, us.taskScope(jobName), missingPaths, pageIndex)
+	for i, result := range compressed {
+		results[missingIndexes[i]] = result
 	}
-
-	cacheData.SetBatch(imagePaths, base64Images)
-	log.Printf("[Cache] Successfully cached %d images for job: %s, page: %d (with %d empty strings)", len(imagePaths), jobName, pageIndex, emptyCount)
-	return imagePaths, base64Images
+	failedCount := logFailedImages(jobName, pageIndex, results)
+
+	// Only successful images are cached so failed ones are retried on the next request
+	cachedPaths := make([]string, 0, len(compressed))
+	cachedImages := make([]string, 0, len(compressed))
+	for _, result := range compressed {
+		if result.OK() {
+			cachedPaths = append(cachedPaths, result.ImagePath)
+			cachedImages = append(cachedImages, result.Base64)
+		}
+	}
+	cacheData.SetBatch(cachedPaths, cachedImages)
+	slog.Debug("Cached page images", "job", jobName, "page", pageIndex,
+		"reused", len(imagePaths)-len(missingPaths), "cached", len(cachedPaths), "failed", failedCount)
+	return results
 }
 
-// isResultSetEmpty checks if all images in the result are empty (cancelled/failed)
-func isResultSetEmpty(base64Images []string) bool {
-	if len(base64Images) == 0 {
-		return true
-	}
+// taskScope names the page list of a job for the compression tasks, which are
+// shared by every workspace
+func (us *UserServices) taskScope(jobName string) string {
+	return us.workspace + "/" + jobName
+}
 
-	// Check if all images are empty
-	for _, img := range base64Images {
-		if img != "" {
-			return false
+// logFailedImages logs the first few failed images of a page and returns the failure count
+func logFailedImages(jobName string, pageIndex int, results []models_verify_viewer.ImageResult) int {
+	failedCount := 0
+	for _, result := range results {
+		if result.OK() {
+			continue
+		}
+		failedCount++
+		if failedCount <= 3 { // Log first 3 failed entries
+			slog.Warn("Page image failed", "job", jobName, "page", pageIndex,
+				"path", result.ImagePath, "status", result.Status, "error", result.Error)
 		}
 	}
-
-	return true
+	if failedCount > 0 {
+		slog.Warn("Page images failed", "job", jobName, "page", pageIndex, "failed", failedCount, "total", len(results))
+	}
+	return failedCount
 }
 
 func (us *UserServices) ensureImageCacheExists(jobName string) {
@@ -183,7 +205,7 @@ func (us *UserServices) ensureImageCacheExists(jobName string) {
 func (us *UserServices) ImageCacheExists(jobName string, pageIndex int) bool {
 	data, found := us.CacheManager.GetImageCacheStore(jobName)
 	if !found {
-		log.Println("Image cache store not found for job:", jobName)
+		slog.Debug("Image cache store not found", "job", jobName)
 		return false
 	}
 
@@ -203,15 +225,15 @@ func (us *UserServices) allImagesAreCached(data *models_verify_viewer.Base64Imag
 func (us *UserServices) isImageCached(data *models_verify_viewer.Base64ImageCache, imagePath, jobName string) bool {
 	base64Image, found := data.Get(imagePath)
 	if !found || base64Image == "" {
-		log.Printf("Image path %s not found in cache for job %s", imagePath, jobName)
+		slog.Debug("Image not found in cache", "job", jobName, "path", imagePath)
 		return false
 	}
 	return true
 }
 
 // GetOriginalImageBase64 returns the original, uncompressed image in base64 format
-func (us *UserServices) GetOriginalImageBase64(imagePath string) (string, error) {
-	return utils.ImageToBase64(imagePath)
+func (us *UserServices) GetOriginalImageBase64(ctx context.Context, imagePath string) (string, error) {
+	return utils.ImageToBase64(ctx, us.storage, imagePath)
 }
 
 // RemoveImagesFromCache removes deleted images from the image cache for a specific job
@@ -222,7 +244,7 @@ func (us *UserServices) RemoveImagesFromCache(jobName string, imagePaths []strin
 
 	imageCache, found := us.CacheManager.GetImageCacheStore(jobName)
 	if !found {
-		log.Printf("Image cache not found for job %s, skipping cache cleanup", jobName)
+		slog.Debug("Image cache not found, skipping cache cleanup", "job", jobName)
 		return 0
 	}
 
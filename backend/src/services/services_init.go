@@ -4,114 +4,171 @@ import (
 	"backend/src/models_verify_viewer"
 	"backend/src/utils"
 	"context"
-	"log"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 )
 
-var (
-	configOnce sync.Once
-	imageRoot  string
-	backupDir  string
-)
-
-func SetConfig(root, backup string) {
-	configOnce.Do(func() {
-		imageRoot = root
-		backupDir = backup
-		log.Printf("Configuration set - ImageRoot: %s, BackupDir: %s", root, backup)
-	})
+type JointServices struct {
+	JobList           *models_verify_viewer.JobList
+	PendingReviewData *models_verify_viewer.PendingReview
+	AuditLog          *models_verify_viewer.AuditLog
+	DeletionQueue     *models_verify_viewer.DeletionQueue
+	AssignmentBoard   *models_verify_viewer.AssignmentBoard
+	BlindReviews      *models_verify_viewer.BlindReviewStore
+	ReviewProgress    *models_verify_viewer.ProgressTracker
+	Samples           *models_verify_viewer.SampleStore
+	Hashes            *models_verify_viewer.HashStore
+	Quality           *models_verify_viewer.QualityStore
+	Exports           *TrainingSetExports
+
+	storage             utils.Storage
+	root                string
+	backupDir           string
+	watcher             *utils.JobWatcher
+	deletionApproval    atomic.Bool
+	progressPersistedAt atomic.Int64
+	analyses            sync.WaitGroup
 }
 
-func GetImageRoot() string {
-	return imageRoot
-}
+func NewJointServices(ctx context.Context, store utils.Storage, backupDir string) *JointServices {
+	js := newJointServices(store, backupDir)
+	js.watcher = utils.ConcurrentJobScanner(ctx, store, js.JobList)
 
-func GetBackupDir() string {
-	return backupDir
+	js.restoreState()
+	return js
 }
 
-type JointServices struct {
-	JobList           *models_verify_viewer.JobList
-	PendingReviewData *models_verify_viewer.PendingReview
+// NewOfflineJointServices builds joint services for command line use: the job
+// list is scanned once up front and no file system watcher is started.
+func NewOfflineJointServices(ctx context.Context, store utils.Storage, backupDir string) *JointServices {
+	js := newJointServices(store, backupDir)
+	if jobs, err := utils.ScanJobNames(ctx, store); err == nil {
+		js.JobList.Replace(jobs)
+	}
+
+	js.restoreState()
+	return js
 }
 
-func NewJointServices(ctx context.Context) *JointServices {
-	js := &JointServices{
+func newJointServices(store utils.Storage, backupDir string) *JointServices {
+	return &JointServices{
 		JobList:           models_verify_viewer.NewJobList(),
 		PendingReviewData: models_verify_viewer.NewPendingReview(),
+		AuditLog:          openAuditLog(backupDir),
+		DeletionQueue:     models_verify_viewer.NewDeletionQueue(),
+		AssignmentBoard:   models_verify_viewer.NewAssignmentBoard(),
+		BlindReviews:      models_verify_viewer.NewBlindReviewStore(),
+		ReviewProgress:    models_verify_viewer.NewProgressTracker(),
+		Samples:           models_verify_viewer.NewSampleStore(),
+		Hashes:            models_verify_viewer.NewHashStore(),
+		Quality:           models_verify_viewer.NewQualityStore(),
+		Exports:           NewTrainingSetExports(),
+		storage:           store,
+		root:              store.Root(),
+		backupDir:         backupDir,
 	}
+}
+
+// ImageRoot returns the directory the jobs of these services are read from
+func (js *JointServices) ImageRoot() string {
+	return js.root
+}
 
-	imageRoot := GetImageRoot()
-	utils.ConcurrentJobScanner(ctx, imageRoot, js.JobList)
+// Storage returns where the images and labels of these services are stored
+func (js *JointServices) Storage() utils.Storage {
+	return js.storage
+}
 
+// BackupDir returns the directory backups and review state are written to
+func (js *JointServices) BackupDir() string {
+	return js.backupDir
+}
+
+// watcherRunning reports whether the job list is still kept in sync with disk.
+// Offline services never start a watcher.
+func (js *JointServices) watcherRunning() bool {
+	return js.watcher != nil && js.watcher.Running()
+}
+
+func (js *JointServices) restoreState() {
 	js.autoRestoreLatestBackup()
-	return js
+	js.restoreDeletionQueue()
+	js.restoreAssignmentBoard()
+	js.restoreBlindReviews()
+	js.restoreReviewProgress()
+	js.restoreSamples()
 }
 
 type UserServices struct {
 	CacheManager    *models_verify_viewer.CacheManager
 	CurrentPageData *models_verify_viewer.Pages
 	processingLocks sync.Map // Per-page locks to prevent duplicate processing
+
+	workspace string
+	storage   utils.Storage
 }
 
-func NewUserServices() *UserServices {
+func NewUserServices(workspace string, store utils.Storage, limits models_verify_viewer.CacheLimits) *UserServices {
 	return &UserServices{
-		CacheManager:    models_verify_viewer.NewCacheManager(),
+		CacheManager:    models_verify_viewer.NewCacheManagerWithLimits(limits),
 		CurrentPageData: models_verify_viewer.NewPages(),
+		workspace:       workspace,
+		storage:         store,
 	}
 }
 
 func (js *JointServices) autoRestoreLatestBackup() {
-	backupDir := GetBackupDir()
+	backupDir := js.backupDir
 	latestBackup, err := js.PendingReviewData.GetLatestBackup(backupDir)
 	if err != nil {
-		log.Printf("No backup found to restore on startup (this is normal for first run): %v", err)
+		slog.Info("No backup found to restore on startup (this is normal for first run)", "error", err)
 		return
 	}
-	log.Printf("Found latest backup: %s, restoring...", latestBackup)
+	slog.Info("Restoring latest backup", "backup", latestBackup)
 
 	err = js.PendingReviewData.RestoreFromBackup(backupDir, latestBackup)
 	if err != nil {
-		log.Printf("Failed to restore from backup on startup: %v", err)
+		slog.Error("Failed to restore from backup on startup", "backup", latestBackup, "error", err)
 		return
 	}
 
 	itemCount := js.PendingReviewData.Len()
 	if itemCount == 0 {
-		log.Printf("Restored backup %s, but it contains 0 items (empty backup)", latestBackup)
+		slog.Info("Restored backup contains no items", "backup", latestBackup)
 	} else {
-		log.Printf("Successfully restored %d items from backup: %s", itemCount, latestBackup)
+		slog.Info("Restored items from backup", "backup", latestBackup, "items", itemCount)
 	}
 }
 
 func CheckServicesState(us *UserServices, js *JointServices) {
-	validateConfiguration()
+	validateConfiguration(js)
 	logServiceInitialization(us, js)
-	log.Println("Services state check completed")
+	slog.Debug("Services state check completed")
 }
 
-func validateConfiguration() {
-	if GetImageRoot() == "" {
-		log.Println("WARNING: ImageRoot is not set")
+func validateConfiguration(js *JointServices) {
+	if js.root == "" {
+		slog.Warn("ImageRoot is not set")
 	}
-	if GetBackupDir() == "" {
-		log.Println("WARNING: BackupDir is not set")
+	if js.backupDir == "" {
+		slog.Warn("BackupDir is not set")
 	}
 }
 
 func logServiceInitialization(us *UserServices, js *JointServices) {
 	jobs := js.JobList.Jobs()
 	if jobs == nil {
-		log.Println("INFO: JobList initialized")
+		slog.Debug("JobList initialized")
 	}
 	items := js.PendingReviewData.Items()
 	if items == nil {
-		log.Println("INFO: PendingReviewData initialized")
+		slog.Debug("PendingReviewData initialized")
 	}
 	if us.CacheManager == nil {
-		log.Println("INFO: CacheManager initialized")
+		slog.Debug("CacheManager initialized")
 	}
 	if us.CurrentPageData.Len() == 0 {
-		log.Println("INFO: CurrentPageData initialized")
+		slog.Debug("CurrentPageData initialized")
 	}
 }
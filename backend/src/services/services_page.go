@@ -3,20 +3,62 @@ package services
 import (
 	"backend/src/models_verify_viewer"
 	"backend/src/utils"
-	"log"
+	"context"
+	"log/slog"
 )
 
-func (us *UserServices) SetCurrentPageData(jobName string, pageSize int) {
-	log.Printf("[SetCurrentPageData] START - job: %s, pageSize: %d", jobName, pageSize)
-	log.Printf("[SetCurrentPageData] BEFORE - CurrentPageData.JobName: %s, PageItems count: %d", us.CurrentPageData.JobName(), us.CurrentPageData.Len())
+// SetCurrentPageData builds the pages of a job. With a sample only the sampled
+// images are paged.
+func (us *UserServices) SetCurrentPageData(ctx context.Context, jobName string, pageSize int, layout models_verify_viewer.PageLayout, sample *models_verify_viewer.SampleRecord) {
+	slog.Debug("Setting current page data", "job", jobName, "page_size", pageSize, "layout", layout,
+		"previous_job", us.CurrentPageData.JobName(), "previous_pages", us.CurrentPageData.Len())
 
-	root := GetImageRoot()
-	jobData, _ := utils.ConcurrentJobDetailsScanner(root, jobName)
+	jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, us.storage, jobName)
 	us.FillJobNameToCurrentPageData(jobData.Name)
+	us.CurrentPageData.SetLayout(layout)
 
-	log.Printf("[SetCurrentPageData] Scanned job: %s with %d datasets", jobData.Name, len(jobData.Datasets))
+	slog.Debug("Scanned job", "job", jobData.Name, "datasets", len(jobData.Datasets))
 
+	if sample != nil {
+		jobData = filterSampledImages(jobData, sample.ImageKeys())
+		us.CurrentPageData.SetSampleID(sample.ID)
+		slog.Debug("Paging sampled images", "job", jobName, "sample_id", sample.ID, "images", len(sample.Items))
+	}
+
+	if layout.Mode == models_verify_viewer.PageModePacked {
+		us.addPackedPages(ctx, jobData, pageSize, layout.Sort)
+	} else {
+		us.addDatasetPages(ctx, jobData, pageSize, layout.Sort)
+	}
+
+	slog.Info("Current page data set", "job", us.CurrentPageData.JobName(), "pages", us.CurrentPageData.Len())
+}
+
+// filterSampledImages keeps only the images whose "dataset|image" key is in the sample
+func filterSampledImages(jobData models_verify_viewer.Job, keys map[string]bool) models_verify_viewer.Job {
+	filtered := models_verify_viewer.Job{
+		Name:     jobData.Name,
+		Datasets: make([]models_verify_viewer.Dataset, 0, len(jobData.Datasets)),
+	}
+	for _, dataset := range jobData.Datasets {
+		images := make([]models_verify_viewer.Image, 0)
+		for _, image := range dataset.Image {
+			if keys[dataset.Name+"|"+image.Name] {
+				images = append(images, image)
+			}
+		}
+		if len(images) > 0 {
+			dataset.Image = images
+			filtered.Datasets = append(filtered.Datasets, dataset)
+		}
+	}
+	return filtered
+}
+
+// addDatasetPages chunks every dataset separately, sorting within the dataset
+func (us *UserServices) addDatasetPages(ctx context.Context, jobData models_verify_viewer.Job, pageSize int, order models_verify_viewer.ImageSort) {
 	for _, dataset := range jobData.Datasets {
+		utils.SortDatasetImages(ctx, us.storage, &dataset, order)
 		imageCount := dataset.GetImageLength()
 		for i := 0; i < imageCount; i += pageSize {
 			end := i + pageSize
@@ -26,8 +68,15 @@ func (us *UserServices) SetCurrentPageData(jobName string, pageSize int) {
 			us.AddPageToCurrentPageData(dataset.Name, dataset.Image[i:end])
 		}
 	}
+}
 
-	log.Printf("[SetCurrentPageData] AFTER - CurrentPageData.JobName: %s, PageItems count: %d", us.CurrentPageData.JobName(), us.CurrentPageData.Len())
+// addPackedPages sorts the whole job as one list and fills every page but the last
+func (us *UserServices) addPackedPages(ctx context.Context, jobData models_verify_viewer.Job, pageSize int, order models_verify_viewer.ImageSort) {
+	images, datasetNames := utils.SortJobImages(ctx, us.storage, jobData, order)
+	for i := 0; i < len(images); i += pageSize {
+		end := min(i+pageSize, len(images))
+		us.CurrentPageData.AddPackedPage(images[i:end], datasetNames[i:end])
+	}
 }
 
 func (us *UserServices) AddPageToCurrentPageData(datasetName string, datasetImage []models_verify_viewer.Image) {
@@ -40,7 +89,7 @@ func (us *UserServices) FillJobNameToCurrentPageData(jobName string) {
 
 func (us *UserServices) GetCurrentPageData(jobName string) (*models_verify_viewer.Pages, bool) {
 	if !us.currentPageDataExists(jobName) {
-		log.Println("CurrentPageData does not exist for job:", jobName)
+		slog.Debug("Current page data does not exist", "job", jobName)
 		return nil, false
 	}
 	return us.CurrentPageData, true
@@ -58,6 +107,13 @@ func (us *UserServices) CurrentPageDataExists(jobName string) bool {
 	return us.currentPageDataExists(jobName)
 }
 
+// FullPageDataExists reports whether the current pages cover the whole job (not a
+// search) with the given layout
+func (us *UserServices) FullPageDataExists(jobName string, layout models_verify_viewer.PageLayout) bool {
+	_, isSearch := us.CurrentPageData.SearchQuery()
+	return us.currentPageDataExists(jobName) && !isSearch && us.CurrentPageData.Layout() == layout
+}
+
 func (us *UserServices) currentPageDataExists(jobName string) bool {
 	if us.CurrentPageData.JobName() == jobName && us.CurrentPageData.Len() > 0 {
 		return true
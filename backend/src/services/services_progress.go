@@ -0,0 +1,63 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// progressPersistInterval throttles how often page views are written to disk
+const progressPersistInterval = 30 * time.Second
+
+func (js *JointServices) restoreReviewProgress() {
+	if err := js.ReviewProgress.LoadFromFile(js.backupDir); err != nil {
+		slog.Warn("Failed to load review progress", "error", err)
+	}
+}
+
+// FlushReviewProgress writes review progress to disk immediately
+func (js *JointServices) FlushReviewProgress() {
+	js.progressPersistedAt.Store(time.Now().UnixNano())
+	if err := js.ReviewProgress.SaveToFile(js.backupDir); err != nil {
+		slog.Warn("Failed to save review progress", "error", err)
+	}
+}
+
+func (js *JointServices) persistReviewProgressThrottled() {
+	last := time.Unix(0, js.progressPersistedAt.Load())
+	if time.Since(last) < progressPersistInterval {
+		return
+	}
+	js.FlushReviewProgress()
+}
+
+// RecordPageView marks the images of a page as seen by the reviewer
+func (js *JointServices) RecordPageView(user, jobName string, page models_verify_viewer.PageItem) {
+	js.ReviewProgress.RecordPageView(jobName, user, page)
+	js.persistReviewProgressThrottled()
+}
+
+func (js *JointServices) recordDecisions(user string, items []models_verify_viewer.PendingReviewItem) {
+	if len(items) == 0 {
+		return
+	}
+	js.ReviewProgress.RecordDecisions(user, items)
+	js.FlushReviewProgress()
+}
+
+// GetReviewProgress reports images seen, images decided and time spent per dataset and reviewer
+func (js *JointServices) GetReviewProgress(ctx context.Context, jobName, datasetName, reviewer string) (models_verify_viewer.ProgressReport, error) {
+	if !js.JobExists(jobName) {
+		return models_verify_viewer.ProgressReport{}, ErrJobNotFound
+	}
+
+	jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, js.storage, jobName)
+	datasetSizes := make(map[string]int, len(jobData.Datasets))
+	for _, dataset := range jobData.Datasets {
+		datasetSizes[dataset.Name] = dataset.GetImageLength()
+	}
+
+	return js.ReviewProgress.Report(jobName, datasetSizes, datasetName, reviewer), nil
+}
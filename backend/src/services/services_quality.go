@@ -0,0 +1,171 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+var ErrInvalidQualityThresholds = errors.New("invalid quality thresholds")
+
+// StartQualityCheck analyzes every image of a job in the background for decode
+// errors, blur, exposure and resolution. Unchanged images keep their metrics but
+// are flagged again under the new thresholds.
+func (js *JointServices) StartQualityCheck(ctx context.Context, jobName string, thresholds models_verify_viewer.QualityThresholds) (models_verify_viewer.AnalysisJobStatus, error) {
+	if !js.JobExists(jobName) {
+		return models_verify_viewer.AnalysisJobStatus{}, ErrJobNotFound
+	}
+
+	thresholds = thresholds.Normalize()
+	if err := thresholds.Validate(); err != nil {
+		return models_verify_viewer.AnalysisJobStatus{}, fmt.Errorf("%w: %v", ErrInvalidQualityThresholds, err)
+	}
+
+	if err := js.Quality.LoadFromFile(js.backupDir, jobName); err != nil {
+		slog.Warn("Failed to load quality records", "job", jobName, "error", err)
+	}
+
+	tasks := js.listImageTasks(ctx, jobName)
+
+	jobCtx, cancel := context.WithCancel(context.Background())
+	status, err := js.Quality.Begin(jobName, len(tasks), cancel)
+	if err != nil {
+		cancel()
+		return status, err
+	}
+
+	js.startAnalysis(func() { js.runQualityCheck(jobCtx, jobName, thresholds, tasks) })
+
+	slog.Info("Quality check started", "job", jobName, "images", len(tasks))
+	return status, nil
+}
+
+func (js *JointServices) runQualityCheck(ctx context.Context, jobName string, thresholds models_verify_viewer.QualityThresholds, tasks []imageTask) {
+	previous, _, _ := js.Quality.Records(jobName)
+	known := make(map[string]models_verify_viewer.ImageQualityRecord, len(previous))
+	for _, record := range previous {
+		known[record.Key()] = record
+	}
+
+	var mu sync.Mutex
+	records := make([]models_verify_viewer.ImageQualityRecord, 0, len(tasks))
+	runImageTasks(ctx, tasks, func(task imageTask) {
+		record, reused := analyzeImage(ctx, js.storage, task, known)
+		record.Flags = thresholds.Flags(record)
+		js.Quality.Progress(jobName, reused, record.Error != "")
+
+		mu.Lock()
+		records = append(records, record)
+		mu.Unlock()
+	})
+
+	state := models_verify_viewer.AnalysisJobStateCompleted
+	if ctx.Err() != nil {
+		// Images not reached keep their previous metrics, flagged under the new thresholds
+		state = models_verify_viewer.AnalysisJobStateCancelled
+		done := make(map[string]bool, len(records))
+		for _, record := range records {
+			done[record.Key()] = true
+		}
+		for key, record := range known {
+			if !done[key] {
+				record.Flags = thresholds.Flags(record)
+				records = append(records, record)
+			}
+		}
+	}
+
+	js.Quality.SetRecords(jobName, thresholds, records)
+	js.Quality.End(jobName, state, nil)
+	slog.Info("Quality check finished", "job", jobName, "state", state, "images", len(records))
+
+	if err := js.Quality.SaveToFile(js.backupDir, jobName); err != nil {
+		slog.Warn("Failed to save quality records", "job", jobName, "error", err)
+	}
+}
+
+// analyzeImage reuses the known metrics when the file is unchanged and measures it otherwise
+func analyzeImage(ctx context.Context, store utils.Storage, task imageTask, known map[string]models_verify_viewer.ImageQualityRecord) (models_verify_viewer.ImageQualityRecord, bool) {
+	record := models_verify_viewer.ImageQualityRecord{
+		DatasetName: task.datasetName,
+		ImageName:   task.imageName,
+	}
+
+	info, err := store.Stat(ctx, task.imagePath)
+	if err != nil {
+		record.Error = err.Error()
+		return record, false
+	}
+	record.Size = info.Size
+	record.ModTime = info.ModTime
+
+	if previous, found := known[record.Key()]; found &&
+		previous.Size == record.Size && previous.ModTime.Equal(record.ModTime) {
+		return previous, true
+	}
+
+	quality, err := utils.AnalyzeImageQuality(ctx, store, task.imagePath)
+	if err != nil {
+		record.Error = err.Error()
+		return record, false
+	}
+	record.Width = quality.Width
+	record.Height = quality.Height
+	record.BlurScore = quality.BlurScore
+	record.MeanBrightness = quality.MeanBrightness
+	record.DarkRatio = quality.DarkRatio
+	record.BrightRatio = quality.BrightRatio
+	return record, false
+}
+
+// CancelQualityCheck stops the running quality check of a job
+func (js *JointServices) CancelQualityCheck(jobName string) error {
+	return js.Quality.Cancel(jobName)
+}
+
+// GetQualityCheckStatus returns the progress of the latest quality check of a job
+func (js *JointServices) GetQualityCheckStatus(jobName string) (models_verify_viewer.AnalysisJobStatus, error) {
+	if !js.JobExists(jobName) {
+		return models_verify_viewer.AnalysisJobStatus{}, ErrJobNotFound
+	}
+
+	return js.Quality.Status(jobName)
+}
+
+// GetQualityReport lists the images of a job raising the quality flag, or any
+// flag when flag is empty, with the count of every flag
+func (js *JointServices) GetQualityReport(jobName, flag string) (models_verify_viewer.QualityReport, error) {
+	if !js.JobExists(jobName) {
+		return models_verify_viewer.QualityReport{}, ErrJobNotFound
+	}
+	if flag != "" && !models_verify_viewer.IsValidQualityFlag(flag) {
+		return models_verify_viewer.QualityReport{}, models_verify_viewer.ErrInvalidQualityFlag
+	}
+
+	if err := js.Quality.LoadFromFile(js.backupDir, jobName); err != nil {
+		slog.Warn("Failed to load quality records", "job", jobName, "error", err)
+	}
+	return js.Quality.Report(jobName, flag)
+}
+
+// qualityRecordsByKey returns the quality records of a job keyed by "dataset|image"
+func (js *JointServices) qualityRecordsByKey(jobName string) (map[string]models_verify_viewer.ImageQualityRecord, error) {
+	if err := js.Quality.LoadFromFile(js.backupDir, jobName); err != nil {
+		slog.Warn("Failed to load quality records", "job", jobName, "error", err)
+	}
+
+	records, _, found := js.Quality.Records(jobName)
+	if !found {
+		return nil, models_verify_viewer.ErrQualityNotAnalyzed
+	}
+
+	index := make(map[string]models_verify_viewer.ImageQualityRecord, len(records))
+	for _, record := range records {
+		index[record.Key()] = record
+	}
+	return index, nil
+}
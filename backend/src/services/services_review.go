@@ -2,25 +2,47 @@ package services
 
 import (
 	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"path/filepath"
+	"time"
 )
 
-// SavePendingReviewData saves pending review items from the request body
-// Returns the number of items saved, or -1 if cleared
-func (js *JointServices) SavePendingReviewData(body interface{}) int {
+// SavePendingReviewData replaces the pending review list with the items of the
+// request body, provided the list is still at the revision the client loaded.
+// Returns the number of items saved (-1 if cleared, 0 if the body is invalid)
+// and the new revision. A stale revision fails with ErrPendingReviewStale and
+// the current revision, so items flagged meanwhile by bulk flagging, duplicate
+// flagging or imports are not dropped.
+func (js *JointServices) SavePendingReviewData(body interface{}, revision uint64, user string) (int, uint64, error) {
 	items, err := parseReviewItems(body)
 	if err != nil {
-		log.Println("Failed to parse review items:", err)
-		return 0
+		slog.Warn("Failed to parse review items", "error", err)
+		return 0, js.PendingReviewData.Revision(), nil
 	}
+	stampReviewItems(items, user)
 
+	before := js.PendingReviewData.Items()
 	if len(items) == 0 {
-		return js.clearPendingReviewItems()
+		cleared, newRevision, err := js.clearPendingReviewItems(revision)
+		if err != nil {
+			return 0, newRevision, err
+		}
+		js.recordReviewChanges(user, models_verify_viewer.AuditActionReviewCleared, before, nil)
+		return cleared, newRevision, nil
 	}
 
-	return js.mergePendingReviewItems(items)
+	itemsLen, newRevision, err := js.mergePendingReviewItems(items, revision)
+	if err != nil {
+		return 0, newRevision, err
+	}
+	// Only images this save added count as decisions of the saving user; the rest
+	// were already pending, often flagged by someone or something else
+	js.recordDecisions(user, newReviewItems(items, before))
+	js.recordReviewChanges(user, models_verify_viewer.AuditActionReviewSaved, before, js.PendingReviewData.Items())
+	return itemsLen, newRevision, nil
 }
 
 func parseReviewItems(body interface{}) ([]models_verify_viewer.PendingReviewItem, error) {
@@ -41,6 +63,7 @@ func parseReviewItems(body interface{}) ([]models_verify_viewer.PendingReviewIte
 			DatasetName: getString(itemMap, "dataset"),
 			ImageName:   getString(itemMap, "imageName"),
 			ImagePath:   getString(itemMap, "imagePath"),
+			Note:        getString(itemMap, "note"),
 		}
 		items = append(items, pendingItem)
 	}
@@ -48,39 +71,79 @@ func parseReviewItems(body interface{}) ([]models_verify_viewer.PendingReviewIte
 	return items, nil
 }
 
-func (js *JointServices) clearPendingReviewItems() int {
+// stampReviewItems records who flagged the items and when. Items already pending
+// keep their original stamp because Merge prefers the existing entry.
+func stampReviewItems(items []models_verify_viewer.PendingReviewItem, user string) {
+	now := time.Now()
+	for i := range items {
+		items[i].Reviewer = user
+		items[i].FlaggedAt = &now
+	}
+}
+
+// newReviewItems returns the items that are not in before
+func newReviewItems(items, before []models_verify_viewer.PendingReviewItem) []models_verify_viewer.PendingReviewItem {
+	pending := make(map[string]bool, len(before))
+	for _, item := range before {
+		pending[item.Key()] = true
+	}
+
+	added := make([]models_verify_viewer.PendingReviewItem, 0, len(items))
+	for _, item := range items {
+		if !pending[item.Key()] {
+			added = append(added, item)
+		}
+	}
+	return added
+}
+
+func (js *JointServices) clearPendingReviewItems(revision uint64) (int, uint64, error) {
 	pending := models_verify_viewer.NewPendingReview()
-	js.PendingReviewData.Merge(pending)
-	return -1
+	newRevision, err := js.PendingReviewData.MergeAt(pending, revision)
+	if err != nil {
+		return 0, newRevision, err
+	}
+	return -1, newRevision, nil
 }
 
-func (js *JointServices) mergePendingReviewItems(items []models_verify_viewer.PendingReviewItem) int {
+func (js *JointServices) mergePendingReviewItems(items []models_verify_viewer.PendingReviewItem, revision uint64) (int, uint64, error) {
 	pending := models_verify_viewer.NewPendingReview()
 	pending.Replace(items)
-	js.PendingReviewData.Merge(pending)
+	newRevision, err := js.PendingReviewData.MergeAt(pending, revision)
+	if err != nil {
+		return 0, newRevision, err
+	}
 
-	backupDir := GetBackupDir()
+	backupDir := js.backupDir
 	if err := js.PendingReviewData.CreateBackup(backupDir); err != nil {
-		log.Printf("Warning: Failed to create backup: %v", err)
+		slog.Warn("Failed to create backup", "error", err)
 	}
 
-	return len(items)
+	return len(items), newRevision, nil
 }
 
 // GetBackupList returns a list of all available backups
 func (js *JointServices) GetBackupList() ([]models_verify_viewer.BackupInfo, error) {
-	backupDir := GetBackupDir()
+	backupDir := js.backupDir
 	return js.PendingReviewData.ListBackups(backupDir)
 }
 
+// SetMaxBackupCount sets how many pending review backups rotation keeps
+func (js *JointServices) SetMaxBackupCount(count int) {
+	js.PendingReviewData.SetMaxBackupCount(count)
+}
+
 // RestoreFromBackup restores pending review data from a backup file
-func (js *JointServices) RestoreFromBackup(filename string) error {
-	backupDir := GetBackupDir()
+func (js *JointServices) RestoreFromBackup(filename string, user string) error {
+	backupDir := js.backupDir
 	err := js.PendingReviewData.RestoreFromBackup(backupDir, filename)
+	utils.RecordBackupRestore(err == nil)
 	if err != nil {
 		return err
 	}
 
+	detail := fmt.Sprintf("%s (%d items)", filename, js.PendingReviewData.Len())
+	js.recordAudit(auditEntry(user, models_verify_viewer.AuditActionBackupRestored, detail))
 	return nil
 }
 
@@ -99,22 +162,25 @@ func (js *JointServices) GetPendingReviewItems() []models_verify_viewer.PendingR
 }
 
 // ClearPendingReview clears all pending review data
-func (js *JointServices) ClearPendingReview() {
+func (js *JointServices) ClearPendingReview(user string) {
+	cleared := js.PendingReviewData.Items()
+
 	// Clear the pending review data
 	js.PendingReviewData.Clear()
-	
+	js.recordReviewChanges(user, models_verify_viewer.AuditActionReviewCleared, cleared, nil)
+
 	// Create a backup of the cleared state
-	backupDir := GetBackupDir()
+	backupDir := js.backupDir
 	if err := js.PendingReviewData.CreateBackup(backupDir); err != nil {
-		log.Printf("Warning: Failed to create backup after clear: %v", err)
+		slog.Warn("Failed to create backup after clear", "error", err)
 	}
 }
 
 // ClearPendingReviewData clears all pending review data after creating a backup
 func (js *JointServices) ClearPendingReviewData() {
-	backupDir := GetBackupDir()
+	backupDir := js.backupDir
 	if err := js.PendingReviewData.CreateBackup(backupDir); err != nil {
-		log.Printf("Warning: Failed to create backup before clear: %v", err)
+		slog.Warn("Failed to create backup before clear", "error", err)
 	}
 
 	js.PendingReviewData.Clear()
@@ -124,7 +190,7 @@ func (js *JointServices) ClearPendingReviewData() {
 func (js *JointServices) GetPendingReviewImagePaths() []string {
 	items := js.GetPendingReviewItems()
 	imagePaths := make([]string, 0, len(items))
-	root := GetImageRoot()
+	root := js.root
 
 	for _, item := range items {
 		fullPath := filepath.Join(root, item.JobName, item.DatasetName, item.ImageName)
@@ -136,40 +202,34 @@ func (js *JointServices) GetPendingReviewImagePaths() []string {
 
 // DeleteImageResult contains information about the deletion operation
 type DeleteImageResult struct {
-	DeletedCount int      `json:"deleted_count"`
-	CacheCleared bool     `json:"cache_cleared"`
-	AffectedJobs []string `json:"affected_jobs"`
-	DeletedPaths []string `json:"deleted_paths"`
+	DeletedCount    int      `json:"deleted_count"`
+	CacheCleared    bool     `json:"cache_cleared"`
+	AffectedJobs    []string `json:"affected_jobs"`
+	DeletedPaths    []string `json:"deleted_paths"`
+	PendingApproval bool     `json:"pending_approval"`
+	BatchID         string   `json:"batch_id,omitempty"`
 }
 
-// DeleteSelectedImages deletes physical image files and removes them from pending review
-func (js *JointServices) DeleteSelectedImages(body interface{}) (*DeleteImageResult, error) {
+// DeleteSelectedImages deletes physical image files and removes them from pending review.
+// When deletion approval is required, a pending deletion batch is created instead.
+func (js *JointServices) DeleteSelectedImages(ctx context.Context, body interface{}, user string) (*DeleteImageResult, error) {
 	itemsData, ok := body.([]interface{})
 	if !ok {
-		log.Println("Invalid data format for DeleteSelectedImages")
+		slog.Warn("Invalid data format for image deletion")
 		return &DeleteImageResult{DeletedCount: 0, CacheCleared: false}, nil
 	}
 
-	deletedItems, deletedPaths, affectedJobs := js.deletePhysicalFiles(itemsData)
-	deletedCount := len(deletedItems)
-
-	if deletedCount > 0 {
-		js.removePendingReviewItems(deletedItems)
+	items := js.parseDeleteItems(itemsData)
+	if js.DeletionApprovalRequired() {
+		return js.requestDeletion(items, user)
 	}
 
-	return &DeleteImageResult{
-		DeletedCount: deletedCount,
-		CacheCleared: false,
-		AffectedJobs: affectedJobs,
-		DeletedPaths: deletedPaths,
-	}, nil
+	return js.deleteItems(ctx, items, user, ""), nil
 }
 
-func (js *JointServices) deletePhysicalFiles(itemsData []interface{}) (map[string]bool, []string, []string) {
-	deletedItems := make(map[string]bool)
-	deletedPaths := make([]string, 0)
-	affectedJobsMap := make(map[string]bool)
-	root := GetImageRoot()
+func (js *JointServices) parseDeleteItems(itemsData []interface{}) []models_verify_viewer.PendingReviewItem {
+	root := js.root
+	items := make([]models_verify_viewer.PendingReviewItem, 0, len(itemsData))
 
 	for _, item := range itemsData {
 		itemMap, ok := item.(map[string]interface{})
@@ -185,12 +245,46 @@ func (js *JointServices) deletePhysicalFiles(itemsData []interface{}) (map[strin
 			continue
 		}
 
-		fullPath := buildImagePath(root, jobName, datasetName, imageName)
-		if js.deleteImageFile(fullPath) {
-			key := createItemKey(jobName, datasetName, imageName)
+		items = append(items, models_verify_viewer.PendingReviewItem{
+			JobName:     jobName,
+			DatasetName: datasetName,
+			ImageName:   imageName,
+			ImagePath:   buildImagePath(root, jobName, datasetName, imageName),
+		})
+	}
+
+	return items
+}
+
+func (js *JointServices) deleteItems(ctx context.Context, items []models_verify_viewer.PendingReviewItem, user string, detail string) *DeleteImageResult {
+	deletedItems, deletedPaths, affectedJobs := js.deletePhysicalFiles(ctx, items, user, detail)
+	deletedCount := len(deletedItems)
+
+	if deletedCount > 0 {
+		js.removePendingReviewItems(deletedItems)
+	}
+
+	return &DeleteImageResult{
+		DeletedCount: deletedCount,
+		CacheCleared: false,
+		AffectedJobs: affectedJobs,
+		DeletedPaths: deletedPaths,
+	}
+}
+
+func (js *JointServices) deletePhysicalFiles(ctx context.Context, items []models_verify_viewer.PendingReviewItem, user string, detail string) (map[string]bool, []string, []string) {
+	deletedItems := make(map[string]bool)
+	deletedPaths := make([]string, 0)
+	affectedJobsMap := make(map[string]bool)
+
+	for _, item := range items {
+		if js.deleteImageFile(ctx, item.ImagePath) {
+			key := createItemKey(item.JobName, item.DatasetName, item.ImageName)
 			deletedItems[key] = true
-			deletedPaths = append(deletedPaths, fullPath)
-			affectedJobsMap[jobName] = true
+			deletedPaths = append(deletedPaths, item.ImagePath)
+			affectedJobsMap[item.JobName] = true
+
+			js.recordItemAudit(user, models_verify_viewer.AuditActionImageDeleted, item, detail)
 		}
 	}
 
@@ -211,9 +305,11 @@ func buildImagePath(root, jobName, datasetName, imageName string) string {
 	return filepath.Join(root, jobName, datasetName, "image", imageName)
 }
 
-func (js *JointServices) deleteImageFile(fullPath string) bool {
-	if err := models_verify_viewer.DeleteImageFile(fullPath); err != nil {
-		log.Printf("Failed to delete image %s: %v", fullPath, err)
+func (js *JointServices) deleteImageFile(ctx context.Context, fullPath string) bool {
+	err := js.storage.Delete(ctx, fullPath)
+	utils.RecordImageDeletion(err == nil)
+	if err != nil {
+		slog.Error("Failed to delete image", "path", fullPath, "error", err)
 		return false
 	}
 
@@ -232,12 +328,12 @@ func (js *JointServices) removePendingReviewItems(deletedItems map[string]bool)
 
 	js.PendingReviewData.Replace(newItems)
 
-	backupDir := GetBackupDir()
+	backupDir := js.backupDir
 	if err := js.PendingReviewData.CreateBackup(backupDir); err != nil {
-		log.Printf("Warning: Failed to create backup after deletion: %v", err)
+		slog.Warn("Failed to create backup after deletion", "error", err)
 	}
 
-	log.Printf("Removed %d items from pending review list", len(deletedItems))
+	slog.Info("Removed items from pending review list", "count", len(deletedItems))
 }
 
 func createItemKey(jobName, datasetName, imageName string) string {
@@ -262,7 +358,6 @@ func (js *JointServices) CleanupDeletedImagesFromCache(us *UserServices, result
 
 	if totalCacheRemoved > 0 || pageDataRemoved > 0 {
 		result.CacheCleared = true
-		log.Printf("Cache cleanup complete: %d images removed from cache, %d from page data",
-			totalCacheRemoved, pageDataRemoved)
+		slog.Info("Cache cleanup complete", "cache_removed", totalCacheRemoved, "page_data_removed", pageDataRemoved)
 	}
 }
@@ -3,16 +3,13 @@ package services
 import (
 	"backend/src/models_verify_viewer"
 	"backend/src/utils"
-	"log"
+	"log/slog"
 )
 
-const (
-	reviewCacheKey       = "pending_review"
-	maxReviewCacheImages = 1000
-)
+const reviewCacheKey = "pending_review"
 
 // GetOrCreateReviewBase64Images retrieves or creates base64 images for review modal.
-// Uses a separate cache (ReviewCacheStore) with 1000 image limit to avoid conflicts with ImageGrid cache.
+// Uses a separate cache (ReviewCacheStore) with its own image limit to avoid conflicts with ImageGrid cache.
 func (us *UserServices) GetOrCreateReviewBase64Images(imagePaths []string) []string {
 	if len(imagePaths) == 0 {
 		return []string{}
@@ -21,7 +18,7 @@ func (us *UserServices) GetOrCreateReviewBase64Images(imagePaths []string) []str
 	// Get or create review cache
 	reviewCache := us.getOrCreateReviewCache()
 	if reviewCache == nil {
-		log.Println("ERROR: Failed to create review cache")
+		slog.Error("Failed to create review cache")
 		return make([]string, len(imagePaths))
 	}
 
@@ -38,6 +35,7 @@ func (us *UserServices) GetOrCreateReviewBase64Images(imagePaths []string) []str
 			missingIndices = append(missingIndices, i)
 		}
 	}
+	utils.RecordCacheLookups(utils.CacheStoreReview, len(imagePaths)-len(missingPaths), len(missingPaths))
 
 	// Compress missing images
 	if len(missingPaths) > 0 {
@@ -71,7 +69,7 @@ func (us *UserServices) getOrCreateReviewCache() *models_verify_viewer.Base64Ima
 	us.CacheManager.SetReviewImageCacheStore(reviewCacheKey)
 	reviewCache, exist = us.CacheManager.GetReviewImageCacheStore(reviewCacheKey)
 	if !exist {
-		log.Println("Failed to create review cache store")
+		slog.Error("Failed to create review cache store")
 		return nil
 	}
 
@@ -84,9 +82,9 @@ func (us *UserServices) compressReviewImages(imagePaths []string) []string {
 	base64Images := make([]string, len(imagePaths))
 
 	for i, imagePath := range imagePaths {
-		base64Image, err := utils.CompressImageToBase64(imagePath)
+		base64Image, err := utils.CompressImageToBase64(us.storage, imagePath)
 		if err != nil {
-			log.Printf("Failed to compress image %s: %v", imagePath, err)
+			slog.Warn("Failed to compress review image", "path", imagePath, "error", err)
 			base64Images[i] = ""
 		} else {
 			base64Images[i] = base64Image
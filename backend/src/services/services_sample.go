@@ -0,0 +1,219 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// randomSampleStratum is the single stratum of an unstratified sample
+const randomSampleStratum = "all"
+
+var ErrInvalidSample = errors.New("invalid sample")
+
+func (js *JointServices) restoreSamples() {
+	if err := js.Samples.LoadFromFile(js.backupDir); err != nil {
+		slog.Warn("Failed to load samples", "error", err)
+	}
+}
+
+func (js *JointServices) persistSamples() {
+	if err := js.Samples.SaveToFile(js.backupDir); err != nil {
+		slog.Warn("Failed to save samples", "error", err)
+	}
+}
+
+// CreateSample draws and records a reproducible sample of a job's images.
+// The spec's seed must already be set.
+func (js *JointServices) CreateSample(ctx context.Context, jobName string, spec models_verify_viewer.SampleSpec, user string) (models_verify_viewer.SampleRecord, error) {
+	if !js.JobExists(jobName) {
+		return models_verify_viewer.SampleRecord{}, ErrJobNotFound
+	}
+	if err := spec.Validate(); err != nil {
+		return models_verify_viewer.SampleRecord{}, fmt.Errorf("%w: %v", ErrInvalidSample, err)
+	}
+	if !spec.Enabled() {
+		return models_verify_viewer.SampleRecord{}, fmt.Errorf("%w: no sample method", ErrInvalidSample)
+	}
+
+	jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, js.storage, jobName)
+	strata, order := stratifyJobImages(ctx, js.storage, jobData, spec)
+
+	record := models_verify_viewer.SampleRecord{
+		ID:        newRecordID("sample"),
+		JobName:   jobName,
+		Spec:      spec,
+		Strata:    make([]models_verify_viewer.SampleStratum, 0, len(order)),
+		Items:     make([]models_verify_viewer.SampleItem, 0),
+		CreatedBy: user,
+		CreatedAt: time.Now(),
+	}
+	for _, name := range order {
+		record.Population += len(strata[name])
+	}
+
+	sizes := allocateSample(spec, strata, order, record.Population)
+	rng := rand.New(rand.NewSource(spec.Seed))
+	for _, name := range order {
+		population := strata[name]
+		drawn := drawStratum(rng, population, sizes[name])
+		record.Items = append(record.Items, drawn...)
+		record.Strata = append(record.Strata, models_verify_viewer.SampleStratum{
+			Name:       name,
+			Population: len(population),
+			Sampled:    len(drawn),
+		})
+	}
+
+	js.Samples.Add(record)
+	js.persistSamples()
+	slog.Info("Sample drawn", "sample_id", record.ID, "job", jobName,
+		"sampled", len(record.Items), "population", record.Population, "method", spec.Method, "seed", spec.Seed)
+
+	return record, nil
+}
+
+// stratifyJobImages groups the images of a job into strata and returns the
+// stratum names in a stable order
+func stratifyJobImages(ctx context.Context, store utils.Storage, jobData models_verify_viewer.Job, spec models_verify_viewer.SampleSpec) (map[string][]models_verify_viewer.SampleItem, []string) {
+	strata := make(map[string][]models_verify_viewer.SampleItem)
+	for _, dataset := range jobData.Datasets {
+		labels := dataset.LabelIndex()
+		for _, image := range dataset.Image {
+			stratum := randomSampleStratum
+			if spec.Method == models_verify_viewer.SampleMethodStratified {
+				if spec.Stratify == models_verify_viewer.StratifyByDataset {
+					stratum = dataset.Name
+				} else {
+					stratum = primaryLabelClass(ctx, store, labels, image.Name)
+				}
+			}
+			strata[stratum] = append(strata[stratum], models_verify_viewer.SampleItem{
+				DatasetName: dataset.Name,
+				ImageName:   image.Name,
+				Stratum:     stratum,
+			})
+		}
+	}
+
+	order := make([]string, 0, len(strata))
+	for name := range strata {
+		order = append(order, name)
+	}
+	sort.Strings(order)
+	return strata, order
+}
+
+// primaryLabelClass is the most frequent class of an image's label (ties go to
+// the alphabetically first class), or the unlabeled stratum
+func primaryLabelClass(ctx context.Context, store utils.Storage, labels models_verify_viewer.LabelIndex, imageName string) string {
+	label, ok := labels.For(imageName)
+	if !ok {
+		return models_verify_viewer.UnlabeledStratum
+	}
+	annotation, err := utils.ReadAnnotation(ctx, store, label.Path)
+	if err != nil || len(annotation.Objects) == 0 {
+		return models_verify_viewer.UnlabeledStratum
+	}
+
+	counts := make(map[string]int)
+	for _, object := range annotation.Objects {
+		counts[object.Class]++
+	}
+	primary := ""
+	for class, count := range counts {
+		if primary == "" || count > counts[primary] || (count == counts[primary] && class < primary) {
+			primary = class
+		}
+	}
+	return primary
+}
+
+// allocateSample returns the sample size of every stratum. A rate applies to
+// each stratum (at least one image from a non-empty stratum); a count is split
+// in proportion to stratum size using largest remainders.
+func allocateSample(spec models_verify_viewer.SampleSpec, strata map[string][]models_verify_viewer.SampleItem, order []string, population int) map[string]int {
+	sizes := make(map[string]int, len(order))
+	if spec.Rate > 0 {
+		for _, name := range order {
+			size := int(math.Round(spec.Rate * float64(len(strata[name]))))
+			sizes[name] = max(size, 1)
+		}
+		return sizes
+	}
+
+	total := min(spec.Count, population)
+	if population == 0 {
+		return sizes
+	}
+
+	type remainder struct {
+		name string
+		frac float64
+	}
+	remainders := make([]remainder, 0, len(order))
+	allocated := 0
+	for _, name := range order {
+		exact := float64(total) * float64(len(strata[name])) / float64(population)
+		sizes[name] = int(exact)
+		allocated += sizes[name]
+		remainders = append(remainders, remainder{name: name, frac: exact - float64(sizes[name])})
+	}
+	sort.SliceStable(remainders, func(i, j int) bool {
+		return remainders[i].frac > remainders[j].frac
+	})
+	for i := 0; allocated < total; i++ {
+		sizes[remainders[i%len(remainders)].name]++
+		allocated++
+	}
+	return sizes
+}
+
+// drawStratum picks size items without replacement and returns them in their original order
+func drawStratum(rng *rand.Rand, population []models_verify_viewer.SampleItem, size int) []models_verify_viewer.SampleItem {
+	size = min(size, len(population))
+	picked := rng.Perm(len(population))[:size]
+	sort.Ints(picked)
+
+	drawn := make([]models_verify_viewer.SampleItem, 0, size)
+	for _, index := range picked {
+		drawn = append(drawn, population[index])
+	}
+	return drawn
+}
+
+// GetSamples lists recorded samples of a job without their items
+func (js *JointServices) GetSamples(jobName string) []models_verify_viewer.SampleRecord {
+	return js.Samples.List(jobName)
+}
+
+func (js *JointServices) GetSample(sampleID string) (models_verify_viewer.SampleRecord, error) {
+	return js.Samples.Get(sampleID)
+}
+
+// GetSampleEstimate extrapolates the images flagged (pending review) within a
+// sample to every stratum of the job
+func (js *JointServices) GetSampleEstimate(sampleID string) (models_verify_viewer.SampleEstimate, error) {
+	record, err := js.Samples.Get(sampleID)
+	if err != nil {
+		return models_verify_viewer.SampleEstimate{}, err
+	}
+
+	pending := make(map[string]bool)
+	for _, item := range js.GetPendingReviewItems() {
+		if item.JobName == record.JobName {
+			pending[createItemKey(item.JobName, item.DatasetName, item.ImageName)] = true
+		}
+	}
+
+	return record.Estimate(func(item models_verify_viewer.SampleItem) bool {
+		return pending[createItemKey(record.JobName, item.DatasetName, item.ImageName)]
+	}), nil
+}
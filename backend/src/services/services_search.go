@@ -0,0 +1,158 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+const (
+	DefaultSearchPageSize = 50
+	maxSearchPageSize     = 500
+)
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+// SearchResult is one page of search hits
+type SearchResult struct {
+	JobName    string                          `json:"job_name"`
+	Total      int                             `json:"total"`
+	Page       int                             `json:"page"`
+	PageSize   int                             `json:"page_size"`
+	TotalPages int                             `json:"total_pages"`
+	Hits       []models_verify_viewer.ImageHit `json:"hits"`
+}
+
+// SearchJobImages returns one page of the job's images matching the query.
+// Dimensions are filled in for the returned hits.
+func (js *JointServices) SearchJobImages(ctx context.Context, jobName string, query models_verify_viewer.ImageQuery, page, pageSize int) (SearchResult, error) {
+	hits, err := js.MatchImageQuery(ctx, jobName, query)
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	pageSize = clampSearchPageSize(pageSize)
+	result := SearchResult{
+		JobName:    jobName,
+		Total:      len(hits),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: (len(hits) + pageSize - 1) / pageSize,
+		Hits:       make([]models_verify_viewer.ImageHit, 0, pageSize),
+	}
+
+	start := page * pageSize
+	if start >= len(hits) {
+		return result, nil
+	}
+	end := min(start+pageSize, len(hits))
+
+	for _, hit := range hits[start:end] {
+		if hit.Width == 0 && hit.Height == 0 {
+			hit.Width, hit.Height, _ = utils.ImageDimensions(ctx, js.storage, hit.ImagePath)
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	return result, nil
+}
+
+// MatchImageQuery returns every image of the job matching the query, in scan order
+func (js *JointServices) MatchImageQuery(ctx context.Context, jobName string, query models_verify_viewer.ImageQuery) ([]models_verify_viewer.ImageHit, error) {
+	if !js.JobExists(jobName) {
+		return nil, ErrJobNotFound
+	}
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+
+	pending := make(map[string]bool)
+	for _, item := range js.GetPendingReviewItems() {
+		if item.JobName == jobName {
+			pending[item.Key()] = true
+		}
+	}
+
+	jobData, _ := utils.ConcurrentJobDetailsScanner(ctx, js.storage, jobName)
+	hits := make([]models_verify_viewer.ImageHit, 0)
+	for _, dataset := range jobData.Datasets {
+		if query.DatasetName != "" && dataset.Name != query.DatasetName {
+			continue
+		}
+
+		labels := make(map[string]bool, len(dataset.Label))
+		for _, label := range dataset.Label {
+			labels[label.Name] = true
+		}
+
+		for _, image := range dataset.Image {
+			if !query.MatchesName(dataset.Name, image.Name) {
+				continue
+			}
+
+			hit, ok := newImageHit(ctx, js.storage, jobName, dataset.Name, image, labels, pending, query.NeedsDimensions())
+			if ok && query.Matches(hit) {
+				hits = append(hits, hit)
+			}
+		}
+	}
+
+	return hits, nil
+}
+
+func newImageHit(ctx context.Context, store utils.Storage, jobName, datasetName string, image models_verify_viewer.Image, labels, pending map[string]bool, withDimensions bool) (models_verify_viewer.ImageHit, bool) {
+	info, err := store.Stat(ctx, image.Path)
+	if err != nil {
+		slog.Debug("Skipping image in search", "path", image.Path, "error", err)
+		return models_verify_viewer.ImageHit{}, false
+	}
+
+	hit := models_verify_viewer.ImageHit{
+		DatasetName: datasetName,
+		ImageName:   image.Name,
+		ImagePath:   image.Path,
+		Size:        info.Size,
+		ModTime:     info.ModTime,
+		HasLabel:    labels[models_verify_viewer.LabelNameForImage(image.Name)],
+		Decision:    models_verify_viewer.SearchDecisionUndecided,
+	}
+	if pending[createItemKey(jobName, datasetName, image.Name)] {
+		hit.Decision = models_verify_viewer.SearchDecisionPending
+	}
+	if withDimensions {
+		hit.Width, hit.Height, _ = utils.ImageDimensions(ctx, store, image.Path)
+	}
+	return hit, true
+}
+
+func clampSearchPageSize(pageSize int) int {
+	if pageSize <= 0 {
+		return DefaultSearchPageSize
+	}
+	return min(pageSize, maxSearchPageSize)
+}
+
+// SetSearchPageData replaces the current pages with the search hits so the grid
+// can page through them like a whole job. Pages never mix datasets.
+func (us *UserServices) SetSearchPageData(jobName string, query models_verify_viewer.ImageQuery, hits []models_verify_viewer.ImageHit, pageSize int) {
+	us.ClearImageCache(jobName)
+	us.ClearCurrentPageData()
+	us.FillJobNameToCurrentPageData(jobName)
+	us.CurrentPageData.SetSearchQuery(query)
+
+	for start := 0; start < len(hits); {
+		datasetName := hits[start].DatasetName
+		images := make([]models_verify_viewer.Image, 0, pageSize)
+		end := start
+		for end < len(hits) && len(images) < pageSize && hits[end].DatasetName == datasetName {
+			images = append(images, hits[end].Image())
+			end++
+		}
+		us.AddPageToCurrentPageData(datasetName, images)
+		start = end
+	}
+
+	slog.Info("Search page data set", "job", jobName, "hits", len(hits), "pages", us.CurrentPageData.Len())
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Shutdown stops the background work of the joint services and persists what
+// is only held in memory. It is called once the HTTP server has stopped
+// accepting requests; when draining hit the deadline, requests still running
+// may change the pending review list after the final backup is written.
+func (js *JointServices) Shutdown(ctx context.Context) {
+	js.CancelAnalysisJobs()
+	if err := js.waitForAnalysisJobs(ctx); err != nil {
+		slog.Warn("Analysis jobs did not stop before the shutdown deadline", "error", err)
+	}
+
+	if js.watcher != nil {
+		js.watcher.Stop()
+	}
+
+	if err := js.PendingReviewData.CreateBackup(js.backupDir); err != nil {
+		slog.Error("Failed to write final pending review backup", "error", err)
+	} else {
+		slog.Info("Wrote final pending review backup", "items", js.PendingReviewData.Len())
+	}
+
+	js.FlushReviewProgress()
+	slog.Info("Joint services stopped", "root", js.root)
+}
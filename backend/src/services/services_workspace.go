@@ -0,0 +1,70 @@
+package services
+
+import (
+	"backend/src/models_verify_viewer"
+	"backend/src/utils"
+	"context"
+	"log/slog"
+)
+
+// WorkspaceConfig names the storage holding an image root and the local
+// directory its review state, backups and audit log are written to
+type WorkspaceConfig struct {
+	Name      string
+	Storage   utils.Storage
+	BackupDir string
+}
+
+// Workspace is one image root served by the backend. Each workspace has its own
+// job watcher, pending review list, backups and caches, so reviews in one
+// workspace never touch the files of another.
+type Workspace struct {
+	Name          string
+	UserServices  *UserServices
+	JointServices *JointServices
+}
+
+type WorkspaceSummary struct {
+	Name    string `json:"name"`
+	Root    string `json:"root"`
+	Jobs    int    `json:"jobs"`
+	Default bool   `json:"default"`
+}
+
+func NewWorkspace(ctx context.Context, config WorkspaceConfig, limits models_verify_viewer.CacheLimits) *Workspace {
+	js := NewJointServices(ctx, config.Storage, config.BackupDir)
+	us := NewUserServices(config.Name, config.Storage, limits)
+	utils.RegisterCachedBytes(utils.CacheStoreImage, config.Name, us.CacheManager.ImageCacheBytes)
+	utils.RegisterCachedBytes(utils.CacheStoreReview, config.Name, us.CacheManager.ReviewCacheBytes)
+
+	CheckServicesState(us, js)
+	slog.Info("Workspace opened", "workspace", config.Name, "image_root", config.Storage.Root(), "backup_dir", config.BackupDir)
+	return &Workspace{
+		Name:          config.Name,
+		UserServices:  us,
+		JointServices: js,
+	}
+}
+
+// Summary describes the workspace for the workspace list
+func (ws *Workspace) Summary(isDefault bool) WorkspaceSummary {
+	return WorkspaceSummary{
+		Name:    ws.Name,
+		Root:    ws.JointServices.ImageRoot(),
+		Jobs:    len(ws.JointServices.JobList.Jobs()),
+		Default: isDefault,
+	}
+}
+
+// CheckWorkspacesHealth runs the health checks of every workspace and tags each
+// check with the workspace it belongs to
+func CheckWorkspacesHealth(ctx context.Context, workspaces []*Workspace) models_verify_viewer.HealthReport {
+	checks := make([]models_verify_viewer.HealthCheck, 0)
+	for _, ws := range workspaces {
+		for _, check := range ws.JointServices.CheckHealth(ctx).Checks {
+			check.Workspace = ws.Name
+			checks = append(checks, check)
+		}
+	}
+	return models_verify_viewer.NewHealthReport(checks)
+}
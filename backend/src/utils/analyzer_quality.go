@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"context"
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// qualityAnalysisSize bounds the longer side the metrics are computed on, so
+	// blur scores are comparable between image sizes
+	qualityAnalysisSize = 512
+	darkPixelLevel      = 16
+	brightPixelLevel    = 240
+)
+
+// ImageQuality holds the raw quality metrics of one image. Brightness values are
+// on a 0-255 scale; the ratios are fractions of the pixels.
+type ImageQuality struct {
+	Width          int
+	Height         int
+	BlurScore      float64
+	MeanBrightness float64
+	DarkRatio      float64
+	BrightRatio    float64
+}
+
+// AnalyzeImageQuality decodes an image through imaging and measures its
+// sharpness as the variance of the Laplacian and its exposure from the
+// grayscale histogram. A decode error means the file is corrupt or unreadable.
+func AnalyzeImageQuality(ctx context.Context, store Storage, imagePath string) (ImageQuality, error) {
+	srcImage, err := openStoredImage(ctx, store, imagePath)
+	if err != nil {
+		return ImageQuality{}, err
+	}
+
+	bounds := srcImage.Bounds()
+	quality := ImageQuality{
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+	}
+
+	var sample image.Image = srcImage
+	if quality.Width > qualityAnalysisSize || quality.Height > qualityAnalysisSize {
+		sample = imaging.Fit(srcImage, qualityAnalysisSize, qualityAnalysisSize, imaging.Box)
+	}
+	gray := imaging.Grayscale(sample)
+
+	width, height := gray.Bounds().Dx(), gray.Bounds().Dy()
+	luma := func(x, y int) float64 {
+		return float64(gray.Pix[y*gray.Stride+x*4])
+	}
+
+	pixels := float64(width * height)
+	var sum float64
+	var dark, bright int
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			value := luma(x, y)
+			sum += value
+			if value < darkPixelLevel {
+				dark++
+			} else if value >= brightPixelLevel {
+				bright++
+			}
+		}
+	}
+	quality.MeanBrightness = sum / pixels
+	quality.DarkRatio = float64(dark) / pixels
+	quality.BrightRatio = float64(bright) / pixels
+	quality.BlurScore = laplacianVariance(luma, width, height)
+
+	return quality, nil
+}
+
+// laplacianVariance applies the 4-neighbour Laplacian kernel to the inner pixels
+// and returns the variance of the responses. Sharp edges give a high variance.
+func laplacianVariance(luma func(x, y int) float64, width, height int) float64 {
+	if width < 3 || height < 3 {
+		return 0
+	}
+
+	var sum, sumSquares float64
+	count := float64((width - 2) * (height - 2))
+	for y := 1; y < height-1; y++ {
+		for x := 1; x < width-1; x++ {
+			response := 4*luma(x, y) - luma(x-1, y) - luma(x+1, y) - luma(x, y-1) - luma(x, y+1)
+			sum += response
+			sumSquares += response * response
+		}
+	}
+
+	mean := sum / count
+	return sumSquares/count - mean*mean
+}
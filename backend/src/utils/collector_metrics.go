@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "reviewer"
+
+const (
+	CacheStoreImage  = "image"
+	CacheStoreReview = "review"
+
+	cancelReasonOutOfRange = "out_of_range"
+	cancelReasonSlotsFull  = "slots_full"
+	cancelReasonShutdown   = "shutdown"
+
+	metricResultSuccess = "success"
+	metricResultFailure = "failure"
+)
+
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	cacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "cache_lookups_total",
+		Help:      "Image cache lookups by cache store and result (hit or miss).",
+	}, []string{"store", "result"})
+
+	compressionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "image_compression_duration_seconds",
+		Help:      "Time spent compressing a single image by outcome status.",
+		Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"status"})
+
+	taskQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "task_manager_running_tasks",
+		Help:      "Page compression tasks currently holding a task manager slot.",
+	})
+
+	taskCancellations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "task_manager_cancellations_total",
+		Help:      "Page compression tasks cancelled by the task manager by reason.",
+	}, []string{"reason"})
+
+	watcherEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "watcher_events_total",
+		Help:      "File system events received by the job watcher by operation.",
+	}, []string{"op"})
+
+	imageDeletions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "image_deletions_total",
+		Help:      "Image files deleted from disk by result.",
+	}, []string{"result"})
+
+	backupRestores = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "backup_restores_total",
+		Help:      "Pending review restores from backup files by result.",
+	}, []string{"result"})
+)
+
+// MetricsHandler serves the registered metrics in the Prometheus text format
+func MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveHTTPRequest records the latency of a finished request. Requests that
+// matched no route are grouped under "unmatched" to keep label cardinality low.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// RecordCacheLookups counts image lookups against a cache store
+func RecordCacheLookups(store string, hits, misses int) {
+	if hits > 0 {
+		cacheLookups.WithLabelValues(store, "hit").Add(float64(hits))
+	}
+	if misses > 0 {
+		cacheLookups.WithLabelValues(store, "miss").Add(float64(misses))
+	}
+}
+
+// RegisterCachedBytes exposes the size of a workspace's cache store, read from
+// sizeFn on every scrape. Registering the same pair twice keeps the first reader.
+func RegisterCachedBytes(store, workspace string, sizeFn func() int) {
+	gauge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   metricsNamespace,
+		Name:        "cache_bytes",
+		Help:        "Size of the base64 images held by a cache store.",
+		ConstLabels: prometheus.Labels{"store": store, "workspace": workspace},
+	}, func() float64 {
+		return float64(sizeFn())
+	})
+
+	if err := prometheus.Register(gauge); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if !errors.As(err, &alreadyRegistered) {
+			slog.Warn("Failed to register cache size metric", "store", store, "workspace", workspace, "error", err)
+		}
+	}
+}
+
+// RecordImageDeletion counts one image file deletion attempt
+func RecordImageDeletion(success bool) {
+	imageDeletions.WithLabelValues(resultLabel(success)).Inc()
+}
+
+// RecordBackupRestore counts one restore of the pending review list
+func RecordBackupRestore(success bool) {
+	backupRestores.WithLabelValues(resultLabel(success)).Inc()
+}
+
+func observeCompression(status string, duration time.Duration) {
+	compressionDuration.WithLabelValues(status).Observe(duration.Seconds())
+}
+
+func recordTaskCancellations(reason string, count int) {
+	taskCancellations.WithLabelValues(reason).Add(float64(count))
+}
+
+func recordWatcherEvent(op string) {
+	watcherEvents.WithLabelValues(op).Inc()
+}
+
+func resultLabel(success bool) string {
+	if success {
+		return metricResultSuccess
+	}
+	return metricResultFailure
+}
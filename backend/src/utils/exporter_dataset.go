@@ -0,0 +1,462 @@
+package utils
+
+import (
+	"archive/tar"
+	"backend/src/models_verify_viewer"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	AnnotationFormatCOCO = "coco"
+	AnnotationFormatYOLO = "yolo"
+
+	exportImagesDir     = "images"
+	exportLabelsDir     = "labels"
+	exportCOCOFile      = "annotations.coco.json"
+	exportClassesFile   = "classes.txt"
+	exportFilePerm      = 0644
+	exportDirectoryPerm = 0755
+)
+
+// TrainingSetOptions controls ExportTrainingSet. Excluded holds
+// "job|dataset|image" keys of images that must not be exported; Progress, when
+// set, is called after each image is copied or skipped as failed.
+type TrainingSetOptions struct {
+	Formats  []string
+	Excluded map[string]bool
+	Archive  bool
+	Progress func(failed bool)
+}
+
+// TrainingSetSummary describes the written export
+type TrainingSetSummary struct {
+	JobName         string   `json:"job_name"`
+	OutputPath      string   `json:"output_path"`
+	Formats         []string `json:"formats"`
+	ImageCount      int      `json:"image_count"`
+	ExcludedCount   int      `json:"excluded_count"`
+	AnnotationCount int      `json:"annotation_count"`
+	UnlabeledCount  int      `json:"unlabeled_count"`
+	InvalidLabels   []string `json:"invalid_labels"`
+	Classes         []string `json:"classes"`
+	Archive         bool     `json:"archive"`
+}
+
+type exportedImage struct {
+	relPath    string
+	sourcePath string
+	width      int
+	height     int
+	annotation models_verify_viewer.Annotation
+}
+
+type cocoFile struct {
+	Info        cocoInfo         `json:"info"`
+	Images      []cocoImage      `json:"images"`
+	Annotations []cocoAnnotation `json:"annotations"`
+	Categories  []cocoCategory   `json:"categories"`
+}
+
+type cocoInfo struct {
+	Description string `json:"description"`
+	DateCreated string `json:"date_created"`
+}
+
+type cocoImage struct {
+	ID       int    `json:"id"`
+	FileName string `json:"file_name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+type cocoAnnotation struct {
+	ID         int        `json:"id"`
+	ImageID    int        `json:"image_id"`
+	CategoryID int        `json:"category_id"`
+	BBox       [4]float64 `json:"bbox"`
+	Area       float64    `json:"area"`
+	IsCrowd    int        `json:"iscrowd"`
+	Score      *float64   `json:"score,omitempty"`
+}
+
+type cocoCategory struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func IsValidAnnotationFormat(format string) bool {
+	return format == AnnotationFormatCOCO || format == AnnotationFormatYOLO
+}
+
+// ExportTrainingSet copies the surviving images of a job and writes their labels as
+// COCO and/or YOLO annotations, either into outputPath as a directory or into
+// outputPath as a .tar.gz archive.
+//
+// Layout: images/<dataset>/<image>, labels/<dataset>/<image>.txt and classes.txt
+// for YOLO, annotations.coco.json for COCO.
+// Images and labels are read from store; the export is always written locally.
+// Cancelling ctx stops copying and leaves the partial output behind.
+func ExportTrainingSet(ctx context.Context, store Storage, job models_verify_viewer.Job, outputPath string, options TrainingSetOptions) (TrainingSetSummary, error) {
+	summary := TrainingSetSummary{
+		JobName:       job.Name,
+		OutputPath:    outputPath,
+		Formats:       options.Formats,
+		InvalidLabels: make([]string, 0),
+		Archive:       options.Archive,
+	}
+
+	images := collectExportImages(ctx, store, job, options, &summary)
+	classes := collectClasses(images)
+	summary.Classes = classes
+	summary.ImageCount = len(images)
+
+	sink, err := newExportSink(store, outputPath, options.Archive)
+	if err != nil {
+		return summary, err
+	}
+
+	if err := writeTrainingSet(ctx, sink, images, classes, options); err != nil {
+		sink.Close()
+		return summary, err
+	}
+	if err := sink.Close(); err != nil {
+		return summary, err
+	}
+
+	for _, img := range images {
+		summary.AnnotationCount += len(img.annotation.Objects)
+	}
+	return summary, nil
+}
+
+func collectExportImages(ctx context.Context, store Storage, job models_verify_viewer.Job, options TrainingSetOptions, summary *TrainingSetSummary) []exportedImage {
+	images := make([]exportedImage, 0)
+	for _, dataset := range job.Datasets {
+		labels := dataset.LabelIndex()
+		for _, img := range dataset.Image {
+			key := fmt.Sprintf("%s|%s|%s", job.Name, dataset.Name, img.Name)
+			if options.Excluded[key] {
+				summary.ExcludedCount++
+				continue
+			}
+
+			exported := exportedImage{
+				relPath:    path.Join(dataset.Name, img.Name),
+				sourcePath: img.Path,
+				annotation: models_verify_viewer.NewAnnotation(),
+			}
+
+			label, labelled := labels.For(img.Name)
+			if labelled {
+				annotation, err := ReadAnnotation(ctx, store, label.Path)
+				if err != nil {
+					slog.Warn("Skipping invalid label", "path", label.Path, "error", err)
+					summary.InvalidLabels = append(summary.InvalidLabels, path.Join(dataset.Name, label.Name))
+				} else {
+					exported.annotation = annotation
+				}
+			} else {
+				summary.UnlabeledCount++
+			}
+
+			exported.width, exported.height = exported.annotation.ImageWidth, exported.annotation.ImageHeight
+			if exported.width == 0 || exported.height == 0 {
+				if width, height, err := ImageDimensions(ctx, store, img.Path); err == nil {
+					exported.width, exported.height = width, height
+				} else {
+					slog.Warn("Failed to read image dimensions", "path", img.Path, "error", err)
+				}
+			}
+
+			// Boxes cannot be normalized without the image size, and exporting the
+			// image without them would turn it into a background negative
+			if len(exported.annotation.Objects) > 0 && (exported.width == 0 || exported.height == 0) {
+				slog.Warn("Skipping labelled image of unknown size", "path", img.Path, "label", label.Path)
+				summary.InvalidLabels = append(summary.InvalidLabels, path.Join(dataset.Name, label.Name))
+				if options.Progress != nil {
+					options.Progress(true)
+				}
+				continue
+			}
+
+			images = append(images, exported)
+		}
+	}
+	return images
+}
+
+func collectClasses(images []exportedImage) []string {
+	seen := make(map[string]bool)
+	classes := make([]string, 0)
+	for _, img := range images {
+		for _, class := range img.annotation.Classes() {
+			if !seen[class] {
+				seen[class] = true
+				classes = append(classes, class)
+			}
+		}
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+func writeTrainingSet(ctx context.Context, sink exportSink, images []exportedImage, classes []string, options TrainingSetOptions) error {
+	for _, img := range images {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := sink.CopyFile(ctx, path.Join(exportImagesDir, img.relPath), img.sourcePath); err != nil {
+			return err
+		}
+		if options.Progress != nil {
+			options.Progress(false)
+		}
+	}
+
+	classIDs := make(map[string]int, len(classes))
+	for i, class := range classes {
+		classIDs[class] = i
+	}
+
+	for _, format := range options.Formats {
+		var err error
+		switch format {
+		case AnnotationFormatCOCO:
+			err = writeCOCO(sink, images, classes, classIDs)
+		case AnnotationFormatYOLO:
+			err = writeYOLO(sink, images, classes, classIDs)
+		default:
+			err = fmt.Errorf("unsupported annotation format: %s", format)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCOCO(sink exportSink, images []exportedImage, classes []string, classIDs map[string]int) error {
+	coco := cocoFile{
+		Info: cocoInfo{
+			Description: "Exported by dataset-faster-reviewer",
+			DateCreated: time.Now().Format(time.RFC3339),
+		},
+		Images:      make([]cocoImage, 0, len(images)),
+		Annotations: make([]cocoAnnotation, 0),
+		Categories:  make([]cocoCategory, 0, len(classes)),
+	}
+
+	// COCO category ids start at 1
+	for i, class := range classes {
+		coco.Categories = append(coco.Categories, cocoCategory{ID: i + 1, Name: class})
+	}
+
+	for i, img := range images {
+		imageID := i + 1
+		coco.Images = append(coco.Images, cocoImage{
+			ID:       imageID,
+			FileName: path.Join(exportImagesDir, img.relPath),
+			Width:    img.width,
+			Height:   img.height,
+		})
+
+		for _, object := range img.annotation.Objects {
+			annotation := cocoAnnotation{
+				ID:         len(coco.Annotations) + 1,
+				ImageID:    imageID,
+				CategoryID: classIDs[object.Class] + 1,
+				BBox:       object.BBox,
+				Area:       object.Area(),
+			}
+			if object.HasConfidence {
+				score := object.Confidence
+				annotation.Score = &score
+			}
+			coco.Annotations = append(coco.Annotations, annotation)
+		}
+	}
+
+	data, err := json.MarshalIndent(coco, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode COCO annotations: %v", err)
+	}
+	return sink.WriteFile(exportCOCOFile, data)
+}
+
+func writeYOLO(sink exportSink, images []exportedImage, classes []string, classIDs map[string]int) error {
+	if err := sink.WriteFile(exportClassesFile, []byte(strings.Join(classes, "\n")+"\n")); err != nil {
+		return err
+	}
+
+	for _, img := range images {
+		var builder strings.Builder
+		for _, object := range img.annotation.Objects {
+			width, height := float64(img.width), float64(img.height)
+			centerX := (object.BBox[0] + object.BBox[2]/2) / width
+			centerY := (object.BBox[1] + object.BBox[3]/2) / height
+			fmt.Fprintf(&builder, "%d %.6f %.6f %.6f %.6f\n",
+				classIDs[object.Class], centerX, centerY, object.BBox[2]/width, object.BBox[3]/height)
+		}
+
+		labelPath := path.Join(exportLabelsDir, strings.TrimSuffix(img.relPath, path.Ext(img.relPath))+".txt")
+		if err := sink.WriteFile(labelPath, []byte(builder.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportSink abstracts writing the export into a directory or a tar.gz archive
+type exportSink interface {
+	WriteFile(relPath string, data []byte) error
+	CopyFile(ctx context.Context, relPath string, sourcePath string) error
+	Close() error
+}
+
+func newExportSink(source Storage, outputPath string, archive bool) (exportSink, error) {
+	if archive {
+		return newTarExportSink(source, outputPath)
+	}
+	if err := os.MkdirAll(outputPath, exportDirectoryPerm); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %v", err)
+	}
+	return dirExportSink{root: outputPath, source: source}, nil
+}
+
+type dirExportSink struct {
+	root   string
+	source Storage
+}
+
+func (sink dirExportSink) target(relPath string) (string, error) {
+	target := filepath.Join(sink.root, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(target), exportDirectoryPerm); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %v", err)
+	}
+	return target, nil
+}
+
+func (sink dirExportSink) WriteFile(relPath string, data []byte) error {
+	target, err := sink.target(relPath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(target, data, exportFilePerm)
+}
+
+func (sink dirExportSink) CopyFile(ctx context.Context, relPath string, sourcePath string) error {
+	target, err := sink.target(relPath)
+	if err != nil {
+		return err
+	}
+
+	source, err := sink.source.Open(ctx, sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", sourcePath, err)
+	}
+	defer source.Close()
+
+	destination, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, exportFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", target, err)
+	}
+	if _, err := io.Copy(destination, source); err != nil {
+		destination.Close()
+		return fmt.Errorf("failed to copy %s: %v", sourcePath, err)
+	}
+	return destination.Close()
+}
+
+func (sink dirExportSink) Close() error {
+	return nil
+}
+
+type tarExportSink struct {
+	file   *os.File
+	gzip   *gzip.Writer
+	writer *tar.Writer
+	source Storage
+}
+
+func newTarExportSink(source Storage, outputPath string) (*tarExportSink, error) {
+	if err := os.MkdirAll(filepath.Dir(outputPath), exportDirectoryPerm); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %v", err)
+	}
+
+	file, err := os.OpenFile(outputPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, exportFilePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %v", err)
+	}
+
+	gzipWriter := gzip.NewWriter(file)
+	return &tarExportSink{
+		file:   file,
+		gzip:   gzipWriter,
+		writer: tar.NewWriter(gzipWriter),
+		source: source,
+	}, nil
+}
+
+func (sink *tarExportSink) WriteFile(relPath string, data []byte) error {
+	header := &tar.Header{
+		Name:    relPath,
+		Mode:    exportFilePerm,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := sink.writer.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write archive entry %s: %v", relPath, err)
+	}
+	_, err := sink.writer.Write(data)
+	return err
+}
+
+func (sink *tarExportSink) CopyFile(ctx context.Context, relPath string, sourcePath string) error {
+	info, err := sink.source.Stat(ctx, sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %v", sourcePath, err)
+	}
+
+	source, err := sink.source.Open(ctx, sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", sourcePath, err)
+	}
+	defer source.Close()
+
+	header := &tar.Header{
+		Name:    relPath,
+		Mode:    exportFilePerm,
+		Size:    info.Size,
+		ModTime: info.ModTime,
+	}
+	if err := sink.writer.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write archive entry %s: %v", relPath, err)
+	}
+	if _, err := io.Copy(sink.writer, source); err != nil {
+		return fmt.Errorf("failed to copy %s: %v", sourcePath, err)
+	}
+	return nil
+}
+
+func (sink *tarExportSink) Close() error {
+	tarErr := sink.writer.Close()
+	gzipErr := sink.gzip.Close()
+	fileErr := sink.file.Close()
+	for _, err := range []error{tarErr, gzipErr, fileErr} {
+		if err != nil {
+			return fmt.Errorf("failed to finalize archive: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"context"
+	"image"
+	"math"
+	"sort"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	hashSize      = 8
+	phashDCTSize  = 32
+	phashLowFreqs = 8
+)
+
+// PerceptualHashes are 64-bit average, difference and DCT hashes of one image
+type PerceptualHashes struct {
+	AHash uint64
+	DHash uint64
+	PHash uint64
+}
+
+// ComputePerceptualHashes decodes an image through imaging (the same path as the
+// thumbnails) and computes its aHash, dHash and pHash
+func ComputePerceptualHashes(ctx context.Context, store Storage, imagePath string) (PerceptualHashes, error) {
+	srcImage, err := openStoredImage(ctx, store, imagePath)
+	if err != nil {
+		return PerceptualHashes{}, err
+	}
+
+	gray := imaging.Grayscale(srcImage)
+	return PerceptualHashes{
+		AHash: averageHash(gray),
+		DHash: differenceHash(gray),
+		PHash: dctHash(gray),
+	}, nil
+}
+
+func grayValues(img image.Image, width, height int) []float64 {
+	resized := imaging.Resize(img, width, height, imaging.Box)
+	values := make([]float64, 0, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, _, _, _ := resized.At(x, y).RGBA()
+			values = append(values, float64(r>>8))
+		}
+	}
+	return values
+}
+
+// averageHash sets a bit for every pixel of an 8x8 thumbnail brighter than the mean
+func averageHash(img image.Image) uint64 {
+	values := grayValues(img, hashSize, hashSize)
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+
+	return bitsAbove(values, mean)
+}
+
+// differenceHash sets a bit wherever a pixel of a 9x8 thumbnail is brighter than its right neighbour
+func differenceHash(img image.Image) uint64 {
+	values := grayValues(img, hashSize+1, hashSize)
+	var hash uint64
+	for y := 0; y < hashSize; y++ {
+		for x := 0; x < hashSize; x++ {
+			left := values[y*(hashSize+1)+x]
+			right := values[y*(hashSize+1)+x+1]
+			hash <<= 1
+			if left > right {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// dctHash takes the 8x8 lowest frequencies of a 32x32 DCT and sets a bit for
+// every coefficient above their median (the DC term is left out of the median)
+func dctHash(img image.Image) uint64 {
+	values := grayValues(img, phashDCTSize, phashDCTSize)
+	coefficients := dct2D(values, phashDCTSize)
+
+	lowFreqs := make([]float64, 0, phashLowFreqs*phashLowFreqs)
+	for y := 0; y < phashLowFreqs; y++ {
+		for x := 0; x < phashLowFreqs; x++ {
+			lowFreqs = append(lowFreqs, coefficients[y*phashDCTSize+x])
+		}
+	}
+
+	sorted := append([]float64(nil), lowFreqs[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2] + sorted[(len(sorted)-1)/2]) / 2
+
+	return bitsAbove(lowFreqs, median)
+}
+
+func bitsAbove(values []float64, threshold float64) uint64 {
+	var hash uint64
+	for _, value := range values {
+		hash <<= 1
+		if value > threshold {
+			hash |= 1
+		}
+	}
+	return hash
+}
+
+// dct2D is a separable DCT-II over an n x n block
+func dct2D(values []float64, n int) []float64 {
+	cosines := make([]float64, n*n)
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			cosines[k*n+i] = math.Cos(math.Pi / float64(n) * (float64(i) + 0.5) * float64(k))
+		}
+	}
+
+	rows := make([]float64, n*n)
+	for y := 0; y < n; y++ {
+		for k := 0; k < n; k++ {
+			sum := 0.0
+			for x := 0; x < n; x++ {
+				sum += values[y*n+x] * cosines[k*n+x]
+			}
+			rows[y*n+k] = sum
+		}
+	}
+
+	result := make([]float64, n*n)
+	for x := 0; x < n; x++ {
+		for k := 0; k < n; k++ {
+			sum := 0.0
+			for y := 0; y < n; y++ {
+				sum += rows[y*n+x] * cosines[k*n+y]
+			}
+			result[k*n+x] = sum
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const backupLockFilename = "backend.lock"
+
+// ErrBackupDirLocked is returned when another process holds the backup folder lock
+var ErrBackupDirLocked = errors.New("backup folder is in use by another process")
+
+// BackupDirLock is an exclusive lock on a backup folder. The server holds it for
+// its lifetime and commands that change review state hold it while they run, so
+// two processes never append to the same audit log or overwrite each other's
+// pending review state. The operating system releases it when the process exits.
+type BackupDirLock struct {
+	file *os.File
+}
+
+// LockBackupDir takes the lock of dir without waiting. When another process
+// holds it the error wraps ErrBackupDirLocked and names that process. The lock
+// is held until the process exits.
+func LockBackupDir(dir string) (*BackupDirLock, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %v", err)
+	}
+
+	path := filepath.Join(dir, backupLockFilename)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+
+	if err := lockFile(file); err != nil {
+		holder := readLockHolder(file)
+		file.Close()
+		if errors.Is(err, ErrBackupDirLocked) {
+			return nil, fmt.Errorf("%w (%s, pid %s)", ErrBackupDirLocked, path, holder)
+		}
+		return nil, fmt.Errorf("failed to lock %s: %v", path, err)
+	}
+
+	// The pid is only informational, for the error above
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &BackupDirLock{file: file}, nil
+}
+
+func readLockHolder(file *os.File) string {
+	data := make([]byte, 32)
+	n, _ := file.ReadAt(data, 0)
+	if holder := strings.TrimSpace(string(data[:n])); holder != "" {
+		return holder
+	}
+	return "unknown"
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package utils
+
+import "os"
+
+// Platforms without flock run unlocked; the backend is deployed on Linux
+func lockFile(file *os.File) error {
+	return nil
+}
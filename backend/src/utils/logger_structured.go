@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const (
+	LogFormatConsole = "console"
+	LogFormatJSON    = "json"
+)
+
+// logLevel is shared by the default logger so the level can change at runtime
+var logLevel = new(slog.LevelVar)
+
+// ParseLogLevel maps the configured level name (debug, info, warn, error) to a slog level
+func ParseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+}
+
+// NewLogger builds a structured logger writing key=value lines (console) or JSON
+func NewLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	slogLevel, err := ParseLogLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	return newLogger(w, slogLevel, format)
+}
+
+func newLogger(w io.Writer, level slog.Leveler, format string) (*slog.Logger, error) {
+	options := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", LogFormatConsole:
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	case LogFormatJSON:
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q", format)
+}
+
+// ConfigureLogger installs the structured logger as the process default. Output
+// of the standard log package is routed through it at info level.
+func ConfigureLogger(level, format string) error {
+	if err := SetLogLevel(level); err != nil {
+		return err
+	}
+
+	logger, err := newLogger(os.Stderr, logLevel, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// SetLogLevel changes the level of the default logger without rebuilding it
+func SetLogLevel(level string) error {
+	slogLevel, err := ParseLogLevel(level)
+	if err != nil {
+		return err
+	}
+	logLevel.Set(slogLevel)
+	return nil
+}
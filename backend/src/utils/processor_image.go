@@ -1,12 +1,15 @@
 package utils
 
 import (
+	"backend/src/models_verify_viewer"
 	"bytes"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"image"
-	"log"
+	"io/fs"
+	"log/slog"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -26,20 +29,64 @@ const (
 	maxImageWidth        = 400
 	imageQuality         = 75
 	pageRangeThreshold   = 10
+	imageProcessTimeout  = 30 * time.Second
 )
 
 var maxWorkers = runtime.NumCPU()
 
+// CompressionSettings tune page compression: how many page tasks may run at
+// once, how far from the current page a task may be before it is cancelled,
+// and the size, quality and time budget of each compressed image.
+type CompressionSettings struct {
+	MaxSlots           int
+	PageRangeThreshold int
+	MaxImageWidth      int
+	ImageQuality       int
+	ImageTimeout       time.Duration
+}
+
+func DefaultCompressionSettings() CompressionSettings {
+	return CompressionSettings{
+		MaxSlots:           defaultMaxSlots,
+		PageRangeThreshold: pageRangeThreshold,
+		MaxImageWidth:      maxImageWidth,
+		ImageQuality:       imageQuality,
+		ImageTimeout:       imageProcessTimeout,
+	}
+}
+
+var compressionSettings atomic.Pointer[CompressionSettings]
+
+func currentCompressionSettings() CompressionSettings {
+	if settings := compressionSettings.Load(); settings != nil {
+		return *settings
+	}
+	return DefaultCompressionSettings()
+}
+
+// ConfigureCompression applies new compression settings. Running tasks keep
+// their slots; images compressed from now on use the new size and quality.
+func ConfigureCompression(settings CompressionSettings) {
+	compressionSettings.Store(&settings)
+	SetMaxSlots(settings.MaxSlots)
+	slog.Info("Configured image compression", "slots", settings.MaxSlots, "page_range", settings.PageRangeThreshold,
+		"max_width", settings.MaxImageWidth, "quality", settings.ImageQuality, "timeout", settings.ImageTimeout)
+}
+
+// TaskManager shares the compression slots between every workspace and job.
+// Page ranges only make sense within one page list, so tasks are cancelled for
+// being out of range only by tasks of the same scope.
 type TaskManager struct {
-	mu              sync.RWMutex
-	runningTasks    []*TaskInfo
-	maxSlots        int
-	taskCounter     atomic.Int64
-	currentPageHint atomic.Int64
+	mu           sync.RWMutex
+	runningTasks []*TaskInfo
+	maxSlots     int
+	taskCounter  atomic.Int64
+	closed       atomic.Bool
 }
 
 type TaskInfo struct {
 	ID        string
+	Scope     string
 	PageIndex int
 	StartTime time.Time
 	Cancel    context.CancelFunc
@@ -54,46 +101,46 @@ var (
 
 func initTaskManager() {
 	once.Do(func() {
+		maxSlots := currentCompressionSettings().MaxSlots
 		globalTaskManager = &TaskManager{
-			runningTasks: make([]*TaskInfo, 0, defaultMaxSlots),
-			maxSlots:     defaultMaxSlots,
+			runningTasks: make([]*TaskInfo, 0, maxSlots),
+			maxSlots:     maxSlots,
 		}
-		log.Printf("Initialized task manager with %d slots", globalTaskManager.maxSlots)
+		slog.Debug("Initialized task manager", "slots", globalTaskManager.maxSlots)
 	})
 }
 
-func (tm *TaskManager) addTask(taskID string, pageIndex int, cancel context.CancelFunc) *TaskInfo {
+func (tm *TaskManager) addTask(taskID string, scope string, pageIndex int, cancel context.CancelFunc) *TaskInfo {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
-	// Update current page hint
-	tm.currentPageHint.Store(int64(pageIndex))
-
-	// Cancel tasks that are outside the active page range
-	tm.cancelOutOfRangeTasks(pageIndex)
+	// Cancel tasks of the same page list that are outside the active page range
+	tm.cancelOutOfRangeTasks(scope, pageIndex)
 
 	// If still at capacity, cancel oldest task
 	if len(tm.runningTasks) >= tm.maxSlots {
 		tm.cancelOldestTask()
 	}
 
-	newTask := tm.createNewTask(taskID, pageIndex, cancel)
+	newTask := tm.createNewTask(taskID, scope, pageIndex, cancel)
 	tm.runningTasks = append(tm.runningTasks, newTask)
+	taskQueueDepth.Set(float64(len(tm.runningTasks)))
 
 	return newTask
 }
 
-func (tm *TaskManager) cancelOutOfRangeTasks(currentPage int) {
+func (tm *TaskManager) cancelOutOfRangeTasks(scope string, currentPage int) {
 	// Calculate active page range
-	minPage := currentPage - pageRangeThreshold
-	maxPage := currentPage + pageRangeThreshold
+	pageRange := currentCompressionSettings().PageRangeThreshold
+	minPage := currentPage - pageRange
+	maxPage := currentPage + pageRange
 
 	tasksToCancel := make([]*TaskInfo, 0)
 	remainingTasks := make([]*TaskInfo, 0)
 
 	// Identify tasks outside the range
 	for _, task := range tm.runningTasks {
-		if task.PageIndex < minPage || task.PageIndex > maxPage {
+		if task.Scope == scope && (task.PageIndex < minPage || task.PageIndex > maxPage) {
 			tasksToCancel = append(tasksToCancel, task)
 		} else {
 			remainingTasks = append(remainingTasks, task)
@@ -102,30 +149,29 @@ func (tm *TaskManager) cancelOutOfRangeTasks(currentPage int) {
 
 	// Cancel out-of-range tasks
 	if len(tasksToCancel) > 0 {
-		log.Printf("[PageTracker] Current page: %d, cancelling %d tasks outside range [%d, %d]",
-			currentPage, len(tasksToCancel), minPage, maxPage)
+		slog.Debug("Cancelling tasks outside the active page range",
+			"scope", scope, "page", currentPage, "tasks", len(tasksToCancel), "min_page", minPage, "max_page", maxPage)
 
 		for _, task := range tasksToCancel {
-			log.Printf("[PageTracker] Cancelling task %s for page %d (age: %v)",
-				task.ID, task.PageIndex, time.Since(task.StartTime))
+			slog.Debug("Cancelling task", "task_id", task.ID, "scope", task.Scope, "page", task.PageIndex, "age", time.Since(task.StartTime))
 			task.Cancel()
 			tm.waitForTaskCancellation(task)
 		}
 
 		tm.runningTasks = remainingTasks
+		recordTaskCancellations(cancelReasonOutOfRange, len(tasksToCancel))
 	}
 }
 
 func (tm *TaskManager) cancelOldestTask() {
 	oldestTask := tm.runningTasks[0]
 
-	if log.Writer() != nil {
-		log.Printf("Slots full (%d/%d), cancelling oldest task: %s (page: %d, running for %v)",
-			len(tm.runningTasks), tm.maxSlots, oldestTask.ID, oldestTask.PageIndex, time.Since(oldestTask.StartTime))
-	}
+	slog.Debug("Task slots full, cancelling oldest task", "slots", tm.maxSlots, "task_id", oldestTask.ID,
+		"page", oldestTask.PageIndex, "age", time.Since(oldestTask.StartTime))
 
 	oldestTask.Cancel()
 	tm.runningTasks = tm.runningTasks[1:]
+	recordTaskCancellations(cancelReasonSlotsFull, 1)
 	tm.waitForTaskCancellation(oldestTask)
 }
 
@@ -135,16 +181,15 @@ func (tm *TaskManager) waitForTaskCancellation(task *TaskInfo) {
 		case <-t.Done:
 			return
 		case <-time.After(taskCancellationWait):
-			if log.Writer() != nil {
-				log.Printf("Old task cancellation timeout for: %s", t.ID)
-			}
+			slog.Warn("Task cancellation timed out", "task_id", t.ID, "page", t.PageIndex)
 		}
 	}(task)
 }
 
-func (tm *TaskManager) createNewTask(taskID string, pageIndex int, cancel context.CancelFunc) *TaskInfo {
+func (tm *TaskManager) createNewTask(taskID string, scope string, pageIndex int, cancel context.CancelFunc) *TaskInfo {
 	return &TaskInfo{
 		ID:        taskID,
+		Scope:     scope,
 		PageIndex: pageIndex,
 		StartTime: time.Now(),
 		Cancel:    cancel,
@@ -163,6 +208,7 @@ func (tm *TaskManager) removeTask(taskID string) {
 			break
 		}
 	}
+	taskQueueDepth.Set(float64(len(tm.runningTasks)))
 }
 
 func (tm *TaskManager) closeTask(task *TaskInfo) {
@@ -175,6 +221,19 @@ func (tm *TaskManager) removeTaskAtIndex(index int) {
 	tm.runningTasks = append(tm.runningTasks[:index], tm.runningTasks[index+1:]...)
 }
 
+// cancelAll cancels every running task and makes later tasks start cancelled
+func (tm *TaskManager) cancelAll() {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.closed.Store(true)
+	for _, task := range tm.runningTasks {
+		task.Cancel()
+	}
+	recordTaskCancellations(cancelReasonShutdown, len(tm.runningTasks))
+	slog.Info("Cancelled all page tasks", "tasks", len(tm.runningTasks))
+}
+
 func (tm *TaskManager) getTaskStatus() (int, int, []string) {
 	tm.mu.RLock()
 	defer tm.mu.RUnlock()
@@ -188,30 +247,39 @@ func (tm *TaskManager) getTaskStatus() (int, int, []string) {
 func (tm *TaskManager) formatTaskStatuses() []string {
 	taskIDs := make([]string, 0, len(tm.runningTasks))
 	for i, task := range tm.runningTasks {
-		status := fmt.Sprintf("Slot[%d]: %s (page: %d, running: %v)", i, task.ID, task.PageIndex, time.Since(task.StartTime))
+		status := fmt.Sprintf("Slot[%d]: %s (scope: %s, page: %d, running: %v)", i, task.ID, task.Scope, task.PageIndex, time.Since(task.StartTime))
 		taskIDs = append(taskIDs, status)
 	}
 	return taskIDs
 }
 
-func CompressImageSetToBase64(imagePaths []string, pageIndex int) []string {
+// CompressImageSet compresses the images of a page and reports the outcome of
+// each one. When the page task is cancelled, images already compressed are kept
+// and the rest are reported as cancelled. The scope names the page list the
+// page belongs to, so pages of other workspaces and jobs are left running.
+func CompressImageSet(store Storage, scope string, imagePaths []string, pageIndex int) []models_verify_viewer.ImageResult {
 	initTaskManager()
 
 	taskID := generateTaskID(pageIndex)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	_ = globalTaskManager.addTask(taskID, pageIndex, cancel)
+	if globalTaskManager.closed.Load() {
+		// Shutting down: report every image as cancelled without taking a slot
+		cancel()
+		return processImagesWithContext(ctx, store, taskID, imagePaths)
+	}
+
+	_ = globalTaskManager.addTask(taskID, scope, pageIndex, cancel)
 	defer globalTaskManager.removeTask(taskID)
 
-	result := processImagesWithContext(ctx, taskID, imagePaths)
+	results := processImagesWithContext(ctx, store, taskID, imagePaths)
 
 	if isContextCancelled(ctx) {
-		log.Printf("[PageTracker] Task %s for page %d was cancelled", taskID, pageIndex)
-		return make([]string, len(imagePaths))
+		slog.Debug("Page task was cancelled", "task_id", taskID, "scope", scope, "page", pageIndex)
 	}
 
-	return result
+	return results
 }
 
 func generateTaskID(pageIndex int) string {
@@ -228,8 +296,15 @@ func isContextCancelled(ctx context.Context) bool {
 	}
 }
 
-func processImagesWithContext(ctx context.Context, taskID string, imagePaths []string) []string {
-	base64Images := make([]string, len(imagePaths))
+func processImagesWithContext(ctx context.Context, store Storage, taskID string, imagePaths []string) []models_verify_viewer.ImageResult {
+	results := make([]models_verify_viewer.ImageResult, len(imagePaths))
+	for i, imagePath := range imagePaths {
+		results[i] = models_verify_viewer.ImageResult{
+			ImagePath: imagePath,
+			Status:    models_verify_viewer.ImageStatusCancelled,
+			Error:     "page processing was cancelled",
+		}
+	}
 	sem := make(chan struct{}, maxWorkers)
 	defer close(sem)
 
@@ -248,19 +323,19 @@ func processImagesWithContext(ctx context.Context, taskID string, imagePaths []s
 	for i, imagePath := range imagePaths {
 		// Check both context and stop flag
 		if isContextCancelled(ctx) || shouldStop.Load() {
-			log.Printf("[ProcessImages] Task %s cancelled, stopping after launching %d/%d workers", taskID, i, len(imagePaths))
+			slog.Debug("Page task cancelled, stopping workers", "task_id", taskID, "launched", i, "images", len(imagePaths))
 			wg.Wait()
-			return base64Images
+			return results
 		}
 
 		wg.Add(1)
-		go processImage(ctx, &wg, sem, base64Images, i, imagePath)
+		go processImage(ctx, store, &wg, sem, results, i, imagePath)
 	}
 
-	return waitForProcessingCompletion(ctx, &wg, base64Images)
+	return waitForProcessingCompletion(ctx, &wg, results)
 }
 
-func processImage(ctx context.Context, wg *sync.WaitGroup, sem chan struct{}, base64Images []string, index int, path string) {
+func processImage(ctx context.Context, store Storage, wg *sync.WaitGroup, sem chan struct{}, results []models_verify_viewer.ImageResult, index int, path string) {
 	defer wg.Done()
 
 	// Early exit if context is already cancelled
@@ -278,8 +353,7 @@ func processImage(ctx context.Context, wg *sync.WaitGroup, sem chan struct{}, ba
 		return
 	}
 
-	base64Image := compressImageSafely(ctx, path)
-	base64Images[index] = base64Image
+	results[index] = compressImageSafely(ctx, store, path)
 }
 
 func acquireSemaphore(ctx context.Context, sem chan struct{}) bool {
@@ -295,18 +369,44 @@ func releaseSemaphore(sem chan struct{}) {
 	<-sem
 }
 
-func compressImageSafely(ctx context.Context, path string) string {
-	base64Image, err := CompressImageToBase64WithContext(ctx, path)
-	if err != nil {
-		if ctx.Err() == nil {
-			log.Printf("Failed to compress image %s: %v", path, err)
-		}
-		return ""
+// compressImageSafely compresses one image within the configured image timeout and
+// classifies any failure instead of returning an empty string
+func compressImageSafely(ctx context.Context, store Storage, path string) models_verify_viewer.ImageResult {
+	timeout := currentCompressionSettings().ImageTimeout
+	imageCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := models_verify_viewer.ImageResult{ImagePath: path}
+	defer func() { observeCompression(result.Status, time.Since(start)) }()
+
+	base64Image, err := CompressImageToBase64WithContext(imageCtx, store, path)
+	if err == nil {
+		result.Status = models_verify_viewer.ImageStatusOK
+		result.Base64 = base64Image
+		return result
 	}
-	return base64Image
+
+	result.Error = err.Error()
+	switch {
+	case ctx.Err() != nil:
+		result.Status = models_verify_viewer.ImageStatusCancelled
+	case errors.Is(imageCtx.Err(), context.DeadlineExceeded):
+		result.Status = models_verify_viewer.ImageStatusTimedOut
+		result.Error = fmt.Sprintf("processing exceeded %v", timeout)
+	case errors.Is(err, fs.ErrNotExist):
+		result.Status = models_verify_viewer.ImageStatusMissing
+	default:
+		result.Status = models_verify_viewer.ImageStatusDecodeError
+	}
+
+	if result.Status != models_verify_viewer.ImageStatusCancelled {
+		slog.Warn("Failed to compress image", "path", path, "status", result.Status, "error", err)
+	}
+	return result
 }
 
-func waitForProcessingCompletion(ctx context.Context, wg *sync.WaitGroup, base64Images []string) []string {
+func waitForProcessingCompletion(ctx context.Context, wg *sync.WaitGroup, results []models_verify_viewer.ImageResult) []models_verify_viewer.ImageResult {
 	done := make(chan struct{})
 	go func() {
 		wg.Wait()
@@ -316,23 +416,23 @@ func waitForProcessingCompletion(ctx context.Context, wg *sync.WaitGroup, base64
 	select {
 	case <-ctx.Done():
 		wg.Wait()
-		return base64Images
+		return results
 	case <-done:
-		return base64Images
+		return results
 	}
 }
 
-func CompressImageToBase64(imagePath string) (string, error) {
-	return CompressImageToBase64WithContext(context.Background(), imagePath)
+func CompressImageToBase64(store Storage, imagePath string) (string, error) {
+	return CompressImageToBase64WithContext(context.Background(), store, imagePath)
 }
 
-func CompressImageToBase64WithContext(ctx context.Context, imagePath string) (string, error) {
+func CompressImageToBase64WithContext(ctx context.Context, store Storage, imagePath string) (string, error) {
 	// Check before starting
 	if isContextCancelled(ctx) {
 		return "", ctx.Err()
 	}
 
-	srcImage, err := imaging.Open(imagePath)
+	srcImage, err := openStoredImage(ctx, store, imagePath)
 	if err != nil {
 		return "", err
 	}
@@ -357,8 +457,9 @@ func resizeImageIfNeeded(srcImage image.Image) image.Image {
 	bounds := srcImage.Bounds()
 	originalWidth := bounds.Dx()
 
-	if originalWidth > maxImageWidth {
-		resizedImage := imaging.Resize(srcImage, maxImageWidth, 0, imaging.Lanczos)
+	maxWidth := currentCompressionSettings().MaxImageWidth
+	if originalWidth > maxWidth {
+		resizedImage := imaging.Resize(srcImage, maxWidth, 0, imaging.Lanczos)
 		return resizedImage
 	}
 
@@ -376,7 +477,7 @@ func encodeImageToBase64WithContext(ctx context.Context, img image.Image) (strin
 	}
 
 	var buf bytes.Buffer
-	opts := &webp.Options{Lossless: false, Quality: imageQuality}
+	opts := &webp.Options{Lossless: false, Quality: float32(currentCompressionSettings().ImageQuality)}
 
 	if err := webp.Encode(&buf, img, opts); err != nil {
 		return "", err
@@ -391,8 +492,8 @@ func encodeImageToBase64WithContext(ctx context.Context, img image.Image) (strin
 }
 
 // ImageToBase64 reads an image file and converts it to base64 without compression
-func ImageToBase64(imagePath string) (string, error) {
-	img, err := imaging.Open(imagePath)
+func ImageToBase64(ctx context.Context, store Storage, imagePath string) (string, error) {
+	img, err := openStoredImage(ctx, store, imagePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open image: %w", err)
 	}
@@ -408,6 +509,13 @@ func ImageToBase64(imagePath string) (string, error) {
 	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
 }
 
+// CancelAllTasks cancels the running page compression tasks and refuses new
+// ones. It is meant for shutdown and cannot be undone.
+func CancelAllTasks() {
+	initTaskManager()
+	globalTaskManager.cancelAll()
+}
+
 func GetTaskStatus() (int, int, []string) {
 	initTaskManager()
 	return globalTaskManager.getTaskStatus()
@@ -432,5 +540,5 @@ func updateMaxSlots(maxSlots int) {
 	defer globalTaskManager.mu.Unlock()
 
 	globalTaskManager.maxSlots = maxSlots
-	log.Printf("Updated max slots to: %d", maxSlots)
+	slog.Info("Updated task slots", "slots", maxSlots)
 }
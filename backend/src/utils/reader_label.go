@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"backend/src/models_verify_viewer"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"math"
+)
+
+// rawLabelFile covers the label layouts found in our datasets: LabelMe style
+// "shapes" with point lists, and flat "objects"/"annotations" lists with a
+// [x, y, width, height] bbox.
+type rawLabelFile struct {
+	ImageWidth  int              `json:"imageWidth"`
+	ImageHeight int              `json:"imageHeight"`
+	Width       int              `json:"width"`
+	Height      int              `json:"height"`
+	Shapes      []rawLabelObject `json:"shapes"`
+	Objects     []rawLabelObject `json:"objects"`
+	Annotations []rawLabelObject `json:"annotations"`
+}
+
+type rawLabelObject struct {
+	Label      string      `json:"label"`
+	Class      string      `json:"class"`
+	Category   string      `json:"category"`
+	Points     [][]float64 `json:"points"`
+	BBox       []float64   `json:"bbox"`
+	Score      *float64    `json:"score"`
+	Confidence *float64    `json:"confidence"`
+}
+
+// ReadAnnotation parses a label JSON file into the normalized annotation model
+func ReadAnnotation(ctx context.Context, store Storage, labelPath string) (models_verify_viewer.Annotation, error) {
+	data, err := ReadStoredFile(ctx, store, labelPath)
+	if err != nil {
+		return models_verify_viewer.Annotation{}, fmt.Errorf("failed to read label: %w", err)
+	}
+	return ParseAnnotation(data)
+}
+
+func ParseAnnotation(data []byte) (models_verify_viewer.Annotation, error) {
+	var raw rawLabelFile
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return models_verify_viewer.Annotation{}, fmt.Errorf("failed to parse label: %w", err)
+	}
+
+	annotation := models_verify_viewer.NewAnnotation()
+	annotation.ImageWidth = firstPositive(raw.ImageWidth, raw.Width)
+	annotation.ImageHeight = firstPositive(raw.ImageHeight, raw.Height)
+
+	for _, list := range [][]rawLabelObject{raw.Shapes, raw.Objects, raw.Annotations} {
+		for _, rawObject := range list {
+			if object, ok := rawObject.normalize(); ok {
+				annotation.Objects = append(annotation.Objects, object)
+			}
+		}
+	}
+
+	return annotation, nil
+}
+
+func (raw rawLabelObject) normalize() (models_verify_viewer.AnnotationObject, bool) {
+	object := models_verify_viewer.AnnotationObject{
+		Class: firstNonEmpty(raw.Label, raw.Class, raw.Category),
+	}
+
+	switch {
+	case len(raw.BBox) == 4:
+		copy(object.BBox[:], raw.BBox)
+	case len(raw.Points) > 0:
+		bbox, ok := boundingBox(raw.Points)
+		if !ok {
+			return object, false
+		}
+		object.BBox = bbox
+	default:
+		return object, false
+	}
+
+	if raw.Confidence != nil {
+		object.Confidence, object.HasConfidence = *raw.Confidence, true
+	} else if raw.Score != nil {
+		object.Confidence, object.HasConfidence = *raw.Score, true
+	}
+
+	return object, true
+}
+
+func boundingBox(points [][]float64) ([4]float64, bool) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, point := range points {
+		if len(point) < 2 {
+			return [4]float64{}, false
+		}
+		minX, maxX = math.Min(minX, point[0]), math.Max(maxX, point[0])
+		minY, maxY = math.Min(minY, point[1]), math.Max(maxY, point[1])
+	}
+	return [4]float64{minX, minY, maxX - minX, maxY - minY}, true
+}
+
+// ImageDimensions reads only the image header to get its size
+func ImageDimensions(ctx context.Context, store Storage, imagePath string) (int, int, error) {
+	file, err := store.Open(ctx, imagePath)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return 0, 0, err
+	}
+	return config.Width, config.Height, nil
+}
+
+func firstPositive(values ...int) int {
+	for _, value := range values {
+		if value > 0 {
+			return value
+		}
+	}
+	return 0
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"backend/src/models_verify_viewer"
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+type imageSortEntry struct {
+	image       models_verify_viewer.Image
+	datasetName string
+	modTime     time.Time
+	size        int64
+	confidence  float64
+	// missing marks entries without the sort value; they always go last
+	missing bool
+}
+
+// SortDatasetImages orders the images of a dataset in place. Images whose sort
+// value cannot be read (no label, no confidence, stat failure) are kept last.
+func SortDatasetImages(ctx context.Context, store Storage, dataset *models_verify_viewer.Dataset, order models_verify_viewer.ImageSort) {
+	if order.Key == models_verify_viewer.SortKeyNone {
+		return
+	}
+
+	entries := newImageSortEntries(ctx, store, *dataset, order.Key)
+	sortImageEntries(entries, order)
+	for i, entry := range entries {
+		dataset.Image[i] = entry.image
+	}
+}
+
+// SortJobImages orders the images of every dataset of the job as one list and
+// returns them with the dataset of each image. Without a sort key datasets keep
+// their scan order.
+func SortJobImages(ctx context.Context, store Storage, job models_verify_viewer.Job, order models_verify_viewer.ImageSort) ([]models_verify_viewer.Image, []string) {
+	entries := make([]imageSortEntry, 0)
+	for _, dataset := range job.Datasets {
+		entries = append(entries, newImageSortEntries(ctx, store, dataset, order.Key)...)
+	}
+	if order.Key != models_verify_viewer.SortKeyNone {
+		sortImageEntries(entries, order)
+	}
+
+	images := make([]models_verify_viewer.Image, len(entries))
+	datasetNames := make([]string, len(entries))
+	for i, entry := range entries {
+		images[i], datasetNames[i] = entry.image, entry.datasetName
+	}
+	return images, datasetNames
+}
+
+func newImageSortEntries(ctx context.Context, store Storage, dataset models_verify_viewer.Dataset, key string) []imageSortEntry {
+	entries := make([]imageSortEntry, len(dataset.Image))
+	labels := dataset.LabelIndex()
+	for i, image := range dataset.Image {
+		entries[i] = newImageSortEntry(ctx, store, dataset.Name, labels, image, key)
+	}
+	return entries
+}
+
+func sortImageEntries(entries []imageSortEntry, order models_verify_viewer.ImageSort) {
+	if order.Key == models_verify_viewer.SortKeyRandom {
+		rng := rand.New(rand.NewSource(order.Seed))
+		rng.Shuffle(len(entries), func(i, j int) {
+			entries[i], entries[j] = entries[j], entries[i]
+		})
+		return
+	}
+
+	descending := order.Descending()
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.missing != b.missing {
+			return b.missing
+		}
+		if descending {
+			a, b = b, a
+		}
+		return lessImageSortEntry(a, b, order.Key)
+	})
+}
+
+func newImageSortEntry(ctx context.Context, store Storage, datasetName string, labels models_verify_viewer.LabelIndex, image models_verify_viewer.Image, key string) imageSortEntry {
+	entry := imageSortEntry{image: image, datasetName: datasetName}
+
+	switch key {
+	case models_verify_viewer.SortKeyModTime, models_verify_viewer.SortKeySize:
+		info, err := store.Stat(ctx, image.Path)
+		if err != nil {
+			entry.missing = true
+			return entry
+		}
+		entry.modTime, entry.size = info.ModTime, info.Size
+	case models_verify_viewer.SortKeyConfidence:
+		entry.confidence, entry.missing = lowestConfidence(ctx, store, labels, image.Name)
+	}
+	return entry
+}
+
+// lowestConfidence returns the least confident detection of an image, which is
+// the one a reviewer most likely has to look at
+func lowestConfidence(ctx context.Context, store Storage, labels models_verify_viewer.LabelIndex, imageName string) (float64, bool) {
+	label, ok := labels.For(imageName)
+	if !ok {
+		return 0, true
+	}
+	annotation, err := ReadAnnotation(ctx, store, label.Path)
+	if err != nil {
+		return 0, true
+	}
+
+	lowest, found := math.Inf(1), false
+	for _, object := range annotation.Objects {
+		if object.HasConfidence && object.Confidence < lowest {
+			lowest, found = object.Confidence, true
+		}
+	}
+	return lowest, !found
+}
+
+func lessImageSortEntry(a, b imageSortEntry, key string) bool {
+	switch key {
+	case models_verify_viewer.SortKeyModTime:
+		if !a.modTime.Equal(b.modTime) {
+			return a.modTime.Before(b.modTime)
+		}
+	case models_verify_viewer.SortKeySize:
+		if a.size != b.size {
+			return a.size < b.size
+		}
+	case models_verify_viewer.SortKeyConfidence:
+		if a.confidence != b.confidence {
+			return a.confidence < b.confidence
+		}
+	}
+	return NaturalLess(a.image.Name, b.image.Name)
+}
+
+// NaturalLess compares strings treating digit runs as numbers, so img_2 < img_10
+func NaturalLess(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			startA, startB := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			if cmp := compareDigitRuns(a[startA:i], b[startB:j]); cmp != 0 {
+				return cmp < 0
+			}
+			continue
+		}
+
+		if a[i] != b[j] {
+			return a[i] < b[j]
+		}
+		i++
+		j++
+	}
+	return len(a)-i < len(b)-j
+}
+
+func compareDigitRuns(a, b string) int {
+	trimmedA, trimmedB := trimLeadingZeros(a), trimLeadingZeros(b)
+	if len(trimmedA) != len(trimmedB) {
+		return len(trimmedA) - len(trimmedB)
+	}
+	if trimmedA != trimmedB {
+		if trimmedA < trimmedB {
+			return -1
+		}
+		return 1
+	}
+	// Equal values: fewer leading zeros first keeps the order total
+	return len(a) - len(b)
+}
+
+func trimLeadingZeros(digits string) string {
+	for len(digits) > 1 && digits[0] == '0' {
+		digits = digits[1:]
+	}
+	return digits
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
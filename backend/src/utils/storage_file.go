@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"context"
+	"image"
+	"io"
+	"time"
+
+	"github.com/disintegration/imaging"
+)
+
+// FileInfo describes one file or directory of a storage. Listings only promise
+// Name and IsDir; Size and ModTime are always filled by Stat.
+type FileInfo struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+	IsDir   bool
+}
+
+// Storage is where the images and labels of an image root live. Paths are the
+// full paths built from Root() with filepath.Join, so image paths stay plain
+// strings everywhere else. Missing files are reported with an error matching
+// fs.ErrNotExist. The context bounds remote requests; for Open it also covers
+// the reads of the returned file.
+type Storage interface {
+	Root() string
+	List(ctx context.Context, dir string) ([]FileInfo, error)
+	Stat(ctx context.Context, path string) (FileInfo, error)
+	Open(ctx context.Context, path string) (io.ReadSeekCloser, error)
+	Delete(ctx context.Context, path string) error
+}
+
+// ReadStoredFile reads a whole file from storage
+func ReadStoredFile(ctx context.Context, store Storage, path string) ([]byte, error) {
+	file, err := store.Open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+// openStoredImage decodes an image from storage the same way imaging.Open
+// decodes one from disk
+func openStoredImage(ctx context.Context, store Storage, path string) (image.Image, error) {
+	file, err := store.Open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return imaging.Decode(file)
+}
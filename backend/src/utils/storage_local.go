@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// LocalStorage serves an image root from the local file system
+type LocalStorage struct {
+	root string
+}
+
+func NewLocalStorage(root string) *LocalStorage {
+	return &LocalStorage{root: root}
+}
+
+func (ls *LocalStorage) Root() string {
+	return ls.root
+}
+
+func (ls *LocalStorage) List(_ context.Context, dir string) ([]FileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		files = append(files, FileInfo{Name: entry.Name(), IsDir: entry.IsDir()})
+	}
+	return files, nil
+}
+
+func (ls *LocalStorage) Stat(_ context.Context, path string) (FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	return FileInfo{
+		Name:    info.Name(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		IsDir:   info.IsDir(),
+	}, nil
+}
+
+func (ls *LocalStorage) Open(_ context.Context, path string) (io.ReadSeekCloser, error) {
+	return os.Open(path)
+}
+
+func (ls *LocalStorage) Delete(_ context.Context, path string) error {
+	return os.Remove(path)
+}
@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const s3RequestTimeout = 30 * time.Second
+
+// S3Options locate a bucket (and optionally a key prefix inside it) on an
+// S3-compatible service. Empty keys make anonymous requests.
+type S3Options struct {
+	Endpoint  string
+	Bucket    string
+	Prefix    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+// S3Storage serves an image root from object storage. Its root is the virtual
+// path "/<bucket>/<prefix>"; the object keys below the prefix mirror the local
+// layout (job/dataset/image/file.jpg) and "directories" are key prefixes.
+type S3Storage struct {
+	client *minio.Client
+	bucket string
+	prefix string
+	root   string
+}
+
+func NewS3Storage(options S3Options) (*S3Storage, error) {
+	client, err := minio.New(options.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(options.AccessKey, options.SecretKey, ""),
+		Secure: options.UseSSL,
+		Region: options.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	prefix := strings.Trim(options.Prefix, "/")
+	return &S3Storage{
+		client: client,
+		bucket: options.Bucket,
+		prefix: prefix,
+		root:   "/" + path.Join(options.Bucket, prefix),
+	}, nil
+}
+
+func (s3 *S3Storage) Root() string {
+	return s3.root
+}
+
+func (s3 *S3Storage) List(ctx context.Context, dir string) ([]FileInfo, error) {
+	prefix, err := s3.key(dir)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s3RequestTimeout)
+	defer cancel()
+
+	files := make([]FileInfo, 0)
+	for object := range s3.client.ListObjects(ctx, s3.bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if object.Err != nil {
+			return nil, s3.pathError("list", dir, object.Err)
+		}
+
+		name := strings.TrimPrefix(object.Key, prefix)
+		switch {
+		case name == "":
+			// Folder marker object created by some S3 clients
+			continue
+		case strings.HasSuffix(name, "/"):
+			files = append(files, FileInfo{Name: strings.TrimSuffix(name, "/"), IsDir: true})
+		default:
+			files = append(files, FileInfo{Name: name, Size: object.Size, ModTime: object.LastModified})
+		}
+	}
+	return files, nil
+}
+
+func (s3 *S3Storage) Stat(ctx context.Context, name string) (FileInfo, error) {
+	key, err := s3.key(name)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s3RequestTimeout)
+	defer cancel()
+
+	if key == "" {
+		return s3.statBucket(ctx, name)
+	}
+
+	object, err := s3.client.StatObject(ctx, s3.bucket, key, minio.StatObjectOptions{})
+	if err == nil {
+		return FileInfo{Name: path.Base(key), Size: object.Size, ModTime: object.LastModified}, nil
+	}
+	if !isS3NotFound(err) {
+		return FileInfo{}, s3.pathError("stat", name, err)
+	}
+
+	isDir, err := s3.hasKeysUnder(ctx, key+"/")
+	if err != nil {
+		return FileInfo{}, s3.pathError("stat", name, err)
+	}
+	if !isDir {
+		return FileInfo{}, s3.pathError("stat", name, fs.ErrNotExist)
+	}
+	return FileInfo{Name: path.Base(key), IsDir: true}, nil
+}
+
+// Open streams an object; the returned reader fetches ranges on Seek, so it can
+// be served with http.ServeContent. Its reads use ctx, so ctx must outlive them.
+func (s3 *S3Storage) Open(ctx context.Context, name string) (io.ReadSeekCloser, error) {
+	key, err := s3.key(name)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := s3.client.GetObject(ctx, s3.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s3.pathError("open", name, err)
+	}
+
+	// GetObject is lazy; stat now so a missing key fails here and not on first read
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, s3.pathError("open", name, err)
+	}
+	return object, nil
+}
+
+// Delete removes an object. S3 deletes succeed for missing keys, so the key is
+// checked first to report a missing file like the local file system does.
+func (s3 *S3Storage) Delete(ctx context.Context, name string) error {
+	key, err := s3.key(name)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s3RequestTimeout)
+	defer cancel()
+
+	if _, err := s3.client.StatObject(ctx, s3.bucket, key, minio.StatObjectOptions{}); err != nil {
+		return s3.pathError("remove", name, err)
+	}
+	if err := s3.client.RemoveObject(ctx, s3.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return s3.pathError("remove", name, err)
+	}
+	return nil
+}
+
+// key maps a path under the storage root to its object key
+func (s3 *S3Storage) key(name string) (string, error) {
+	name = filepath.ToSlash(filepath.Clean(name))
+	if name != s3.root && !strings.HasPrefix(name, s3.root+"/") {
+		return "", &fs.PathError{Op: "resolve", Path: name, Err: fmt.Errorf("path is outside the storage root %s", s3.root)}
+	}
+
+	return strings.TrimPrefix(path.Join(s3.prefix, strings.TrimPrefix(name, s3.root)), "/"), nil
+}
+
+func (s3 *S3Storage) statBucket(ctx context.Context, name string) (FileInfo, error) {
+	exists, err := s3.client.BucketExists(ctx, s3.bucket)
+	if err != nil {
+		return FileInfo{}, s3.pathError("stat", name, err)
+	}
+	if !exists {
+		return FileInfo{}, s3.pathError("stat", name, fs.ErrNotExist)
+	}
+	return FileInfo{Name: s3.bucket, IsDir: true}, nil
+}
+
+func (s3 *S3Storage) hasKeysUnder(ctx context.Context, prefix string) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for object := range s3.client.ListObjects(ctx, s3.bucket, minio.ListObjectsOptions{Prefix: prefix, MaxKeys: 1}) {
+		if object.Err != nil {
+			return false, object.Err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// pathError wraps an S3 error; missing keys and buckets match fs.ErrNotExist
+func (s3 *S3Storage) pathError(op, name string, err error) error {
+	if isS3NotFound(err) {
+		err = fs.ErrNotExist
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+func isS3NotFound(err error) bool {
+	if errors.Is(err, fs.ErrNotExist) {
+		return true
+	}
+	response := minio.ToErrorResponse(err)
+	return response.StatusCode == http.StatusNotFound ||
+		response.Code == "NoSuchKey" || response.Code == "NoSuchBucket"
+}
@@ -0,0 +1,317 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	fakeS3Bucket = "images"
+	fakeS3Prefix = "proj"
+)
+
+// fakeS3 serves the handful of S3 calls S3Storage makes from an in-memory
+// bucket: bucket and object HEAD, ListObjectsV2, ranged GET and DELETE
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	modTime time.Time
+}
+
+type fakeS3ListResult struct {
+	XMLName        xml.Name           `xml:"ListBucketResult"`
+	Name           string             `xml:"Name"`
+	Prefix         string             `xml:"Prefix"`
+	Delimiter      string             `xml:"Delimiter"`
+	KeyCount       int                `xml:"KeyCount"`
+	IsTruncated    bool               `xml:"IsTruncated"`
+	Contents       []fakeS3Object     `xml:"Contents"`
+	CommonPrefixes []fakeS3CommonPath `xml:"CommonPrefixes"`
+}
+
+type fakeS3Object struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+}
+
+type fakeS3CommonPath struct {
+	Prefix string `xml:"Prefix"`
+}
+
+func newFakeS3(objects map[string]string) *fakeS3 {
+	fake := &fakeS3{
+		objects: make(map[string][]byte, len(objects)),
+		modTime: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	for key, data := range objects {
+		fake.objects[key] = []byte(data)
+	}
+	return fake
+}
+
+func (fake *fakeS3) has(key string) bool {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	_, found := fake.objects[key]
+	return found
+}
+
+func (fake *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != fakeS3Bucket {
+		fake.writeError(w, r, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case key == "" && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		fake.list(w, r)
+	case key == "":
+		fake.writeError(w, r, http.StatusNotImplemented, "NotImplemented")
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		data, found := fake.objects[key]
+		if !found {
+			fake.writeError(w, r, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", fmt.Sprintf(`"%x"`, len(data)))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		http.ServeContent(w, r, key, fake.modTime, bytes.NewReader(data))
+	case r.Method == http.MethodDelete:
+		delete(fake.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		fake.writeError(w, r, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (fake *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	delimiter := r.URL.Query().Get("delimiter")
+
+	keys := make([]string, 0, len(fake.objects))
+	for key := range fake.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := fakeS3ListResult{Name: fakeS3Bucket, Prefix: prefix, Delimiter: delimiter}
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		rest, found := strings.CutPrefix(key, prefix)
+		if !found {
+			continue
+		}
+		if index := strings.Index(rest, delimiter); delimiter != "" && index >= 0 {
+			common := prefix + rest[:index+len(delimiter)]
+			if !seen[common] {
+				seen[common] = true
+				result.CommonPrefixes = append(result.CommonPrefixes, fakeS3CommonPath{Prefix: common})
+			}
+			continue
+		}
+		result.Contents = append(result.Contents, fakeS3Object{
+			Key:          key,
+			LastModified: fake.modTime.Format(time.RFC3339),
+			ETag:         fmt.Sprintf(`"%x"`, len(fake.objects[key])),
+			Size:         len(fake.objects[key]),
+		})
+	}
+	result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func (fake *fakeS3) writeError(w http.ResponseWriter, r *http.Request, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message><Resource>%s</Resource></Error>", code, code, r.URL.Path)
+	}
+}
+
+func newTestS3Storage(t *testing.T, objects map[string]string) (*S3Storage, *fakeS3) {
+	t.Helper()
+
+	fake := newFakeS3(objects)
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3Storage(S3Options{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Bucket:    fakeS3Bucket,
+		Prefix:    fakeS3Prefix,
+		Region:    "us-east-1",
+		AccessKey: "access",
+		SecretKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	return store, fake
+}
+
+func testS3Objects() map[string]string {
+	return map[string]string{
+		"proj/job1/ds1/image/a.jpg":  "image-a",
+		"proj/job1/ds1/image/b.jpg":  "image-bb",
+		"proj/job1/ds1/label/a.json": "{}",
+		"proj/job2/ds1/image/c.jpg":  "image-c",
+		"other/job3/ds1/image/d.jpg": "image-d",
+	}
+}
+
+func TestS3StorageList(t *testing.T) {
+	store, _ := newTestS3Storage(t, testS3Objects())
+	ctx := context.Background()
+
+	jobs, err := store.List(ctx, store.Root())
+	if err != nil {
+		t.Fatalf("List root: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0] != (FileInfo{Name: "job1", IsDir: true}) || jobs[1] != (FileInfo{Name: "job2", IsDir: true}) {
+		t.Fatalf("List root = %+v, want job1 and job2 directories", jobs)
+	}
+
+	images, err := store.List(ctx, store.Root()+"/job1/ds1/image")
+	if err != nil {
+		t.Fatalf("List images: %v", err)
+	}
+	if len(images) != 2 || images[0].Name != "a.jpg" || images[0].Size != 7 || images[0].IsDir || images[1].Name != "b.jpg" {
+		t.Fatalf("List images = %+v, want a.jpg and b.jpg", images)
+	}
+}
+
+func TestS3StorageStat(t *testing.T) {
+	store, fake := newTestS3Storage(t, testS3Objects())
+	ctx := context.Background()
+
+	info, err := store.Stat(ctx, store.Root()+"/job1/ds1/image/b.jpg")
+	if err != nil {
+		t.Fatalf("Stat file: %v", err)
+	}
+	if info.Name != "b.jpg" || info.Size != 8 || info.IsDir || !info.ModTime.Equal(fake.modTime) {
+		t.Fatalf("Stat file = %+v", info)
+	}
+
+	info, err = store.Stat(ctx, store.Root()+"/job1/ds1")
+	if err != nil {
+		t.Fatalf("Stat directory: %v", err)
+	}
+	if info.Name != "ds1" || !info.IsDir {
+		t.Fatalf("Stat directory = %+v, want directory ds1", info)
+	}
+
+	if info, err = store.Stat(ctx, store.Root()); err != nil || !info.IsDir {
+		t.Fatalf("Stat root = %+v, %v, want directory", info, err)
+	}
+
+	if _, err := store.Stat(ctx, store.Root()+"/job1/missing.jpg"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Stat missing error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestS3StorageOpen(t *testing.T) {
+	store, _ := newTestS3Storage(t, testS3Objects())
+	ctx := context.Background()
+
+	file, err := store.Open(ctx, store.Root()+"/job1/ds1/image/b.jpg")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil || string(data) != "image-bb" {
+		t.Fatalf("ReadAll = %q, %v, want image-bb", data, err)
+	}
+
+	if _, err := file.Seek(6, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	data, err = io.ReadAll(file)
+	if err != nil || string(data) != "bb" {
+		t.Fatalf("ReadAll after Seek = %q, %v, want bb", data, err)
+	}
+
+	if _, err := store.Open(ctx, store.Root()+"/job1/ds1/image/missing.jpg"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Open missing error = %v, want fs.ErrNotExist", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := store.Open(cancelled, store.Root()+"/job1/ds1/image/a.jpg"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Open with cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestS3StorageDelete(t *testing.T) {
+	store, fake := newTestS3Storage(t, testS3Objects())
+	ctx := context.Background()
+	path := store.Root() + "/job1/ds1/image/a.jpg"
+
+	if err := store.Delete(ctx, path); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if fake.has("proj/job1/ds1/image/a.jpg") {
+		t.Fatal("Delete left the object in the bucket")
+	}
+	if err := store.Delete(ctx, path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Delete missing error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestS3StorageKeyStaysInRoot(t *testing.T) {
+	store, fake := newTestS3Storage(t, testS3Objects())
+	root := store.Root()
+
+	for path, want := range map[string]string{
+		root:                           "proj",
+		root + "/job1/ds1/image/a.jpg": "proj/job1/ds1/image/a.jpg",
+		root + "/job1/../job2":         "proj/job2",
+	} {
+		key, err := store.key(path)
+		if err != nil || key != want {
+			t.Errorf("key(%q) = %q, %v, want %q", path, key, err, want)
+		}
+	}
+
+	for _, path := range []string{
+		"/images/other/job3/ds1/image/d.jpg",
+		root + "/../other/job3/ds1/image/d.jpg",
+		root + "extra/job1",
+		"/elsewhere/proj/job1",
+	} {
+		if _, err := store.key(path); err == nil {
+			t.Errorf("key(%q) succeeded, want an outside-root error", path)
+		}
+	}
+
+	if err := store.Delete(context.Background(), root+"/../other/job3/ds1/image/d.jpg"); err == nil {
+		t.Fatal("Delete outside the root succeeded")
+	}
+	if !fake.has("other/job3/ds1/image/d.jpg") {
+		t.Fatal("Delete outside the root removed the object")
+	}
+}
@@ -2,8 +2,10 @@ package utils
 
 import (
 	"backend/src/models_verify_viewer"
-	"log"
-	"os"
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
 	"path/filepath"
 )
 
@@ -14,24 +16,24 @@ const (
 	jsonExtension     = ".json"
 )
 
-func ConcurrentJobDetailsScanner(root string, jobName string) (models_verify_viewer.Job, bool) {
-	jobPath := filepath.Join(root, jobName)
+func ConcurrentJobDetailsScanner(ctx context.Context, store Storage, jobName string) (models_verify_viewer.Job, bool) {
+	jobPath := filepath.Join(store.Root(), jobName)
 
-	if !jobDirectoryExists(jobPath) {
+	if !jobDirectoryExists(ctx, store, jobPath) {
 		return createEmptyJob(jobName), false
 	}
 
-	jobData := watchJobDetails(root, jobName)
+	jobData := watchJobDetails(ctx, store, jobName)
 	return jobData, true
 }
 
-func jobDirectoryExists(jobPath string) bool {
-	_, err := os.Stat(jobPath)
+func jobDirectoryExists(ctx context.Context, store Storage, jobPath string) bool {
+	_, err := store.Stat(ctx, jobPath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			log.Printf("Job directory does not exist: %s", jobPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("Job directory does not exist", "path", jobPath)
 		} else {
-			log.Printf("Error accessing job directory: %v", err)
+			slog.Error("Failed to access job directory", "path", jobPath, "error", err)
 		}
 		return false
 	}
@@ -44,136 +46,141 @@ func createEmptyJob(jobName string) models_verify_viewer.Job {
 	return job
 }
 
-func watchJobDetails(root string, jobName string) models_verify_viewer.Job {
+func watchJobDetails(ctx context.Context, store Storage, jobName string) models_verify_viewer.Job {
 	jobData := models_verify_viewer.NewJob()
 	jobData.FillJobName(jobName)
 
-	jobPath := filepath.Join(root, jobName)
-	if !jobDirectoryExists(jobPath) {
+	jobPath := filepath.Join(store.Root(), jobName)
+	if !jobDirectoryExists(ctx, store, jobPath) {
 		return jobData
 	}
 
-	datasets := readDatasets(jobPath, jobName)
-	jobData.Datasets = processDatasets(jobPath, datasets)
+	datasets := readDatasets(ctx, store, jobPath, jobName)
+	jobData.Datasets = processDatasets(ctx, store, jobPath, datasets)
 
 	return jobData
 }
 
-func readDatasets(jobPath, jobName string) []os.DirEntry {
-	datasets, err := os.ReadDir(jobPath)
+func readDatasets(ctx context.Context, store Storage, jobPath, jobName string) []FileInfo {
+	datasets, err := store.List(ctx, jobPath)
 	if err != nil {
-		log.Printf("Error reading datasets for job %s: %v", jobName, err)
-		return []os.DirEntry{}
+		slog.Error("Failed to read datasets", "job", jobName, "error", err)
+		return []FileInfo{}
 	}
 	return datasets
 }
 
-func processDatasets(jobPath string, datasets []os.DirEntry) []models_verify_viewer.Dataset {
+func processDatasets(ctx context.Context, store Storage, jobPath string, datasets []FileInfo) []models_verify_viewer.Dataset {
 	var processedDatasets []models_verify_viewer.Dataset
 	for _, dataset := range datasets {
-		if dataset.IsDir() {
-			datasetData := scanDataset(jobPath, dataset)
+		if dataset.IsDir {
+			datasetData := scanDataset(ctx, store, jobPath, dataset)
 			processedDatasets = append(processedDatasets, datasetData)
 		}
 	}
 	return processedDatasets
 }
 
-func scanDataset(jobPath string, dataset os.DirEntry) models_verify_viewer.Dataset {
+func scanDataset(ctx context.Context, store Storage, jobPath string, dataset FileInfo) models_verify_viewer.Dataset {
 	datasetData := models_verify_viewer.NewDataset()
-	datasetData.FillDatasetName(dataset.Name())
+	datasetData.FillDatasetName(dataset.Name)
 
-	datasetPath := filepath.Join(jobPath, dataset.Name())
-	metaDirectories := readMetaDirectories(datasetPath, dataset.Name())
+	datasetPath := filepath.Join(jobPath, dataset.Name)
+	metaDirectories := readMetaDirectories(ctx, store, datasetPath, dataset.Name)
 
-	processMetaDirectories(datasetPath, metaDirectories, &datasetData)
+	processMetaDirectories(ctx, store, datasetPath, metaDirectories, &datasetData)
 
 	return datasetData
 }
 
-func readMetaDirectories(datasetPath, datasetName string) []os.DirEntry {
-	metas, err := os.ReadDir(datasetPath)
+func readMetaDirectories(ctx context.Context, store Storage, datasetPath, datasetName string) []FileInfo {
+	metas, err := store.List(ctx, datasetPath)
 	if err != nil {
-		log.Printf("Error reading meta directories for dataset %s: %v", datasetName, err)
-		return []os.DirEntry{}
+		slog.Error("Failed to read dataset directories", "dataset", datasetName, "error", err)
+		return []FileInfo{}
 	}
 	return metas
 }
 
-func processMetaDirectories(datasetPath string, metas []os.DirEntry, datasetData *models_verify_viewer.Dataset) {
+func processMetaDirectories(ctx context.Context, store Storage, datasetPath string, metas []FileInfo, datasetData *models_verify_viewer.Dataset) {
 	for _, meta := range metas {
-		if meta.IsDir() {
-			metaPath := filepath.Join(datasetPath, meta.Name())
-			scanMeta(metaPath, meta.Name(), datasetData)
+		if meta.IsDir {
+			metaPath := filepath.Join(datasetPath, meta.Name)
+			scanMeta(ctx, store, metaPath, meta.Name, datasetData)
 		}
 	}
 }
 
-func scanMeta(metaPath, metaName string, datasetData *models_verify_viewer.Dataset) {
+func scanMeta(ctx context.Context, store Storage, metaPath, metaName string, datasetData *models_verify_viewer.Dataset) {
 	switch metaName {
 	case imageSubdirectory:
-		scanImagesDirectory(metaPath, datasetData)
+		scanImagesDirectory(ctx, store, metaPath, datasetData)
 	case labelSubdirectory:
-		scanLabelsDirectory(metaPath, datasetData)
+		scanLabelsDirectory(ctx, store, metaPath, datasetData)
 	}
 }
 
-func scanImagesDirectory(metaPath string, datasetData *models_verify_viewer.Dataset) {
-	images, err := os.ReadDir(metaPath)
+func scanImagesDirectory(ctx context.Context, store Storage, metaPath string, datasetData *models_verify_viewer.Dataset) {
+	images, err := store.List(ctx, metaPath)
 	if err != nil {
-		log.Printf("Error reading images: %v", err)
+		slog.Error("Failed to read images", "path", metaPath, "error", err)
 		return
 	}
 	scanImages(images, metaPath, datasetData)
 }
 
-func scanLabelsDirectory(metaPath string, datasetData *models_verify_viewer.Dataset) {
-	labels, err := os.ReadDir(metaPath)
+func scanLabelsDirectory(ctx context.Context, store Storage, metaPath string, datasetData *models_verify_viewer.Dataset) {
+	labels, err := store.List(ctx, metaPath)
 	if err != nil {
-		log.Printf("Error reading labels: %v", err)
+		slog.Error("Failed to read labels", "path", metaPath, "error", err)
 		return
 	}
 	scanLabels(labels, metaPath, datasetData)
 }
 
-func scanImages(images []os.DirEntry, metaPath string, datasetData *models_verify_viewer.Dataset) {
+func scanImages(images []FileInfo, metaPath string, datasetData *models_verify_viewer.Dataset) {
 	capacity := countValidFiles(images, jpgExtension)
 	datasetData.Image = make([]models_verify_viewer.Image, 0, capacity)
 
 	for _, image := range images {
 		if isValidImageFile(image) {
-			imagePath := filepath.Join(metaPath, image.Name())
-			datasetData.Image = append(datasetData.Image, models_verify_viewer.NewImage(image.Name(), imagePath))
+			imagePath := filepath.Join(metaPath, image.Name)
+			datasetData.Image = append(datasetData.Image, models_verify_viewer.NewImage(image.Name, imagePath))
 		}
 	}
 }
 
-func scanLabels(labels []os.DirEntry, metaPath string, datasetData *models_verify_viewer.Dataset) {
+func scanLabels(labels []FileInfo, metaPath string, datasetData *models_verify_viewer.Dataset) {
 	capacity := countValidFiles(labels, jsonExtension)
 	datasetData.Label = make([]models_verify_viewer.Label, 0, capacity)
 
 	for _, label := range labels {
 		if isValidLabelFile(label) {
-			labelPath := filepath.Join(metaPath, label.Name())
-			datasetData.Label = append(datasetData.Label, models_verify_viewer.NewLabel(label.Name(), labelPath))
+			labelPath := filepath.Join(metaPath, label.Name)
+			datasetData.Label = append(datasetData.Label, models_verify_viewer.NewLabel(label.Name, labelPath))
 		}
 	}
 }
 
-func countValidFiles(files []os.DirEntry, extension string) int {
+func countValidFiles(files []FileInfo, extension string) int {
 	count := 0
 	for _, file := range files {
-		if !file.IsDir() && filepath.Ext(file.Name()) == extension {
+		if !file.IsDir && filepath.Ext(file.Name) == extension {
 			count++
 		}
 	}
 	return count
 }
 
-func isValidImageFile(file os.DirEntry) bool {
-	return !file.IsDir() && filepath.Ext(file.Name()) == jpgExtension
+func isValidImageFile(file FileInfo) bool {
+	return !file.IsDir && filepath.Ext(file.Name) == jpgExtension
 }
 
-func isValidLabelFile(file os.DirEntry) bool {
-	return !file.IsDir() && filepath.Ext(file.Name()) == jsonExtension
+func isValidLabelFile(file FileInfo) bool {
+	return !file.IsDir && filepath.Ext(file.Name) == jsonExtension
+}
+
+func DatasetExists(ctx context.Context, store Storage, jobName string, datasetName string) bool {
+	info, err := store.Stat(ctx, filepath.Join(store.Root(), jobName, datasetName))
+	return err == nil && info.IsDir
 }
@@ -3,53 +3,105 @@ package utils
 import (
 	"backend/src/models_verify_viewer"
 	"context"
-	"log"
-	"os"
+	"log/slog"
 	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
 
-var (
-	watcherContext context.Context
-	watcherCancel  context.CancelFunc
-)
+const jobPollInterval = 30 * time.Second
 
-func ConcurrentJobScanner(ctx context.Context, root string, jobList *models_verify_viewer.JobList) {
-	watcherContext, watcherCancel = context.WithCancel(ctx)
-	go watchJobs(watcherContext, root, jobList)
-	log.Printf("Job watcher initialized for root directory: %s", root)
+// JobWatcher keeps the job list of one image root in sync with its storage.
+// Local roots are watched with fsnotify; object storage has no change events
+// and is polled instead. Every root has its own watcher so they can be stopped
+// independently.
+type JobWatcher struct {
+	store   Storage
+	cancel  context.CancelFunc
+	running atomic.Bool
 }
 
-func StopJobWatcher() {
-	if watcherCancel != nil {
-		watcherCancel()
-		log.Println("Job watcher stopped gracefully")
-	}
+func ConcurrentJobScanner(ctx context.Context, store Storage, jobList *models_verify_viewer.JobList) *JobWatcher {
+	watcherContext, cancel := context.WithCancel(ctx)
+	watcher := &JobWatcher{store: store, cancel: cancel}
+	go watcher.watchJobs(watcherContext, jobList)
+	slog.Info("Job watcher initialized", "root", store.Root())
+	return watcher
+}
+
+func (jw *JobWatcher) Stop() {
+	jw.cancel()
+	slog.Info("Job watcher stopped", "root", jw.store.Root())
 }
 
-func watchJobs(ctx context.Context, root string, jobList *models_verify_viewer.JobList) {
+// Running reports whether the watcher goroutine is still consuming events
+func (jw *JobWatcher) Running() bool {
+	return jw.running.Load()
+}
+
+func (jw *JobWatcher) watchJobs(ctx context.Context, jobList *models_verify_viewer.JobList) {
+	if _, isLocal := jw.store.(*LocalStorage); !isLocal {
+		jw.pollJobs(ctx, jobList)
+		return
+	}
+
+	root := jw.store.Root()
 	watcher, err := createWatcher(root)
 	if err != nil {
 		return
 	}
 	defer watcher.Close()
 
-	performInitialScan(root, jobList)
-	log.Printf("Watching directory: %s", root)
+	jw.running.Store(true)
+	defer jw.running.Store(false)
+
+	performInitialScan(ctx, jw.store, jobList)
+	slog.Debug("Watching directory", "root", root)
+
+	monitorFileSystemEvents(ctx, watcher, jw.store, jobList)
+}
+
+func (jw *JobWatcher) pollJobs(ctx context.Context, jobList *models_verify_viewer.JobList) {
+	jw.running.Store(true)
+	defer jw.running.Store(false)
+
+	performInitialScan(ctx, jw.store, jobList)
+	slog.Debug("Polling storage for job changes", "root", jw.store.Root(), "interval", jobPollInterval)
 
-	monitorFileSystemEvents(ctx, watcher, root, jobList)
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Debug("Job watcher context cancelled, shutting down")
+			return
+		case <-ticker.C:
+			// A failed listing keeps the current jobs instead of emptying the list
+			jobs, err := scanJobs(ctx, jw.store)
+			if err != nil {
+				continue
+			}
+			if !slices.Equal(jobs, jobList.Jobs()) {
+				jobList.Replace(jobs)
+				slog.Info("Refreshed job list", "jobs", len(jobs))
+			}
+		}
+	}
 }
 
 func createWatcher(root string) (*fsnotify.Watcher, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		log.Printf("Failed to create watcher: %v", err)
+		slog.Error("Failed to create job watcher", "error", err)
 		return nil, err
 	}
 
 	if err := watcher.Add(root); err != nil {
-		log.Printf("Failed to add watcher for %s: %v", root, err)
+		slog.Error("Failed to watch root directory", "root", root, "error", err)
 		watcher.Close()
 		return nil, err
 	}
@@ -57,38 +109,42 @@ func createWatcher(root string) (*fsnotify.Watcher, error) {
 	return watcher, nil
 }
 
-func performInitialScan(root string, jobList *models_verify_viewer.JobList) {
-	jobs := scanJobs(root)
+func performInitialScan(ctx context.Context, store Storage, jobList *models_verify_viewer.JobList) {
+	jobs, err := scanJobs(ctx, store)
+	if err != nil {
+		return
+	}
 	jobList.Replace(jobs)
-	log.Printf("Initial scan found %d jobs", len(jobs))
+	slog.Info("Initial job scan finished", "jobs", len(jobs))
 }
 
-func monitorFileSystemEvents(ctx context.Context, watcher *fsnotify.Watcher, root string, jobList *models_verify_viewer.JobList) {
+func monitorFileSystemEvents(ctx context.Context, watcher *fsnotify.Watcher, store Storage, jobList *models_verify_viewer.JobList) {
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Job watcher context cancelled, shutting down...")
+			slog.Debug("Job watcher context cancelled, shutting down")
 			return
 
 		case event, ok := <-watcher.Events:
 			if !ok {
-				log.Println("Watcher events channel closed")
+				slog.Warn("Job watcher events channel closed")
 				return
 			}
-			handleFileSystemEvent(event, root, jobList)
+			recordWatcherEvent(event.Op.String())
+			handleFileSystemEvent(ctx, event, store, jobList)
 
 		case err, ok := <-watcher.Errors:
 			if !ok {
-				log.Println("Watcher errors channel closed")
+				slog.Warn("Job watcher errors channel closed")
 				return
 			}
-			log.Printf("Watcher error: %v", err)
+			slog.Error("Job watcher error", "error", err)
 		}
 	}
 }
 
-func handleFileSystemEvent(event fsnotify.Event, root string, jobList *models_verify_viewer.JobList) {
-	if !isJobLevelChange(root, event.Name) {
+func handleFileSystemEvent(ctx context.Context, event fsnotify.Event, store Storage, jobList *models_verify_viewer.JobList) {
+	if !isJobLevelChange(store.Root(), event.Name) {
 		return
 	}
 
@@ -96,8 +152,8 @@ func handleFileSystemEvent(event fsnotify.Event, root string, jobList *models_ve
 		return
 	}
 
-	log.Printf("Detected job level change: %s [%s]", event.Name, event.Op)
-	refreshJobList(root, jobList)
+	slog.Debug("Detected job level change", "path", event.Name, "op", event.Op.String())
+	refreshJobList(ctx, store, jobList)
 }
 
 func isRelevantOperation(op fsnotify.Op) bool {
@@ -106,27 +162,35 @@ func isRelevantOperation(op fsnotify.Op) bool {
 		op&fsnotify.Remove == fsnotify.Remove
 }
 
-func refreshJobList(root string, jobList *models_verify_viewer.JobList) {
-	jobs := scanJobs(root)
+func refreshJobList(ctx context.Context, store Storage, jobList *models_verify_viewer.JobList) {
+	jobs, err := scanJobs(ctx, store)
+	if err != nil {
+		return
+	}
 	jobList.Replace(jobs)
-	log.Printf("Refreshed job list: %d jobs", len(jobs))
+	slog.Info("Refreshed job list", "jobs", len(jobs))
+}
+
+// ScanJobNames lists the job directories of a storage once, without watching
+func ScanJobNames(ctx context.Context, store Storage) ([]string, error) {
+	return scanJobs(ctx, store)
 }
 
-func scanJobs(root string) []string {
-	entries, err := os.ReadDir(root)
+func scanJobs(ctx context.Context, store Storage) ([]string, error) {
+	entries, err := store.List(ctx, store.Root())
 	if err != nil {
-		log.Printf("Error reading root directory: %v", err)
-		return []string{}
+		slog.Error("Failed to read root directory", "root", store.Root(), "error", err)
+		return nil, err
 	}
 
-	return extractJobNames(entries)
+	return extractJobNames(entries), nil
 }
 
-func extractJobNames(entries []os.DirEntry) []string {
+func extractJobNames(entries []FileInfo) []string {
 	var jobs []string
 	for _, entry := range entries {
-		if entry.IsDir() {
-			jobs = append(jobs, entry.Name())
+		if entry.IsDir {
+			jobs = append(jobs, entry.Name)
 		}
 	}
 	return jobs
@@ -0,0 +1,52 @@
+"use client";
+
+import React, { useEffect, useState } from 'react';
+import { getReviewerToken, setReviewerToken } from '@/services/api';
+
+export default function ReviewerToken() {
+  const [token, setToken] = useState('');
+  const [saved, setSaved] = useState(false);
+
+  // localStorage is only available after mount
+  useEffect(() => {
+    const stored = getReviewerToken();
+    setToken(stored);
+    setSaved(stored !== '');
+  }, []);
+
+  const onSave = () => {
+    setReviewerToken(token);
+    setSaved(token.trim() !== '');
+  };
+
+  return (
+    <div className="mb-4">
+      <label className="block text-xs text-gray-500 mb-1" htmlFor="reviewer-token">
+        Reviewer token {saved ? '(saved)' : '(not set)'}
+      </label>
+      <div className="flex gap-2">
+        <input
+          id="reviewer-token"
+          type="password"
+          autoComplete="off"
+          className="flex-1 min-w-0 p-2 border border-gray-300 rounded bg-white text-gray-700 text-sm focus:outline-none focus:border-blue-500 focus:ring-[3px] focus:ring-blue-500/10"
+          value={token}
+          onChange={(e) => {
+            setToken(e.target.value);
+            setSaved(false);
+          }}
+          onKeyDown={(e) => {
+            if (e.key === 'Enter') onSave();
+          }}
+        />
+        <button
+          type="button"
+          className="px-3 py-2 rounded bg-blue-500 text-white text-sm hover:bg-blue-600"
+          onClick={onSave}
+        >
+          Save
+        </button>
+      </div>
+    </div>
+  );
+}
@@ -7,6 +7,7 @@ import { useJobDataset } from '../JobDatasetContext';
 import { SidebarHeader } from './Header';
 import { DatasetSection } from './DatasetSection';
 import JobSelect from './JobSelect';
+import ReviewerToken from './ReviewerToken';
 import LoadingIndicator from './LoadingIndicator';
 
 export default function LeftSidebar() {
@@ -30,6 +31,8 @@ export default function LeftSidebar() {
   return (
     <div className="w-[17%] bg-gray-100 p-2 min-w-[280px]">
       <SidebarHeader />
+
+      <ReviewerToken />
       
       <JobSelect 
         currentJobList = {currentJobList} 
@@ -1,8 +1,8 @@
 "use client";
 
-import React, { createContext, useState, useContext, ReactNode, useEffect, useCallback, useMemo } from 'react';
+import React, { createContext, useState, useContext, ReactNode, useEffect, useCallback, useMemo, useRef } from 'react';
 import { CachedImage, JobDatasetContextType } from '@/types/JobDatasetContext';
-import { getPendingReview, updateALLPages } from '@/services/api';
+import { ApiError, getPendingReview, savePendingReview, updateALLPages } from '@/services/api';
 import { IMAGES_PER_PAGE } from '@/services/config';
 import { logger } from '@/utils/logger';
 
@@ -12,6 +12,63 @@ import { logger } from '@/utils/logger';
 
 const JobDatasetContext = createContext<JobDatasetContextType | undefined>(undefined);
 
+// ============================================================================
+// Pending Review Sync Helpers
+// ============================================================================
+
+/**
+ * Pending review list as last synced with the backend: its revision and the
+ * keys of its images
+ */
+interface SyncedReview {
+  revision: number;
+  keys: Set<string>;
+}
+
+const imageKey = (img: CachedImage): string =>
+  `${img.item_job_name}|${img.item_dataset_name}|${img.item_image_name}`;
+
+const toCachedImage = (img: CachedImage): CachedImage => ({
+  item_job_name: img.item_job_name,
+  item_dataset_name: img.item_dataset_name,
+  item_image_name: img.item_image_name,
+  item_image_path: img.item_image_path,
+});
+
+const toImageToSave = (img: CachedImage) => ({
+  job: img.item_job_name,
+  dataset: img.item_dataset_name,
+  imageName: img.item_image_name,
+  imagePath: img.item_image_path,
+});
+
+/**
+ * Re-apply the local additions and removals made since the last sync to the
+ * server's current list, so images flagged on the server meanwhile (bulk or
+ * duplicate flagging, imports) are kept
+ */
+function rebaseImages(syncedKeys: Set<string>, local: CachedImage[], server: CachedImage[]): CachedImage[] {
+  const localKeys = new Set(local.map(imageKey));
+  const seen = new Set<string>();
+  const result: CachedImage[] = [];
+
+  server.forEach(img => {
+    const key = imageKey(img);
+    if (syncedKeys.has(key) && !localKeys.has(key)) return; // removed locally
+    seen.add(key);
+    result.push(toCachedImage(img));
+  });
+
+  local.forEach(img => {
+    const key = imageKey(img);
+    if (seen.has(key) || syncedKeys.has(key)) return; // kept above or removed on the server
+    seen.add(key);
+    result.push(toCachedImage(img));
+  });
+
+  return result;
+}
+
 // ============================================================================
 // Provider Component
 // ============================================================================
@@ -35,6 +92,7 @@ export function JobDatasetProvider({ children }: { children: ReactNode }) {
   const [selectedPageIndex, setselectedPageIndex] = useState<number>(0);
   const [cachedImages, setCachedImages] = useState<CachedImage[]>([]);
   const [isLoadingPending, setIsLoadingPending] = useState<boolean>(false);
+  const syncedReview = useRef<SyncedReview | null>(null);
 
   // ========== Cached Image Management ==========
 
@@ -107,6 +165,32 @@ export function JobDatasetProvider({ children }: { children: ReactNode }) {
     [cachedImages]
   );
 
+  /**
+   * Save the cached images as the pending review list. When the list changed
+   * on the backend since the last sync, the local changes are rebased onto it
+   * and the cache is updated to the saved list.
+   */
+  const savePendingChanges = useCallback(async () => {
+    const synced = syncedReview.current;
+    if (synced) {
+      try {
+        const result = await savePendingReview({ images: cachedImages.map(toImageToSave) }, synced.revision);
+        syncedReview.current = { revision: result.revision, keys: new Set(cachedImages.map(imageKey)) };
+        return;
+      } catch (error) {
+        if ((error as ApiError).status !== 409) throw error;
+        logger.log('[JobDatasetContext] Pending review changed on the server, rebasing local changes');
+      }
+    }
+
+    // Stale revision, or the initial load failed and every cached image counts as added
+    const latest = await getPendingReview(true);
+    const merged = rebaseImages(synced?.keys ?? new Set(), cachedImages, latest.items ?? []);
+    const result = await savePendingReview({ images: merged.map(toImageToSave) }, latest.revision);
+    syncedReview.current = { revision: result.revision, keys: new Set(merged.map(imageKey)) };
+    setCachedImages(merged);
+  }, [cachedImages]);
+
   // ========== Effects ==========
 
   /**
@@ -124,6 +208,11 @@ export function JobDatasetProvider({ children }: { children: ReactNode }) {
         
         if (!isMounted) return;
 
+        syncedReview.current = {
+          revision: data.revision,
+          keys: new Set((data.items ?? []).map(imageKey)),
+        };
+
         // Batch add all pending images
         if (data.items && Array.isArray(data.items)) {
           data.items.forEach((item: CachedImage) => {
@@ -207,6 +296,7 @@ export function JobDatasetProvider({ children }: { children: ReactNode }) {
       clearCache,
       getCache,
       getCacheCountForJob,
+      savePendingChanges,
     }),
     [
       selectedJob,
@@ -219,6 +309,7 @@ export function JobDatasetProvider({ children }: { children: ReactNode }) {
       clearCache,
       getCache,
       getCacheCountForJob,
+      savePendingChanges,
     ]
   );
 
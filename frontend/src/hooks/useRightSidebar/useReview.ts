@@ -2,7 +2,7 @@
 
 import { useState, useEffect, useCallback } from 'react';
 import { useJobDataset } from '@/components/JobDatasetContext';
-import { savePendingReview, deleteSelectedImages } from '@/services/api';
+import { deleteSelectedImages } from '@/services/api';
 import { PendingReviewData, ReviewItem } from '@/types/HomeReview';
 import { logger } from '@/utils/logger';
 import { useReviewImageLoader } from './useReviewImageLoader';
@@ -16,7 +16,7 @@ const ERROR_MESSAGES = {
 } as const;
 
 export function useHomeReview(isOpen: boolean) {
-  const { cachedImages, addImageToCache, removeImageFromCache } = useJobDataset();
+  const { cachedImages, addImageToCache, removeImageFromCache, savePendingChanges } = useJobDataset();
   const imageLoader = useReviewImageLoader();
   const [reviewData, setReviewData] = useState<PendingReviewData | null>(null);
   const [selectedImages, setSelectedImages] = useState<Set<string>>(new Set());
@@ -51,26 +51,14 @@ export function useHomeReview(isOpen: boolean) {
     }
   };
 
-  const createSaveData = useCallback(() => ({
-    job: selectedJob,
-    dataset: selectedDataset,
-    images: cachedImages.map(img => ({
-      job: img.item_job_name,
-      dataset: img.item_dataset_name,
-      imageName: img.item_image_name,
-      imagePath: img.item_image_path
-    })),
-    timestamp: new Date().toISOString()
-  }), [selectedJob, selectedDataset, cachedImages]);
-
   const saveToPendingReview = useCallback(async () => {
     try {
       setSaving(true);
-      await savePendingReview(createSaveData());
+      await savePendingChanges();
     } finally {
       setSaving(false);
     }
-  }, [createSaveData]);
+  }, [savePendingChanges]);
 
   const toggleImageSelection = async (item: ReviewItem) => {
     const { item_job_name, item_dataset_name, item_image_name, item_image_path } = item;
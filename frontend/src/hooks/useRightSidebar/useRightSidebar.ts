@@ -2,12 +2,11 @@
 
 import { useState } from 'react';
 import { useJobDataset } from '@/components/JobDatasetContext';
-import { savePendingReview } from '@/services/api';
-import { RightSidebarState, RightSidebarActions, SaveData, CachedImage } from '@/types/HomeRightSidebar';
+import { RightSidebarState, RightSidebarActions, CachedImage } from '@/types/HomeRightSidebar';
 import { logger } from '@/utils/logger';
 
 export function useRightSidebar(): RightSidebarState & RightSidebarActions {
-  const { selectedPages, selectedDataset, cachedImages } = useJobDataset();
+  const { selectedPages, selectedDataset, cachedImages, savePendingChanges } = useJobDataset();
   const [isReviewOpen, setIsReviewOpen] = useState(false);
   const [loading, setLoading] = useState(false);
   const [saveSuccess, setSaveSuccess] = useState(false);
@@ -23,19 +22,7 @@ export function useRightSidebar(): RightSidebarState & RightSidebarActions {
       setLoading(true);
       setSaveSuccess(false);
       
-      const saveData: SaveData = {
-        job: selectedPages,
-        dataset: selectedDataset,
-        images: cachedImages.map(img => ({
-          job: img.item_job_name,
-          dataset: img.item_dataset_name,
-          imageName: img.item_image_name,
-          imagePath: img.item_image_path
-        })),
-        timestamp: new Date().toISOString()
-      };
-
-      await savePendingReview(saveData);
+      await savePendingChanges();
       setSaveSuccess(true);
       
       setTimeout(() => {
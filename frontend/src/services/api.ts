@@ -27,7 +27,10 @@ export interface GetAllPagesResponse {
 export interface JobMetadataResponse {
   job_name: string;
   total_pages: number;
+  // Dataset of the first image of every page
   dataset_names: string[];
+  // Every dataset on every page; packed pages can mix datasets
+  page_dataset_names?: string[][];
 }
 
 export interface ImageSetResponse {
@@ -60,6 +63,13 @@ export interface PendingReviewItem {
 
 export interface PendingReviewResponse {
   items: PendingReviewItem[];
+  revision: number;
+}
+
+export interface SavePendingReviewResponse {
+  status: string;
+  count: number;
+  revision: number;
 }
 
 export interface ApiError {
@@ -80,6 +90,36 @@ const api = axios.create({
   },
 });
 
+// ============================================================================
+// Reviewer Token
+// ============================================================================
+
+const REVIEWER_TOKEN_KEY = 'reviewerToken';
+
+/**
+ * Reviewer token configured on the backend (review.reviewers). It identifies
+ * the reviewer for actions that need a verified identity, such as requesting
+ * or approving a deletion batch.
+ */
+export const getReviewerToken = (): string => {
+  if (typeof window === 'undefined') {
+    return '';
+  }
+  return window.localStorage.getItem(REVIEWER_TOKEN_KEY) ?? '';
+};
+
+export const setReviewerToken = (token: string): void => {
+  if (typeof window === 'undefined') {
+    return;
+  }
+  const trimmed = token.trim();
+  if (trimmed) {
+    window.localStorage.setItem(REVIEWER_TOKEN_KEY, trimmed);
+  } else {
+    window.localStorage.removeItem(REVIEWER_TOKEN_KEY);
+  }
+};
+
 // Request tracking for cancellation (disabled by default to prevent issues)
 // Can be enabled via config if needed
 const pendingRequests = new Map<string, AbortController>();
@@ -91,6 +131,11 @@ const pendingRequests = new Map<string, AbortController>();
 // Request interceptor
 api.interceptors.request.use(
   (config) => {
+    const reviewerToken = getReviewerToken();
+    if (reviewerToken) {
+      config.headers.Authorization = `Bearer ${reviewerToken}`;
+    }
+
     // Log requests in development
     if (process.env.NODE_ENV === 'development') {
       logger.log(`[API] ${config.method?.toUpperCase()} ${config.url}`);
@@ -133,9 +178,18 @@ api.interceptors.response.use(
         case 400:
           apiError.message = 'Invalid request parameters';
           break;
+        case 401:
+          apiError.message = 'Reviewer token is missing or invalid';
+          break;
+        case 403:
+          apiError.message = 'This action is not allowed for the current reviewer';
+          break;
         case 404:
           apiError.message = 'Resource not found';
           break;
+        case 409:
+          apiError.message = 'The data changed on the server, reload and try again';
+          break;
         case 500:
           apiError.message = 'Server error occurred';
           break;
@@ -363,15 +417,20 @@ export const fetchALLPages = async (job: string): Promise<GetAllPagesResponse> =
   return result;
 };
 
+/**
+ * Replace the pending review list. revision is the revision of the list the
+ * images are based on; the backend answers 409 when the list changed since.
+ */
 export const savePendingReview = async (
-  data: SavePendingReviewPayload
-): Promise<{ success: boolean }> => {
+  data: SavePendingReviewPayload,
+  revision: number
+): Promise<SavePendingReviewResponse> => {
   if (!data.images) {
     data.images = [];
   }
 
-  return withRetry<{ success: boolean }>(() =>
-    api.post('/api/savePendingReview', data.images)
+  return withRetry<SavePendingReviewResponse>(() =>
+    api.post('/api/savePendingReview', data.images, { params: { revision } })
   );
 };
 
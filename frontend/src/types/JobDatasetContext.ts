@@ -40,4 +40,8 @@ export interface JobDatasetContextType {
   clearCache: () => void;
   getCache: (job: string, dataset: string) => string[];
   getCacheCountForJob: (job: string) => number;
+
+  // Saves the cached images as the pending review list, keeping images
+  // flagged on the server since the last sync
+  savePendingChanges: () => Promise<void>;
 }